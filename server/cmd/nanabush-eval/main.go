@@ -0,0 +1,135 @@
+// Command nanabush-eval measures translation quality on a corpus of
+// source/reference pairs and compares it with a saved baseline.
+//
+// Usage:
+//
+//	nanabush-eval -corpus corpus.jsonl -backend-url http://vllm:8000 -out report.json
+//	nanabush-eval -corpus corpus.jsonl -fixtures testdata/fixtures -baseline baseline.json
+//
+// It exits 1 when a metric drops below the baseline by more than its threshold.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/dasmlab/nanabush/server/pkg/backend/replay"
+	"github.com/dasmlab/nanabush/server/pkg/backend/vllm"
+	"github.com/dasmlab/nanabush/server/pkg/eval"
+	"github.com/dasmlab/nanabush/server/pkg/service"
+)
+
+var (
+	corpusPath   = flag.String("corpus", "", "JSON lines corpus of {id, kind, source_language, target_language, source, reference}")
+	backendURL   = flag.String("backend-url", os.Getenv("NANABUSH_BACKEND_URL"), "vLLM OpenAI-compatible base URL")
+	backendModel = flag.String("backend-model", os.Getenv("NANABUSH_BACKEND_MODEL"), "Model to request from the backend")
+	fixturesDir  = flag.String("fixtures", "", "Replay recorded fixtures from this directory; with -backend-url, missing fixtures are recorded")
+	concurrency  = flag.Int("concurrency", 4, "Segments translated in parallel")
+	timeout      = flag.Duration("timeout", 30*time.Minute, "Timeout for the whole run")
+
+	outPath      = flag.String("out", "", "Write the report as JSON (use it as the next baseline)")
+	baselinePath = flag.String("baseline", "", "Baseline report to compare against")
+	maxBLEUDrop  = flag.Float64("max-bleu-drop", 1.0, "Largest BLEU drop from the baseline, in points")
+	maxChrFDrop  = flag.Float64("max-chrf-drop", 1.0, "Largest chrF drop from the baseline, in points")
+	maxMarkup    = flag.Float64("max-markup-drop", 0.02, "Largest drop in markup preservation from the baseline (0-1)")
+)
+
+func main() {
+	flag.Parse()
+
+	if *corpusPath == "" {
+		fmt.Fprintln(os.Stderr, "-corpus is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	segments, err := eval.LoadCorpus(*corpusPath)
+	if err != nil {
+		fatalf("failed to load corpus: %v", err)
+	}
+
+	var backend service.TranslatorBackend
+	var backendName string
+	switch {
+	case *fixturesDir != "" && *backendURL != "":
+		// Replay what is recorded and record the rest from the live backend
+		recorder, err := replay.NewRecorder(*fixturesDir, *backendModel, vllm.New(vllm.Config{BaseURL: *backendURL, Model: *backendModel}),
+			log.New(os.Stderr, "", 0))
+		if err != nil {
+			fatalf("invalid -fixtures: %v", err)
+		}
+		replayer, err := replay.NewReplayer(*fixturesDir, *backendModel)
+		if err != nil {
+			fatalf("invalid -fixtures: %v", err)
+		}
+		replayer.Fallback = recorder
+		backend, backendName = replayer, "replay+record:"+recorder.Name()
+	case *fixturesDir != "":
+		replayer, err := replay.NewReplayer(*fixturesDir, *backendModel)
+		if err != nil {
+			fatalf("invalid -fixtures: %v", err)
+		}
+		backend, backendName = replayer, "replay:"+*fixturesDir
+	case *backendURL != "":
+		vllmBackend := vllm.New(vllm.Config{BaseURL: *backendURL, Model: *backendModel})
+		backend, backendName = vllmBackend, vllmBackend.Name()
+	default:
+		fatalf("one of -backend-url or -fixtures is required")
+	}
+
+	var baseline *eval.Report
+	if *baselinePath != "" {
+		if baseline, err = eval.LoadReport(*baselinePath); err != nil {
+			fatalf("failed to load baseline: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	fmt.Printf("Evaluating %d segments from %s with %s\n\n", len(segments), *corpusPath, backendName)
+	report := eval.Run(ctx, backend, segments, *concurrency)
+	report.Backend = backendName
+	report.Corpus = *corpusPath
+
+	eval.WriteSummary(os.Stdout, report, baseline)
+	for _, result := range report.Results {
+		if result.Error != "" {
+			fmt.Printf("  failed %s (%s): %s\n", result.ID, result.Pair, result.Error)
+		}
+	}
+
+	if *outPath != "" {
+		if err := report.Save(*outPath); err != nil {
+			fatalf("failed to write report: %v", err)
+		}
+		fmt.Printf("\nReport written to %s\n", *outPath)
+	}
+
+	if baseline == nil {
+		return
+	}
+	regressions := eval.Compare(baseline, report, eval.Thresholds{
+		BLEU:   *maxBLEUDrop,
+		ChrF:   *maxChrFDrop,
+		Markup: *maxMarkup,
+	})
+	if len(regressions) == 0 {
+		fmt.Println("\nPASS: no regressions against baseline")
+		return
+	}
+	fmt.Println("\nFAIL: quality regressed against baseline")
+	for _, regression := range regressions {
+		fmt.Printf("  %s\n", regression)
+	}
+	os.Exit(1)
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
@@ -0,0 +1,895 @@
+// Command nanabushctl is a command-line client for the Nanabush gRPC server.
+//
+// Usage:
+//
+//	nanabushctl [global flags] <command> [command flags]
+//
+// Commands:
+//
+//	register      register as a client (and optionally keep heartbeating)
+//	translate     translate a title or a markdown file
+//	collection    translate a directory of markdown files with a shared glossary
+//	stream        send a file through TranslateStream
+//	health        check the gRPC health service
+//	capabilities  show server capabilities
+//	languages     list supported language pairs
+//
+// Admin commands (need -admin-token or an allowed client certificate):
+//
+//	clients       list registered clients
+//	metrics       show client and job counts
+//	evict         evict a client registration
+//	jobs          list running and recently completed jobs
+//	cancel        cancel a running job
+//	drain         stop the server accepting new work
+//	dead-letters  list job callbacks that could not be delivered
+//
+// Global flags match the server's TLS flags; add -json to any command for
+// machine-readable output.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/dasmlab/nanabush/server/pkg/client"
+	nanabushv1 "github.com/dasmlab/nanabush/server/pkg/proto/v1"
+	"github.com/dasmlab/nanabush/server/pkg/tlsconfig"
+	"github.com/dasmlab/nanabush/server/pkg/version"
+)
+
+var (
+	addr         = flag.String("addr", envOr("NANABUSH_ADDR", "localhost:50051"), "Server address")
+	insecureMode = flag.Bool("insecure", true, "Connect without TLS")
+	tlsCertPath  = flag.String("tls-cert", "", "Path to client certificate (mTLS)")
+	tlsKeyPath   = flag.String("tls-key", "", "Path to client private key (mTLS)")
+	tlsCAPath    = flag.String("tls-ca", "", "Path to CA certificate used to verify the server")
+	tlsServer    = flag.String("tls-server-name", "", "Server name to verify (defaults to the address host)")
+	timeout      = flag.Duration("timeout", 5*time.Minute, "Timeout for the command")
+	jsonOutput   = flag.Bool("json", false, "Print JSON output")
+	adminToken   = flag.String("admin-token", os.Getenv("NANABUSH_ADMIN_TOKEN"), "Bearer token for admin commands")
+)
+
+var commands = map[string]func(ctx context.Context, args []string) error{
+	"register":     runRegister,
+	"translate":    runTranslate,
+	"collection":   runCollection,
+	"stream":       runStream,
+	"health":       runHealth,
+	"capabilities": runCapabilities,
+	"languages":    runLanguages,
+	"clients":      runClients,
+	"metrics":      runMetrics,
+	"evict":        runEvict,
+	"jobs":         runJobs,
+	"cancel":       runCancel,
+	"drain":        runDrain,
+	"dead-letters": runDeadLetters,
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	run, ok := commands[flag.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	ctx, cancelTimeout := context.WithTimeout(ctx, *timeout)
+	defer cancelTimeout()
+
+	if err := run(ctx, flag.Args()[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "nanabushctl %s: %v\n", flag.Arg(0), err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `Usage: nanabushctl [global flags] <command> [command flags]
+
+Commands:
+  register      register as a client (-heartbeat keeps it registered)
+  translate     translate a title (-title) or markdown file (-file); -async with -callback-url
+  collection    translate markdown files (-dir or arguments) with a shared glossary (-out-dir)
+  stream        send a file through TranslateStream
+  health        check the gRPC health service
+  capabilities  show server capabilities
+  languages     list supported language pairs
+
+Admin commands (need -admin-token or an allowed client certificate):
+  clients       list registered clients (-namespace, -version, -name)
+  metrics       show client and job counts
+  evict         evict a client registration (-client-id)
+  jobs          list running and recently completed jobs
+  cancel        cancel a running job (-job-id)
+  drain         stop the server accepting new work
+  dead-letters  list job callbacks that could not be delivered (-job-id, -client-id)
+
+Global flags:
+`)
+	flag.PrintDefaults()
+}
+
+// clientConfig builds the SDK configuration from the global flags.
+func clientConfig(name, namespace string) client.Config {
+	return client.Config{
+		Address:       *addr,
+		ClientName:    name,
+		ClientVersion: version.Version,
+		Namespace:     namespace,
+		Insecure:      *insecureMode,
+		TLS: tlsconfig.Files{
+			CertFile:   *tlsCertPath,
+			KeyFile:    *tlsKeyPath,
+			CAFile:     *tlsCAPath,
+			ServerName: *tlsServer,
+		},
+		Logger: log.New(os.Stderr, "", 0),
+	}
+}
+
+// dial connects without registering.
+func dial() (*client.Client, error) {
+	return client.Dial(clientConfig("", ""))
+}
+
+func runRegister(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("register", flag.ExitOnError)
+	name := fs.String("name", "nanabushctl", "Client name")
+	namespace := fs.String("namespace", "", "Client namespace")
+	heartbeat := fs.Bool("heartbeat", false, "Stay registered and heartbeat until interrupted, printing server directives")
+	queueDepth := fs.Int("queue-depth", 0, "Queue depth to report in heartbeats (with -heartbeat)")
+	poll := fs.Bool("poll", false, "Send unary heartbeats instead of holding a session open (with -heartbeat)")
+	fs.Parse(args)
+
+	cfg := clientConfig(*name, *namespace)
+	cfg.DisableSession = *poll
+	if *queueDepth > 0 {
+		cfg.HeartbeatMetadata = func() map[string]string {
+			return map[string]string{client.QueueDepthMetadataKey: strconv.Itoa(*queueDepth)}
+		}
+	}
+	cfg.OnHeartbeat = func(resp *nanabushv1.HeartbeatResponse) {
+		if *jsonOutput {
+			printJSON(resp)
+			return
+		}
+		bp := resp.Backpressure
+		fmt.Printf("heartbeat: success=%v drain=%v min_client_version=%q backpressure=%v queue_depth=%d max_concurrent_jobs=%d retry_after=%ds next_in=%ds\n",
+			resp.Success, resp.Drain, resp.MinClientVersion, bp.GetLevel(), bp.GetQueueDepth(), bp.GetMaxConcurrentJobs(),
+			bp.GetRetryAfterSeconds(), resp.HeartbeatIntervalSeconds)
+	}
+
+	c, err := client.New(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if *jsonOutput {
+		printJSON(map[string]string{"client_id": c.ClientID(), "client_name": *name})
+	} else {
+		fmt.Printf("Registered %q as %s\n", *name, c.ClientID())
+	}
+
+	if *heartbeat {
+		fmt.Fprintln(os.Stderr, "Heartbeating, press Ctrl-C to stop")
+		<-ctx.Done()
+	}
+	return nil
+}
+
+func runTranslate(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("translate", flag.ExitOnError)
+	from := fs.String("from", "auto", "Source language (BCP 47, or auto)")
+	to := fs.String("to", "", "Target language (BCP 47)")
+	title := fs.String("title", "", "Translate this title")
+	file := fs.String("file", "", "Translate this markdown file (- for stdin)")
+	jobID := fs.String("job-id", "", "Job ID (default: generated)")
+	namespace := fs.String("namespace", "", "Namespace for scheduling")
+	priority := fs.String("priority", "interactive", "Scheduling class: interactive, normal or bulk")
+	out := fs.String("out", "", "Write translated markdown to this file instead of stdout")
+	callbackURL := fs.String("callback-url", "", "POST the result to this URL when the job finishes")
+	async := fs.Bool("async", false, "Submit the job and return without waiting (needs -callback-url)")
+	fs.Parse(args)
+
+	if *to == "" {
+		return fmt.Errorf("-to is required")
+	}
+	if (*title == "") == (*file == "") {
+		return fmt.Errorf("exactly one of -title or -file is required")
+	}
+	if *async && *callbackURL == "" {
+		return fmt.Errorf("-async needs -callback-url to deliver the result")
+	}
+	if *jobID == "" {
+		*jobID = fmt.Sprintf("nanabushctl-%d", time.Now().UnixNano())
+	}
+
+	req := &nanabushv1.TranslateRequest{
+		JobId:          *jobID,
+		Namespace:      *namespace,
+		SourceLanguage: *from,
+		TargetLanguage: *to,
+		RequestedAt:    timestamppb.Now(),
+		CallbackUrl:    *callbackURL,
+	}
+	if *title != "" {
+		req.Primitive = nanabushv1.PrimitiveType_PRIMITIVE_TITLE
+		req.Source = &nanabushv1.TranslateRequest_Title{Title: *title}
+	} else {
+		content, err := readInput(*file)
+		if err != nil {
+			return err
+		}
+		req.Primitive = nanabushv1.PrimitiveType_PRIMITIVE_DOC_TRANSLATE
+		req.Source = &nanabushv1.TranslateRequest_Doc{Doc: &nanabushv1.DocumentContent{
+			Title:    titleFromPath(*file),
+			Markdown: string(content),
+		}}
+	}
+
+	c, err := dial()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if *async {
+		accepted, err := c.SubmitTranslate(client.WithPriority(ctx, *priority), req)
+		if err != nil {
+			return err
+		}
+		if *jsonOutput {
+			printJSON(accepted)
+			return nil
+		}
+		fmt.Printf("job %s accepted, the result will be sent to %s\n", accepted.JobId, *callbackURL)
+		return nil
+	}
+
+	resp, err := c.Translate(client.WithPriority(ctx, *priority), req)
+	if err != nil {
+		return err
+	}
+	if *jsonOutput {
+		printJSON(resp)
+		return nil
+	}
+	if !resp.Success {
+		return fmt.Errorf("translation failed (%s): %s", resp.ErrorCode, resp.ErrorMessage)
+	}
+
+	fmt.Fprintf(os.Stderr, "job %s: %s -> %s in %.2fs, quality %.2f %v\n", resp.JobId,
+		resp.ResolvedSourceLanguage, resp.ResolvedTargetLanguage, resp.InferenceTimeSeconds, resp.QualityScore, resp.QualityFlags)
+	if *title != "" {
+		fmt.Println(resp.TranslatedTitle)
+		return nil
+	}
+	return writeOutput(*out, resp.TranslatedMarkdown)
+}
+
+func runCollection(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("collection", flag.ExitOnError)
+	from := fs.String("from", "auto", "Source language (BCP 47, or auto)")
+	to := fs.String("to", "", "Target language (BCP 47)")
+	dir := fs.String("dir", "", "Translate the .md files in this directory (or pass files as arguments)")
+	collectionID := fs.String("collection-id", "", "Collection ID (default: the directory name)")
+	namespace := fs.String("namespace", "", "Namespace for scheduling")
+	priority := fs.String("priority", "normal", "Scheduling class: interactive, normal or bulk")
+	glossaryFile := fs.String("glossary", "", "File of fixed renderings, one \"source = target\" per line")
+	maxTerms := fs.Int("max-terms", 0, "Most terms to extract (0 = server default)")
+	outDir := fs.String("out-dir", "", "Write translated files to this directory")
+	fs.Parse(args)
+
+	if *to == "" {
+		return fmt.Errorf("-to is required")
+	}
+	files := fs.Args()
+	if *dir != "" {
+		matches, err := filepath.Glob(filepath.Join(*dir, "*.md"))
+		if err != nil {
+			return err
+		}
+		files = append(files, matches...)
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("-dir or at least one file is required")
+	}
+	if *collectionID == "" {
+		if *dir == "" {
+			return fmt.Errorf("-collection-id is required without -dir")
+		}
+		abs, err := filepath.Abs(*dir)
+		if err != nil {
+			return err
+		}
+		*collectionID = filepath.Base(abs)
+	}
+
+	req := &nanabushv1.TranslateCollectionRequest{
+		CollectionId:     *collectionID,
+		Namespace:        *namespace,
+		SourceLanguage:   *from,
+		TargetLanguage:   *to,
+		MaxGlossaryTerms: int32(*maxTerms),
+	}
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		req.Documents = append(req.Documents, &nanabushv1.DocumentContent{
+			Title:    titleFromPath(file),
+			Markdown: string(content),
+			Slug:     strings.TrimSuffix(filepath.Base(file), filepath.Ext(file)),
+			Metadata: map[string]string{"collection": *collectionID},
+		})
+	}
+	if *glossaryFile != "" {
+		terms, err := readGlossary(*glossaryFile)
+		if err != nil {
+			return err
+		}
+		req.Glossary = terms
+	}
+
+	c, err := dial()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	resp, err := c.TranslateCollection(client.WithPriority(ctx, *priority), req)
+	if err != nil {
+		return err
+	}
+
+	if *outDir != "" {
+		if err := os.MkdirAll(*outDir, 0o755); err != nil {
+			return err
+		}
+		for i, result := range resp.Results {
+			if !result.Success {
+				continue
+			}
+			if err := os.WriteFile(filepath.Join(*outDir, filepath.Base(files[i])), []byte(result.Response.TranslatedMarkdown), 0o644); err != nil {
+				return err
+			}
+		}
+	}
+	if *jsonOutput {
+		printJSON(resp)
+		return nil
+	}
+
+	if resp.GlossaryError != "" {
+		fmt.Fprintf(os.Stderr, "collection %s: extracted terms not used, translating them failed: %s\n", resp.CollectionId, resp.GlossaryError)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "FILE\tJOB ID\tSTATUS\tQUALITY")
+	for i, result := range resp.Results {
+		state, quality := "ok", "-"
+		if !result.Success {
+			state = "failed: " + result.ErrorMessage
+		} else {
+			quality = fmt.Sprintf("%.2f", result.Response.QualityScore)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", filepath.Base(files[i]), result.JobId, state, quality)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "TERM\tTRANSLATION\tPAGES\tCONSISTENT\tINCONSISTENT FILES")
+	for _, term := range resp.Report.GetTerms() {
+		var inconsistent []string
+		for _, index := range term.InconsistentDocuments {
+			inconsistent = append(inconsistent, filepath.Base(files[index]))
+		}
+		source := term.Source
+		if term.Provided {
+			source += " *"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\n", source, term.Target, term.Documents, term.ConsistentDocuments, strings.Join(inconsistent, ", "))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("\n%d succeeded, %d failed; consistency %.2f, %d inconsistent terms (* = from -glossary)\n",
+		resp.Succeeded, resp.Failed, resp.Report.GetScore(), resp.Report.GetInconsistentTerms())
+	return nil
+}
+
+// readGlossary reads "source = target" lines; blank lines and # comments are skipped.
+func readGlossary(path string) ([]*nanabushv1.GlossaryTerm, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var terms []*nanabushv1.GlossaryTerm
+	for n, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		source, target, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected \"source = target\"", path, n+1)
+		}
+		terms = append(terms, &nanabushv1.GlossaryTerm{Source: strings.TrimSpace(source), Target: strings.TrimSpace(target)})
+	}
+	return terms, nil
+}
+
+func runStream(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("stream", flag.ExitOnError)
+	file := fs.String("file", "", "File to stream (- for stdin)")
+	jobID := fs.String("job-id", "", "Job ID (default: generated)")
+	chunkSize := fs.Int("chunk-size", client.DefaultChunkSize, "Largest chunk in bytes (at least 4)")
+	out := fs.String("out", "", "Write the result to this file instead of stdout")
+	fs.Parse(args)
+
+	if *file == "" {
+		return fmt.Errorf("-file is required")
+	}
+	if *jobID == "" {
+		*jobID = fmt.Sprintf("nanabushctl-stream-%d", time.Now().UnixNano())
+	}
+	content, err := readInput(*file)
+	if err != nil {
+		return err
+	}
+
+	c, err := dial()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	var result strings.Builder
+	start := time.Now()
+	if err := c.TranslateStream(ctx, *jobID, content, *chunkSize, &result); err != nil {
+		return err
+	}
+	if *jsonOutput {
+		printJSON(map[string]interface{}{
+			"job_id":          *jobID,
+			"input_bytes":     len(content),
+			"output_bytes":    result.Len(),
+			"elapsed_seconds": time.Since(start).Seconds(),
+			"content":         result.String(),
+		})
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "job %s: streamed %d bytes in %v\n", *jobID, len(content), time.Since(start).Round(time.Millisecond))
+	return writeOutput(*out, result.String())
+}
+
+func runHealth(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("health", flag.ExitOnError)
+	service := fs.String("service", "", "Service name to check (empty for the server as a whole)")
+	fs.Parse(args)
+
+	c, err := dial()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	resp, err := grpc_health_v1.NewHealthClient(c.Conn()).Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: *service})
+	if err != nil {
+		return err
+	}
+	if *jsonOutput {
+		printJSON(resp)
+	} else {
+		fmt.Println(resp.Status)
+	}
+	if resp.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+		os.Exit(1)
+	}
+	return nil
+}
+
+func runCapabilities(ctx context.Context, args []string) error {
+	c, err := dial()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	resp, err := c.GetCapabilities(ctx)
+	if err != nil {
+		return err
+	}
+	if *jsonOutput {
+		printJSON(resp)
+		return nil
+	}
+	fmt.Printf("Server version:     %s\n", resp.ServerVersion)
+	fmt.Printf("Max document chars: %d\n", resp.MaxDocumentChars)
+	fmt.Printf("Max batch size:     %d\n", resp.MaxBatchSize)
+	fmt.Printf("Max collection:     %d\n", resp.MaxCollectionSize)
+	fmt.Printf("Streaming:          %v\n", resp.StreamingSupported)
+	fmt.Printf("Auto-detect:        %v\n", resp.AutoDetectSupported)
+	for _, model := range resp.Models {
+		fmt.Printf("Model:              %s (%s, context %d)\n", model.Name, model.Backend, model.ContextWindowTokens)
+	}
+	for _, name := range sortedKeys(resp.Features) {
+		fmt.Printf("Feature %-20s %v\n", name+":", resp.Features[name])
+	}
+	return nil
+}
+
+func runLanguages(ctx context.Context, args []string) error {
+	c, err := dial()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	resp, err := c.Service().ListLanguages(ctx, &nanabushv1.ListLanguagesRequest{})
+	if err != nil {
+		return err
+	}
+	if *jsonOutput {
+		printJSON(resp)
+		return nil
+	}
+	for _, pair := range resp.Pairs {
+		fmt.Printf("%s -> %s\n", pair.SourceLanguage, pair.TargetLanguage)
+	}
+	return nil
+}
+
+// adminContext attaches the admin token to ctx.
+func adminContext(ctx context.Context) context.Context {
+	if *adminToken == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+*adminToken)
+}
+
+func runClients(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("clients", flag.ExitOnError)
+	namespace := fs.String("namespace", "", "Only clients in this namespace")
+	clientVersion := fs.String("version", "", "Only clients reporting this version")
+	name := fs.String("name", "", "Only clients with this name")
+	fs.Parse(args)
+
+	c, err := dial()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	resp, err := nanabushv1.NewAdminServiceClient(c.Conn()).ListClients(adminContext(ctx), &nanabushv1.ListClientsRequest{
+		Namespace:     *namespace,
+		ClientVersion: *clientVersion,
+		ClientName:    *name,
+	})
+	if err != nil {
+		return err
+	}
+	if *jsonOutput {
+		printJSON(resp)
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "CLIENT ID\tNAME\tVERSION\tNAMESPACE\tREGISTERED\tLAST HEARTBEAT")
+	for _, client := range resp.Clients {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s ago\n", client.ClientId, client.ClientName, client.ClientVersion, client.Namespace,
+			client.RegisteredAt.AsTime().Local().Format(time.DateTime), since(client.LastHeartbeat))
+	}
+	return w.Flush()
+}
+
+func runMetrics(ctx context.Context, args []string) error {
+	c, err := dial()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	resp, err := nanabushv1.NewAdminServiceClient(c.Conn()).GetClientMetrics(adminContext(ctx), &nanabushv1.GetClientMetricsRequest{})
+	if err != nil {
+		return err
+	}
+	if *jsonOutput {
+		printJSON(resp)
+		return nil
+	}
+	fmt.Printf("Registered clients: %d\n", resp.TotalClients)
+	for _, ns := range sortedKeys(resp.ClientsByNamespace) {
+		fmt.Printf("  namespace %-20s %d\n", ns, resp.ClientsByNamespace[ns])
+	}
+	for _, v := range sortedKeys(resp.ClientsByVersion) {
+		fmt.Printf("  version   %-20s %d\n", v, resp.ClientsByVersion[v])
+	}
+	if resp.TotalClients > 0 {
+		fmt.Printf("Heartbeats:         oldest %s ago, newest %s ago\n", since(resp.OldestHeartbeat), since(resp.NewestHeartbeat))
+	}
+	fmt.Printf("Active sessions:    %d\n", resp.ActiveSessions)
+	fmt.Printf("Deprecated clients: %d\n", resp.DeprecatedClients)
+	fmt.Printf("Running jobs:       %d\n", resp.RunningJobs)
+	fmt.Printf("Completed jobs:     %d\n", resp.CompletedJobs)
+	fmt.Printf("Interrupted jobs:   %d\n", resp.InterruptedJobs)
+	fmt.Printf("Callbacks:          %d pending, %d delivered, %d dead-lettered\n",
+		resp.CallbacksPending, resp.CallbacksDelivered, resp.CallbacksDeadLettered)
+	fmt.Printf("Draining:           %v\n", resp.Draining)
+	if resp.DrainingSince != nil {
+		fmt.Printf("Draining since:     %s ago\n", since(resp.DrainingSince))
+	}
+	if resp.DrainDeadline != nil {
+		fmt.Printf("Drain deadline:     in %s\n", -time.Since(resp.DrainDeadline.AsTime()).Round(time.Second))
+	}
+	return nil
+}
+
+func runEvict(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("evict", flag.ExitOnError)
+	clientID := fs.String("client-id", "", "Client to evict")
+	reason := fs.String("reason", "", "Reason, recorded in the audit log")
+	fs.Parse(args)
+
+	if *clientID == "" {
+		return fmt.Errorf("-client-id is required")
+	}
+
+	c, err := dial()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	resp, err := nanabushv1.NewAdminServiceClient(c.Conn()).EvictClient(adminContext(ctx), &nanabushv1.EvictClientRequest{
+		ClientId: *clientID,
+		Reason:   *reason,
+	})
+	if err != nil {
+		return err
+	}
+	if *jsonOutput {
+		printJSON(resp)
+		return nil
+	}
+	fmt.Printf("Evicted %s (%s)\n", resp.Client.ClientId, resp.Client.ClientName)
+	return nil
+}
+
+func runJobs(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("jobs", flag.ExitOnError)
+	namespace := fs.String("namespace", "", "Only jobs in this namespace")
+	clientID := fs.String("client-id", "", "Only jobs from this client")
+	state := fs.String("state", "", "Only running, completed or interrupted jobs (default all)")
+	fs.Parse(args)
+
+	req := &nanabushv1.ListJobsRequest{Namespace: *namespace, ClientId: *clientID}
+	switch *state {
+	case "":
+	case "running":
+		req.State = nanabushv1.JobState_JOB_STATE_RUNNING
+	case "completed":
+		req.State = nanabushv1.JobState_JOB_STATE_COMPLETED
+	case "interrupted":
+		req.State = nanabushv1.JobState_JOB_STATE_INTERRUPTED
+	default:
+		return fmt.Errorf("unknown -state %q (want running, completed or interrupted)", *state)
+	}
+
+	c, err := dial()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	resp, err := nanabushv1.NewAdminServiceClient(c.Conn()).ListJobs(adminContext(ctx), req)
+	if err != nil {
+		return err
+	}
+	if *jsonOutput {
+		printJSON(resp)
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "JOB ID\tSTATE\tNAMESPACE\tCLIENT ID\tLANGUAGES\tAGE")
+	for _, job := range resp.Jobs {
+		state, age := "running", since(job.StartedAt)
+		switch job.State {
+		case nanabushv1.JobState_JOB_STATE_COMPLETED:
+			state, age = "completed", since(job.CompletedAt)
+		case nanabushv1.JobState_JOB_STATE_INTERRUPTED:
+			state, age = "interrupted", since(job.CompletedAt)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s -> %s\t%s\n", job.JobId, state, job.Namespace, job.ClientId,
+			job.SourceLanguage, job.TargetLanguage, age)
+	}
+	return w.Flush()
+}
+
+func runCancel(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("cancel", flag.ExitOnError)
+	jobID := fs.String("job-id", "", "Running job to cancel")
+	reason := fs.String("reason", "", "Reason, recorded in the audit log")
+	fs.Parse(args)
+
+	if *jobID == "" {
+		return fmt.Errorf("-job-id is required")
+	}
+
+	c, err := dial()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	resp, err := nanabushv1.NewAdminServiceClient(c.Conn()).CancelJob(adminContext(ctx), &nanabushv1.CancelJobRequest{
+		JobId:  *jobID,
+		Reason: *reason,
+	})
+	if err != nil {
+		return err
+	}
+	if *jsonOutput {
+		printJSON(resp)
+		return nil
+	}
+	fmt.Printf("Cancelled %s (running for %s)\n", resp.Job.JobId, since(resp.Job.StartedAt))
+	return nil
+}
+
+func runDrain(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("drain", flag.ExitOnError)
+	reason := fs.String("reason", "", "Reason, recorded in the audit log")
+	fs.Parse(args)
+
+	c, err := dial()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	resp, err := nanabushv1.NewAdminServiceClient(c.Conn()).DrainServer(adminContext(ctx), &nanabushv1.DrainServerRequest{Reason: *reason})
+	if err != nil {
+		return err
+	}
+	if *jsonOutput {
+		printJSON(resp)
+		return nil
+	}
+	fmt.Printf("Draining since %s, %d jobs still running\n", resp.DrainingSince.AsTime().Local().Format(time.DateTime), resp.RunningJobs)
+	return nil
+}
+
+// since formats the time elapsed since ts, to the second.
+func runDeadLetters(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("dead-letters", flag.ExitOnError)
+	jobID := fs.String("job-id", "", "Only callbacks for this job")
+	clientID := fs.String("client-id", "", "Only callbacks for this client")
+	fs.Parse(args)
+
+	c, err := dial()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	resp, err := nanabushv1.NewAdminServiceClient(c.Conn()).ListDeadLetters(adminContext(ctx), &nanabushv1.ListDeadLettersRequest{
+		JobId:    *jobID,
+		ClientId: *clientID,
+	})
+	if err != nil {
+		return err
+	}
+	if *jsonOutput {
+		printJSON(resp)
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "JOB ID\tCLIENT ID\tURL\tATTEMPTS\tAGE\tLAST ERROR")
+	for _, letter := range resp.DeadLetters {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n", letter.JobId, letter.ClientId, letter.CallbackUrl,
+			letter.Attempts, since(letter.FailedAt), letter.LastError)
+	}
+	return w.Flush()
+}
+
+func since(ts *timestamppb.Timestamp) string {
+	if ts == nil {
+		return "-"
+	}
+	return time.Since(ts.AsTime()).Round(time.Second).String()
+}
+
+// printJSON prints protobuf messages with protojson and anything else with encoding/json.
+func printJSON(v interface{}) {
+	if msg, ok := v.(proto.Message); ok {
+		data, err := protojson.MarshalOptions{Multiline: true, Indent: "  ", UseProtoNames: true}.Marshal(msg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to encode output: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+		return
+	}
+	printPlainJSON(v)
+}
+
+func printPlainJSON(v interface{}) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to encode output: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(string(data))
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func readInput(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(path)
+}
+
+func writeOutput(path, content string) error {
+	if path == "" {
+		_, err := fmt.Print(content)
+		if !strings.HasSuffix(content, "\n") {
+			fmt.Println()
+		}
+		return err
+	}
+	return os.WriteFile(path, []byte(content), 0o644)
+}
+
+// titleFromPath derives a page title from a file name ("getting-started.md" -> "getting started").
+func titleFromPath(path string) string {
+	if path == "-" {
+		return ""
+	}
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return strings.NewReplacer("-", " ", "_", " ").Replace(name)
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
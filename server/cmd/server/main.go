@@ -2,96 +2,287 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"flag"
 	"fmt"
 	"log"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
+	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/reflection"
 
+	"github.com/dasmlab/nanabush/server/pkg/audit"
+	"github.com/dasmlab/nanabush/server/pkg/backend/replay"
+	"github.com/dasmlab/nanabush/server/pkg/backend/vllm"
+	"github.com/dasmlab/nanabush/server/pkg/config"
+	"github.com/dasmlab/nanabush/server/pkg/feedback"
+	"github.com/dasmlab/nanabush/server/pkg/gateway"
+	"github.com/dasmlab/nanabush/server/pkg/jobs"
+	"github.com/dasmlab/nanabush/server/pkg/languages"
 	nanabushv1 "github.com/dasmlab/nanabush/server/pkg/proto/v1"
+	"github.com/dasmlab/nanabush/server/pkg/sanitize"
+	"github.com/dasmlab/nanabush/server/pkg/scheduler"
 	"github.com/dasmlab/nanabush/server/pkg/service"
+	"github.com/dasmlab/nanabush/server/pkg/tlsconfig"
+	"github.com/dasmlab/nanabush/server/pkg/version"
+	"github.com/dasmlab/nanabush/server/pkg/webhook"
 )
 
-var (
-	port         = flag.Int("port", 50051, "gRPC server port")
-	insecureMode = flag.Bool("insecure", true, "Run server in insecure mode (no TLS)")
-	
-	// TLS configuration flags (for future use)
-	tlsCertPath = flag.String("tls-cert", "", "Path to TLS server certificate")
-	tlsKeyPath  = flag.String("tls-key", "", "Path to TLS server private key")
-	tlsCAPath   = flag.String("tls-ca", "", "Path to CA certificate for client verification (mTLS)")
-)
+// loader reads the configuration from -config, NANABUSH_* variables and flags
+var loader = config.NewLoader(flag.CommandLine)
+
+// shutdownGrace is how long shutdown waits for interrupted jobs to answer
+// their callers, and then for remaining streams to end
+const shutdownGrace = 10 * time.Second
 
 func main() {
-	flag.Parse()
+	if err := loader.Parse(os.Args[1:]); err != nil {
+		log.Fatal(err)
+	}
 	
 	logger := log.New(os.Stdout, "[nanabush-grpc] ", log.LstdFlags|log.Lshortfile)
-	logger.Printf("Starting Nanabush gRPC server on port %d (insecure=%v)", *port, *insecureMode)
+	cfg, err := loader.Load()
+	if err != nil {
+		logger.Fatalf("Configuration error: %v", err)
+	}
+	if loader.Path() != "" {
+		logger.Printf("Configuration loaded from %s", loader.Path())
+	}
+	logger.Printf("Starting Nanabush gRPC server %s on port %d (insecure=%v)", version.Version, cfg.Server.Port, cfg.TLS.Insecure)
 	
 	// Create listener
-	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", *port))
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.Server.Port))
 	if err != nil {
-		logger.Fatalf("Failed to listen on port %d: %v", *port, err)
+		logger.Fatalf("Failed to listen on port %d: %v", cfg.Server.Port, err)
 	}
 	
 	// Create gRPC server with options
 	var opts []grpc.ServerOption
 	
-	// TODO: Configure TLS/mTLS when certificates are available
-	if !*insecureMode {
-		// TODO: Load TLS credentials from flags
-		// For now, log warning and continue with insecure
-		logger.Println("WARNING: TLS requested but not yet implemented, using insecure mode")
-		opts = append(opts, grpc.Creds(insecure.NewCredentials()))
+	// Configure TLS, or mTLS when a client CA is given (also used by the REST gateway)
+	var tlsConfig *tls.Config
+	if !cfg.TLS.Insecure {
+		tlsConfig, err = tlsconfig.Server(tlsconfig.Files{
+			CertFile: cfg.TLS.Cert,
+			KeyFile:  cfg.TLS.Key,
+			CAFile:   cfg.TLS.CA,
+		})
+		if err != nil {
+			logger.Fatalf("Invalid TLS configuration: %v", err)
+		}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+		logger.Printf("TLS enabled: cert=%s, mtls=%v", cfg.TLS.Cert, cfg.TLS.CA != "")
 	} else {
 		opts = append(opts, grpc.Creds(insecure.NewCredentials()))
 	}
 	
+	// Keepalive pings notice dead Session streams within ~40s, even when idle
+	opts = append(opts,
+		grpc.KeepaliveParams(keepalive.ServerParameters{Time: 30 * time.Second, Timeout: 10 * time.Second}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{MinTime: 10 * time.Second, PermitWithoutStream: true}),
+	)
+	
 	// Create gRPC server
 	s := grpc.NewServer(opts...)
 	
-	// Register health check service
+	// Register health check service (statuses are set by the health watcher below)
 	healthServer := health.NewServer()
 	grpc_health_v1.RegisterHealthServer(s, healthServer)
-	healthServer.SetServingStatus("", grpc_health_v1.HealthCheckResponse_SERVING)
 	
 	// Register translation service
-	// TODO: Implement actual vLLM backend integration
-	translationService := service.NewTranslationService(nil, logger)
+	// Without a backend URL the service returns placeholder translations
+	var backend service.TranslatorBackend
+	if cfg.Backend.URL != "" {
+		backend = vllm.New(vllm.Config{
+			BaseURL: cfg.Backend.URL,
+			Model:   cfg.Backend.Model,
+		})
+		logger.Printf("Using vLLM backend: url=%s, model=%q", cfg.Backend.URL, cfg.Backend.Model)
+	} else if cfg.Backend.FixturesDir == "" {
+		logger.Println("WARNING: no backend URL configured, returning placeholder translations")
+	}
+	if cfg.Backend.FixturesDir != "" {
+		if backend, err = fixtureBackend(cfg.Backend.FixturesMode, cfg.Backend.FixturesDir, cfg.Backend.Model, backend, logger); err != nil {
+			logger.Fatalf("Invalid fixture configuration: %v", err)
+		}
+		logger.Printf("WARNING: fixture mode %q enabled: dir=%s", cfg.Backend.FixturesMode, cfg.Backend.FixturesDir)
+	}
+	translationService := service.NewTranslationService(backend, logger)
+	translationService.ApplySettings(serviceSettings(cfg))
+	if !cfg.Features.OutputGuard {
+		translationService.Guard = nil
+		logger.Println("WARNING: output guard disabled, backend output is not checked before it is returned")
+	}
+	
+	if cfg.Features.QualityScoring {
+		logger.Printf("Quality scoring enabled: review_threshold=%.2f", cfg.Features.ReviewThreshold)
+	} else {
+		translationService.Quality = nil
+	}
+	
+	translationService.Scheduler = scheduler.New(schedulerConfig(cfg))
+	logger.Printf("Scheduler configured: max_concurrent=%d, starvation_timeout=%v, namespace_weights=%v",
+		cfg.Scheduler.MaxConcurrent, cfg.Scheduler.StarvationTimeout, cfg.Scheduler.NamespaceWeights)
+	
+	pairs, err := languages.ParsePairs(cfg.Languages.Pairs)
+	if err != nil {
+		logger.Fatalf("Invalid -language-pairs: %v", err)
+	}
+	translationService.Languages = languages.NewRegistry(pairs)
+	logger.Printf("Language pairs configured: %v", pairs)
+	
+	if cfg.Features.PIIScrubbing {
+		var sanitizerConfig sanitize.Config
+		if cfg.Sanitizer.RulesFile != "" {
+			if sanitizerConfig, err = sanitize.LoadConfig(cfg.Sanitizer.RulesFile); err != nil {
+				logger.Fatalf("Invalid -sanitizer-rules: %v", err)
+			}
+		}
+		if len(cfg.Sanitizer.InternalDomains) > 0 {
+			sanitizerConfig.InternalDomains = cfg.Sanitizer.InternalDomains
+		}
+		sanitizerConfig.HashKey = []byte(cfg.Sanitizer.HashKey)
+		sanitizer, err := sanitize.New(sanitizerConfig)
+		if err != nil {
+			logger.Fatalf("Invalid sanitizer configuration: %v", err)
+		}
+		translationService.Sanitizer = sanitizer
+		logger.Printf("PII scrubbing enabled: rules=%v", sanitizer.RuleNames())
+	} else {
+		translationService.Sanitizer = nil
+		logger.Println("WARNING: PII scrubbing disabled, raw content will be sent to the backend")
+	}
+	
+	if cfg.Audit.Log != "" {
+		auditWriter, err := audit.Open(cfg.Audit.Log)
+		if err != nil {
+			logger.Fatalf("Invalid -audit-log: %v", err)
+		}
+		defer auditWriter.Close()
+		translationService.Audit = auditWriter
+		logger.Printf("Audit log enabled: %s", cfg.Audit.Log)
+	} else {
+		logger.Println("WARNING: audit log disabled, translation calls are not recorded")
+	}
+	
+	translationService.Jobs = jobs.NewStore(cfg.Limits.JobHistory, cfg.Limits.JobRetention)
+	if cfg.Shutdown.JobHistoryFile != "" {
+		restored, err := translationService.Jobs.Restore(cfg.Shutdown.JobHistoryFile)
+		if err != nil {
+			logger.Printf("WARNING: job history not fully restored: %v", err)
+		}
+		logger.Printf("Restored %d jobs from %s", restored, cfg.Shutdown.JobHistoryFile)
+	}
+	logClientConfig(logger, cfg)
+	if cfg.Feedback.Dir != "" {
+		dataset, err := feedback.Open(cfg.Feedback.Dir)
+		if err != nil {
+			logger.Fatalf("Invalid -feedback-dir: %v", err)
+		}
+		translationService.Feedback = dataset
+		logger.Printf("Feedback capture enabled: dataset=%s, records=%d", dataset.Dir(), dataset.Manifest().Records)
+	}
+	if cfg.Webhooks.Secret != "" {
+		dispatcher, err := webhook.New(webhook.Config{
+			Secret:         cfg.Webhooks.Secret,
+			AllowedHosts:   cfg.Webhooks.AllowedHosts,
+			MaxAttempts:    cfg.Webhooks.MaxAttempts,
+			InitialBackoff: cfg.Webhooks.InitialBackoff,
+			MaxBackoff:     cfg.Webhooks.MaxBackoff,
+			Timeout:        cfg.Webhooks.Timeout,
+			DeadLetterFile: cfg.Webhooks.DeadLetterFile,
+		}, logger)
+		if err != nil {
+			logger.Fatalf("Invalid webhook configuration: %v", err)
+		}
+		translationService.Webhooks = dispatcher
+		logger.Printf("Job callbacks enabled: allowed_hosts=%v, max_attempts=%d, dead_letters=%q",
+			cfg.Webhooks.AllowedHosts, cfg.Webhooks.MaxAttempts, cfg.Webhooks.DeadLetterFile)
+	} else {
+		logger.Println("Job callbacks disabled: set -webhook-secret to enable callback_url and SubmitTranslate")
+	}
+	
 	nanabushv1.RegisterTranslationServiceServer(s, translationService)
 	
+	// Register the admin service only when callers can be authorized
+	adminService := service.NewAdminService(translationService, healthServer, logger)
+	adminService.Token = cfg.Admin.Token
+	adminService.AllowedCommonNames = cfg.Admin.ClientCNs
+	if adminService.Token != "" || len(adminService.AllowedCommonNames) > 0 {
+		nanabushv1.RegisterAdminServiceServer(s, adminService)
+		logger.Printf("Admin service enabled: token=%v, client_cns=%v", adminService.Token != "", adminService.AllowedCommonNames)
+		if adminService.Token != "" && cfg.TLS.Insecure {
+			logger.Println("WARNING: admin token is sent in plaintext without TLS")
+		}
+	} else {
+		logger.Println("Admin service disabled: set -admin-token or -admin-client-cns to enable it")
+	}
+	
 	// Enable reflection for grpcurl/debugging (can be disabled in production)
 	reflection.Register(s)
 	
+	// The runtime configuration, replaced on reload
+	var current atomic.Pointer[config.Config]
+	current.Store(cfg)
+	
 	// Start periodic cleanup goroutine for expired clients
 	cleanupCtx, cleanupCancel := context.WithCancel(context.Background())
 	defer cleanupCancel()
 	
 	go func() {
-		ticker := time.NewTicker(5 * time.Minute) // Run cleanup every 5 minutes
-		defer ticker.Stop()
-		
-		maxIdleTime := 15 * time.Minute // Remove clients idle for more than 15 minutes
+		timer := time.NewTimer(cfg.Clients.CleanupInterval)
+		defer timer.Stop()
 		
 		for {
 			select {
-			case <-ticker.C:
-				translationService.CleanupExpiredClients(maxIdleTime)
+			case <-timer.C:
+				// Read on every run so a reload applies from the next one
+				clients := current.Load().Clients
+				translationService.CleanupExpiredClients(clients.IdleTimeout)
+				translationService.Jobs.Prune()
+				timer.Reset(clients.CleanupInterval)
 			case <-cleanupCtx.Done():
 				return
 			}
 		}
 	}()
-	logger.Println("Started client cleanup goroutine (runs every 5 minutes)")
+	logger.Printf("Started client cleanup goroutine (runs every %v, idle_timeout=%v)", cfg.Clients.CleanupInterval, cfg.Clients.IdleTimeout)
+	
+	// Reload safe-to-change settings on SIGHUP
+	hupChan := make(chan os.Signal, 1)
+	signal.Notify(hupChan, syscall.SIGHUP)
+	go func() {
+		for range hupChan {
+			next, err := loader.Load()
+			if err != nil {
+				logger.Printf("Configuration reload failed, keeping the current configuration: %v", err)
+				continue
+			}
+			if changed := cfg.RestartRequired(next); len(changed) > 0 {
+				logger.Printf("WARNING: restart to apply changes to: %s", strings.Join(changed, ", "))
+			}
+			translationService.ApplySettings(serviceSettings(next))
+			translationService.Scheduler.Reconfigure(schedulerConfig(next))
+			adminService.SetCredentials(next.Admin.Token, next.Admin.ClientCNs)
+			current.Store(next)
+			logger.Printf("Configuration reloaded: heartbeat_interval=%v, idle_timeout=%v, cleanup_interval=%v, registration_expiry=%v, max_concurrent=%d, max_document_chars=%d, review_threshold=%.2f",
+				next.Clients.HeartbeatInterval, next.Clients.IdleTimeout, next.Clients.CleanupInterval, next.Clients.RegistrationExpiry,
+				next.Scheduler.MaxConcurrent, next.Limits.MaxDocumentChars, next.Features.ReviewThreshold)
+			logClientConfig(logger, next)
+		}
+	}()
 	
 	// Start periodic metrics logging
 	metricsCtx, metricsCancel := context.WithCancel(context.Background())
@@ -108,6 +299,15 @@ func main() {
 				metrics := translationService.GetClientMetrics()
 				logger.Printf("Client metrics: total_registered=%d", metrics.TotalClients)
 				
+				// Log scheduler queue state
+				queueStats := translationService.Scheduler.Stats()
+				logger.Printf("Scheduler: running=%d, queued=%d (interactive=%d, normal=%d, bulk=%d), avg_wait=%v",
+					queueStats.Running, queueStats.QueueDepth,
+					queueStats.DepthByPriority[scheduler.PriorityInteractive],
+					queueStats.DepthByPriority[scheduler.PriorityNormal],
+					queueStats.DepthByPriority[scheduler.PriorityBulk],
+					queueStats.AvgWait)
+				
 				if metrics.TotalClients > 0 {
 					// Log namespace distribution
 					for ns, count := range metrics.ClientsByNamespace {
@@ -148,15 +348,71 @@ func main() {
 	}()
 	logger.Println("Started metrics logging goroutine (logs every minute)")
 	
+	// Keep the health statuses in line with the backend
+	healthWatcher := service.NewHealthWatcher(translationService, healthServer, logger)
+	healthWatcher.Interval = cfg.Health.CheckInterval
+	healthWatcher.Timeout = cfg.Health.CheckTimeout
+	healthWatcher.FailureThreshold = cfg.Health.FailureThreshold
+	healthWatcher.SuccessThreshold = cfg.Health.SuccessThreshold
+	healthCtx, healthCancel := context.WithCancel(context.Background())
+	defer healthCancel()
+	go healthWatcher.Run(healthCtx)
+	logger.Printf("Started backend health watcher (checks every %v, unhealthy after %d failures, healthy after %d successes)",
+		cfg.Health.CheckInterval, cfg.Health.FailureThreshold, cfg.Health.SuccessThreshold)
+	
+	// HTTP probes for Kubernetes
+	var httpServer *http.Server
+	if cfg.Server.HTTPPort > 0 {
+		httpServer = &http.Server{
+			Addr:              fmt.Sprintf(":%d", cfg.Server.HTTPPort),
+			Handler:           healthWatcher.Handler(),
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+		go func() {
+			logger.Printf("HTTP probes listening on :%d (/healthz, /readyz)", cfg.Server.HTTPPort)
+			if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Printf("ERROR: HTTP probe server failed: %v", err)
+			}
+		}()
+	}
+	
 	// Start server in goroutine
 	errChan := make(chan error, 1)
 	go func() {
-		logger.Printf("gRPC server listening on :%d", *port)
+		logger.Printf("gRPC server listening on :%d", cfg.Server.Port)
 		if err := s.Serve(lis); err != nil {
 			errChan <- fmt.Errorf("failed to serve: %w", err)
 		}
 	}()
 	
+	// REST/JSON gateway, with the same TLS (and mTLS) as gRPC
+	var gatewayServer *http.Server
+	if cfg.Server.GatewayPort > 0 {
+		handler, err := gateway.New(context.Background(), translationService, logger)
+		if err != nil {
+			logger.Fatalf("Failed to create REST gateway: %v", err)
+		}
+		gatewayLis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.Server.GatewayPort))
+		if err != nil {
+			logger.Fatalf("Failed to listen on gateway port %d: %v", cfg.Server.GatewayPort, err)
+		}
+		if tlsConfig != nil {
+			gatewayTLS := tlsConfig.Clone()
+			gatewayTLS.NextProtos = []string{"http/1.1"}
+			gatewayLis = tls.NewListener(gatewayLis, gatewayTLS)
+		}
+		gatewayServer = &http.Server{
+			Handler:           handler,
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		go func() {
+			logger.Printf("REST gateway listening on :%d (tls=%v)", cfg.Server.GatewayPort, tlsConfig != nil)
+			if err := gatewayServer.Serve(gatewayLis); err != nil && err != http.ErrServerClosed {
+				errChan <- fmt.Errorf("failed to serve REST gateway: %w", err)
+			}
+		}()
+	}
+	
 	// Wait for interrupt signal
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
@@ -165,16 +421,57 @@ func main() {
 	case err := <-errChan:
 		logger.Fatalf("Server error: %v", err)
 	case sig := <-sigChan:
-		logger.Printf("Received signal: %v, shutting down gracefully...", sig)
+		logger.Printf("Received signal: %v, draining...", sig)
+		shutdown := current.Load().Shutdown
 		
-		// Graceful shutdown with timeout
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
+		// Stop taking new jobs; heartbeats and sessions tell clients to go elsewhere,
+		// and readiness probes take the pod out of the service endpoints
+		translationService.Drain()
+		healthServer.SetServingStatus(service.TranslationServiceName, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
 		
-		// Set health status to NOT_SERVING
-		healthServer.SetServingStatus("", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+		// Let in-flight translations finish, up to the drain timeout
+		drainCtx, cancelDrain := context.WithTimeout(context.Background(), shutdown.DrainTimeout)
+		finished := translationService.WaitForJobs(drainCtx)
+		cancelDrain()
+		if !finished {
+			interrupted := translationService.InterruptJobs()
+			logger.Printf("Drain: interrupted %d jobs, callers were told to retry elsewhere", len(interrupted))
+			
+			// Give the interrupted handlers a moment to answer their callers
+			ctx, cancel := context.WithTimeout(context.Background(), shutdownGrace)
+			translationService.Running.Wait(ctx)
+			cancel()
+		}
+		translationService.CloseSessions()
 		
-		// Graceful stop
+		// Deliver the callbacks of finished and interrupted jobs; the rest are dead-lettered
+		if translationService.Webhooks != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), shutdownGrace)
+			if pending := translationService.Webhooks.Close(ctx); pending > 0 {
+				logger.Printf("Callbacks: %d were pending at shutdown, see the dead letters for any not delivered", pending)
+			}
+			cancel()
+		}
+		
+		if shutdown.JobHistoryFile != "" {
+			saved, err := translationService.Jobs.Save(shutdown.JobHistoryFile)
+			if err != nil {
+				logger.Printf("ERROR: %v", err)
+			} else {
+				logger.Printf("Saved %d jobs to %s", saved, shutdown.JobHistoryFile)
+			}
+		}
+		
+		// Everything NOT_SERVING from here on, whatever the health watcher sees
+		healthCancel()
+		healthServer.Shutdown()
+		
+		// Graceful stop for anything left, such as streams
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownGrace)
+		defer cancel()
+		if gatewayServer != nil {
+			gatewayServer.Shutdown(ctx)
+		}
 		stopped := make(chan struct{})
 		go func() {
 			s.GracefulStop()
@@ -188,6 +485,65 @@ func main() {
 			logger.Println("Graceful shutdown timeout, forcing stop...")
 			s.Stop()
 		}
+		if httpServer != nil {
+			httpServer.Close()
+		}
+	}
+}
+
+// fixtureBackend wraps backend to record or replay fixtures.
+func fixtureBackend(mode, dir, model string, backend service.TranslatorBackend, logger *log.Logger) (service.TranslatorBackend, error) {
+	switch mode {
+	case "replay":
+		return replay.NewReplayer(dir, model)
+	case "record", "replay-or-record":
+		if backend == nil {
+			return nil, fmt.Errorf("-fixtures-mode=%s needs a backend (-backend-url) to record from", mode)
+		}
+		recorder, err := replay.NewRecorder(dir, model, backend, logger)
+		if err != nil {
+			return nil, err
+		}
+		if mode == "record" {
+			return recorder, nil
+		}
+		replayer, err := replay.NewReplayer(dir, model)
+		if err != nil {
+			return nil, err
+		}
+		replayer.Fallback = recorder
+		return replayer, nil
+	default:
+		return nil, fmt.Errorf("unknown -fixtures-mode %q (want replay, record or replay-or-record)", mode)
+	}
+}
+
+// serviceSettings returns the runtime settings of the translation service.
+func serviceSettings(cfg *config.Config) service.Settings {
+	policy, _ := cfg.VersionPolicy() // Checked by Validate
+	return service.Settings{
+		HeartbeatInterval:  cfg.Clients.HeartbeatInterval,
+		RegistrationExpiry: cfg.Clients.RegistrationExpiry,
+		MaxDocumentChars:   cfg.Limits.MaxDocumentChars,
+		ReviewThreshold:    cfg.Features.ReviewThreshold,
+		VersionPolicy:      policy,
+	}
+}
+
+// schedulerConfig returns the scheduler configuration.
+func schedulerConfig(cfg *config.Config) scheduler.Config {
+	return scheduler.Config{
+		MaxConcurrent:          cfg.Scheduler.MaxConcurrent,
+		NamespaceWeights:       cfg.Scheduler.NamespaceWeights,
+		DefaultNamespaceWeight: 1,
+		StarvationTimeout:      cfg.Scheduler.StarvationTimeout,
 	}
 }
 
+// logClientConfig logs the client registration settings.
+func logClientConfig(logger *log.Logger, cfg *config.Config) {
+	logger.Printf("Client registrations: heartbeat_interval=%v, registration_expiry=%v", cfg.Clients.HeartbeatInterval, cfg.Clients.RegistrationExpiry)
+	if cfg.Clients.MinVersion != "" || len(cfg.Clients.DeprecatedVersions) > 0 || len(cfg.Clients.BlockedVersions) > 0 {
+		logger.Printf("Client version policy: minimum=%q, deprecated=%q, blocked=%q", cfg.Clients.MinVersion, cfg.Clients.DeprecatedVersions, cfg.Clients.BlockedVersions)
+	}
+}
@@ -0,0 +1,231 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Outcomes recorded for a call.
+const (
+	OutcomeSuccess  = "success"
+	OutcomeFailed   = "failed"
+	OutcomeRejected = "rejected" // Invalid request or output rejected by the guard
+	OutcomeSkipped  = "skipped"  // Source already in the target language
+)
+
+// Record is one audited translation call or admin action. Content is never stored, only its hash.
+type Record struct {
+	Seq  uint64    `json:"seq"`
+	Time time.Time `json:"time"`
+	RPC  string    `json:"rpc"`
+
+	ClientID   string `json:"client_id,omitempty"`
+	ClientName string `json:"client_name,omitempty"`
+	Namespace  string `json:"namespace,omitempty"`
+
+	JobID         string `json:"job_id,omitempty"`
+	PageID        string `json:"page_id,omitempty"`
+	SourceWikiURI string `json:"source_wiki_uri,omitempty"`
+
+	SourceLanguage string `json:"source_language,omitempty"`
+	TargetLanguage string `json:"target_language,omitempty"`
+	Model          string `json:"model,omitempty"`
+
+	SourceSHA256 string `json:"source_sha256,omitempty"`
+	OutputSHA256 string `json:"output_sha256,omitempty"`
+
+	Outcome string         `json:"outcome"`
+	Error   string         `json:"error,omitempty"`
+	Masked  map[string]int `json:"masked,omitempty"`
+
+	// Actor and Reason identify the caller and stated reason for admin actions
+	Actor  string `json:"actor,omitempty"`
+	Reason string `json:"reason,omitempty"`
+
+	// PrevHash is the Hash of the previous record ("" for the first record).
+	PrevHash string `json:"prev_hash"`
+	// Hash is the SHA-256 of this record serialized with Hash empty.
+	Hash string `json:"hash,omitempty"`
+}
+
+// HashContent returns the hex SHA-256 of content, or "" for empty content.
+func HashContent(content string) string {
+	if content == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// computeHash hashes r with its Hash field cleared.
+func computeHash(r Record) (string, error) {
+	r.Hash = ""
+	data, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Log is an append-only, hash-chained JSON lines audit log. Each record
+// carries the hash of the previous one, so editing, reordering or deleting a
+// line breaks the chain and is caught by Verify.
+//
+// A log written to stdout starts a new chain at seq 1 each time the server
+// starts, so a collected stdout log is a series of segments. Verify accepts
+// a seq 1 record as the start of a new segment; records removed from the end
+// of a segment cannot be detected, so compare Summary.Segments with the
+// number of server starts.
+type Log struct {
+	mu       sync.Mutex
+	w        io.Writer
+	closer   io.Closer
+	seq      uint64
+	prevHash string
+}
+
+// Open opens an audit log. "-" writes to stdout, starting a new segment. An
+// existing file is verified and appended to, continuing its chain from the
+// last record.
+func Open(path string) (*Log, error) {
+	if path == "-" {
+		return &Log{w: os.Stdout}, nil
+	}
+
+	l := &Log{}
+	if existing, err := os.Open(path); err == nil {
+		last, summary, verr := verify(existing)
+		existing.Close()
+		if verr != nil {
+			return nil, fmt.Errorf("existing audit log %s failed verification after %d records: %w", path, summary.Records, verr)
+		}
+		if last != nil {
+			l.seq = last.Seq
+			l.prevHash = last.Hash
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	l.w = f
+	l.closer = f
+	return l, nil
+}
+
+// Append chains and writes a record. Seq, PrevHash and Hash are set by the log;
+// Time is set if zero.
+func (l *Log) Append(r Record) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if r.Time.IsZero() {
+		r.Time = time.Now().UTC()
+	}
+	r.Seq = l.seq + 1
+	r.PrevHash = l.prevHash
+	hash, err := computeHash(r)
+	if err != nil {
+		return err
+	}
+	r.Hash = hash
+
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if _, err := l.w.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	if f, ok := l.w.(*os.File); ok && f != os.Stdout {
+		if err := f.Sync(); err != nil {
+			return fmt.Errorf("failed to sync audit log: %w", err)
+		}
+	}
+
+	l.seq = r.Seq
+	l.prevHash = r.Hash
+	return nil
+}
+
+// Close closes the underlying file.
+func (l *Log) Close() error {
+	if l.closer == nil {
+		return nil
+	}
+	return l.closer.Close()
+}
+
+// Summary describes a verified audit log.
+type Summary struct {
+	Records  int // Valid records
+	Segments int // Chains started at seq 1, one per server start for a stdout log
+}
+
+// Verify checks the hash chain of an audit log and returns a summary of the
+// valid records. The log must start at seq 1 with no previous hash, so
+// records removed from its head are caught. Lines that are not JSON objects
+// (e.g. interleaved server logs when writing to stdout) are ignored.
+func Verify(r io.Reader) (Summary, error) {
+	_, summary, err := verify(r)
+	return summary, err
+}
+
+func verify(r io.Reader) (*Record, Summary, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	var last *Record
+	var summary Summary
+	line := 0
+	for scanner.Scan() {
+		line++
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 || raw[0] != '{' {
+			continue
+		}
+
+		var rec Record
+		if err := json.Unmarshal(raw, &rec); err != nil {
+			return last, summary, fmt.Errorf("line %d: invalid record: %w", line, err)
+		}
+		want, err := computeHash(rec)
+		if err != nil {
+			return last, summary, fmt.Errorf("line %d: %w", line, err)
+		}
+		if rec.Hash != want {
+			return last, summary, fmt.Errorf("line %d (seq %d): hash mismatch, record was modified", line, rec.Seq)
+		}
+		switch {
+		case rec.Seq == 1 && rec.PrevHash == "":
+			summary.Segments++
+		case last == nil:
+			if rec.Seq == 1 {
+				return last, summary, fmt.Errorf("line %d (seq 1): first record has a previous hash", line)
+			}
+			return last, summary, fmt.Errorf("line %d: log starts at seq %d, earlier records are missing", line, rec.Seq)
+		case rec.PrevHash != last.Hash:
+			return last, summary, fmt.Errorf("line %d (seq %d): chain broken, previous hash does not match seq %d", line, rec.Seq, last.Seq)
+		case rec.Seq != last.Seq+1:
+			return last, summary, fmt.Errorf("line %d: sequence jumps from %d to %d", line, last.Seq, rec.Seq)
+		}
+		last = &rec
+		summary.Records++
+	}
+	if err := scanner.Err(); err != nil {
+		return last, summary, err
+	}
+	return last, summary, nil
+}
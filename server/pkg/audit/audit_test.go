@@ -0,0 +1,105 @@
+package audit
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeLog appends n records to a fresh log and returns its lines.
+func writeLog(t *testing.T, n int) []string {
+	t.Helper()
+	var buf bytes.Buffer
+	l := &Log{w: &buf}
+	for i := 0; i < n; i++ {
+		if err := l.Append(Record{RPC: "Translate", JobID: "job", Outcome: OutcomeSuccess}); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+	return strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+}
+
+func TestVerify(t *testing.T) {
+	lines := writeLog(t, 4)
+	modified := append([]string(nil), lines...)
+	modified[1] = strings.Replace(modified[1], `"job_id":"job"`, `"job_id":"other"`, 1)
+	restarted := append(append([]string(nil), lines...), "server log line")
+	restarted = append(restarted, writeLog(t, 2)...)
+
+	tests := []struct {
+		name         string
+		lines        []string
+		wantRecords  int
+		wantSegments int
+		wantErr      string
+	}{
+		{"intact", lines, 4, 1, ""},
+		{"empty", nil, 0, 0, ""},
+		{"modified record", modified, 1, 1, "hash mismatch"},
+		{"deleted middle record", []string{lines[0], lines[2], lines[3]}, 1, 1, "chain broken"},
+		{"reordered records", []string{lines[0], lines[2], lines[1], lines[3]}, 1, 1, "chain broken"},
+		{"truncated head", lines[2:], 0, 0, "log starts at seq 3"},
+		{"restarted on stdout", restarted, 6, 2, ""},
+		{"truncated head of a later segment", append(append([]string(nil), lines...), writeLog(t, 3)[1:]...), 4, 1, "chain broken"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary, err := Verify(strings.NewReader(strings.Join(tt.lines, "\n")))
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("Verify = %v, want an error containing %q", err, tt.wantErr)
+			}
+			if summary.Records != tt.wantRecords || summary.Segments != tt.wantSegments {
+				t.Errorf("summary %+v, want %d records in %d segments", summary, tt.wantRecords, tt.wantSegments)
+			}
+		})
+	}
+}
+
+func TestOpenContinuesChain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	for i := 0; i < 2; i++ {
+		l, err := Open(path)
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		for j := 0; j < 3; j++ {
+			if err := l.Append(Record{RPC: "Translate", Outcome: OutcomeSuccess}); err != nil {
+				t.Fatalf("Append: %v", err)
+			}
+		}
+		if err := l.Close(); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	summary, err := Verify(f)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if summary.Records != 6 || summary.Segments != 1 {
+		t.Errorf("summary %+v, want 6 records in one segment", summary)
+	}
+
+	// A broken file is not appended to
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+	if err := os.WriteFile(path, []byte(strings.Join(lines[1:], "")), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(path); err == nil || !strings.Contains(err.Error(), "failed verification") {
+		t.Errorf("Open of a truncated log = %v, want a verification error", err)
+	}
+}
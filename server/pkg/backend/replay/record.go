@@ -0,0 +1,152 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/dasmlab/nanabush/server/pkg/proto/v1"
+	"github.com/dasmlab/nanabush/server/pkg/service"
+)
+
+// Recorder is a TranslatorBackend decorator that passes calls through to a
+// real backend and saves each request and response as a fixture for Replayer.
+// Documents are recorded as separate title and markdown fixtures. Glossaries
+// are passed on when the backend follows them, and recorded either way.
+// Failed and cancelled calls are not recorded, so a transient backend error
+// is retried rather than replayed.
+type Recorder struct {
+	dir     string
+	model   string
+	backend service.TranslatorBackend
+	logger  *log.Logger
+}
+
+var (
+	_ service.TranslatorBackend  = (*Recorder)(nil)
+	_ service.GlossaryTranslator = (*Recorder)(nil)
+	_ service.ModelLister        = (*Recorder)(nil)
+)
+
+// NewRecorder records calls to backend, which serves model, into dir,
+// creating it if needed.
+func NewRecorder(dir, model string, backend service.TranslatorBackend, logger *log.Logger) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create fixture directory: %w", err)
+	}
+	return &Recorder{dir: dir, model: model, backend: backend, logger: logger}, nil
+}
+
+// Name identifies the wrapped backend.
+func (r *Recorder) Name() string {
+	if named, ok := r.backend.(interface{ Name() string }); ok {
+		return named.Name()
+	}
+	return fmt.Sprintf("%T", r.backend)
+}
+
+// TranslateTitle calls the backend and records the result.
+func (r *Recorder) TranslateTitle(ctx context.Context, title, sourceLang, targetLang string) (string, error) {
+	return r.TranslateTitleWithGlossary(ctx, title, sourceLang, targetLang, nil)
+}
+
+// TranslateTitleWithGlossary calls the backend with the glossary and records the result.
+func (r *Recorder) TranslateTitleWithGlossary(ctx context.Context, title, sourceLang, targetLang string, glossary []*nanabushv1.GlossaryTerm) (string, error) {
+	var out string
+	var err error
+	if translator, ok := r.backend.(service.GlossaryTranslator); ok && len(glossary) > 0 {
+		out, err = translator.TranslateTitleWithGlossary(ctx, title, sourceLang, targetLang, glossary)
+	} else {
+		out, err = r.backend.TranslateTitle(ctx, title, sourceLang, targetLang)
+	}
+	if err == nil && ctx.Err() == nil {
+		r.record(KindTitle, title, sourceLang, targetLang, glossary, out)
+	}
+	return out, err
+}
+
+// TranslateDocument calls the backend and records the title and markdown.
+func (r *Recorder) TranslateDocument(ctx context.Context, doc *nanabushv1.DocumentContent, sourceLang, targetLang string) (*nanabushv1.DocumentContent, error) {
+	return r.TranslateDocumentWithGlossary(ctx, doc, sourceLang, targetLang, nil)
+}
+
+// TranslateDocumentWithGlossary calls the backend with the glossary and
+// records the title and markdown.
+func (r *Recorder) TranslateDocumentWithGlossary(ctx context.Context, doc *nanabushv1.DocumentContent, sourceLang, targetLang string, glossary []*nanabushv1.GlossaryTerm) (*nanabushv1.DocumentContent, error) {
+	var translated *nanabushv1.DocumentContent
+	var err error
+	if translator, ok := r.backend.(service.GlossaryTranslator); ok && len(glossary) > 0 {
+		translated, err = translator.TranslateDocumentWithGlossary(ctx, doc, sourceLang, targetLang, glossary)
+	} else {
+		translated, err = r.backend.TranslateDocument(ctx, doc, sourceLang, targetLang)
+	}
+	if err != nil || ctx.Err() != nil {
+		return translated, err
+	}
+
+	if doc.Title != "" {
+		r.record(KindTitle, doc.Title, sourceLang, targetLang, glossary, translated.Title)
+	}
+	if doc.Markdown != "" {
+		r.record(KindMarkdown, doc.Markdown, sourceLang, targetLang, glossary, translated.Markdown)
+	}
+	return translated, nil
+}
+
+// CheckHealth checks the wrapped backend.
+func (r *Recorder) CheckHealth(ctx context.Context) error {
+	return r.backend.CheckHealth(ctx)
+}
+
+// Models lists the wrapped backend's models, if it reports them.
+func (r *Recorder) Models(ctx context.Context) ([]service.ModelInfo, error) {
+	if lister, ok := r.backend.(service.ModelLister); ok {
+		return lister.Models(ctx)
+	}
+	return nil, nil
+}
+
+// record writes a fixture. Failures are logged rather than failing the translation.
+func (r *Recorder) record(kind, content, sourceLang, targetLang string, glossary []*nanabushv1.GlossaryTerm, output string) {
+	fixture := Fixture{
+		Key:            Key(r.model, kind, content, sourceLang, targetLang, glossary),
+		Model:          r.model,
+		Kind:           kind,
+		SourceLanguage: sourceLang,
+		TargetLanguage: targetLang,
+		Content:        content,
+		Glossary:       glossary,
+		Output:         output,
+		Backend:        r.Name(),
+		RecordedAt:     time.Now().UTC(),
+	}
+	if err := writeFixture(r.dir, fixture); err != nil {
+		r.logger.Printf("Failed to record fixture: key=%s, err=%v", fixture.Key, err)
+	}
+}
+
+// writeFixture atomically writes a fixture file, indented for readable diffs.
+func writeFixture(dir string, fixture Fixture) error {
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, fixture.Key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, fixture.Key+".json"))
+}
@@ -0,0 +1,199 @@
+package replay
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dasmlab/nanabush/server/pkg/backend/vllm"
+	"github.com/dasmlab/nanabush/server/pkg/proto/v1"
+	"github.com/dasmlab/nanabush/server/pkg/service"
+)
+
+// Kinds of content a fixture holds.
+const (
+	KindTitle    = string(vllm.KindTitle)
+	KindMarkdown = string(vllm.KindMarkdown)
+)
+
+// ErrNoFixture is returned by Replayer when no fixture matches a request.
+var ErrNoFixture = errors.New("no recorded fixture for request")
+
+// Fixture is one recorded backend call, stored as <dir>/<key>.json.
+type Fixture struct {
+	Key            string                     `json:"key"`
+	Model          string                     `json:"model,omitempty"`
+	Kind           string                     `json:"kind"`
+	SourceLanguage string                     `json:"source_language"`
+	TargetLanguage string                     `json:"target_language"`
+	Content        string                     `json:"content"`
+	Glossary       []*nanabushv1.GlossaryTerm `json:"glossary,omitempty"`
+	Output         string                     `json:"output,omitempty"`
+	// Error makes Replayer fail the call. Recorder never sets it; write it by
+	// hand to replay a backend failure.
+	Error      string    `json:"error,omitempty"`
+	Backend    string    `json:"backend,omitempty"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// Key identifies a request by a hash of the model and the prompt the vLLM
+// backend renders for it (vllm.NormalizedPrompt), glossary included, so
+// changing any of them invalidates recorded fixtures. Tag case, line
+// endings, trailing whitespace and the per-request delimiter nonce do not.
+func Key(model, kind, content, sourceLang, targetLang string, glossary []*nanabushv1.GlossaryTerm) string {
+	messages := vllm.NormalizedPrompt(vllm.ContentKind(kind), normalizeContent(content),
+		strings.ToLower(strings.TrimSpace(sourceLang)), strings.ToLower(strings.TrimSpace(targetLang)), glossary)
+
+	h := sha256.New()
+	h.Write([]byte(model))
+	h.Write([]byte{0})
+	for _, m := range messages {
+		h.Write([]byte(m.Role))
+		h.Write([]byte{0})
+		h.Write([]byte(m.Content))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// normalizeContent unifies line endings and strips trailing whitespace per line.
+func normalizeContent(content string) string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// Replayer is a TranslatorBackend that serves recorded fixtures instead of
+// calling a model, for deterministic evaluation and tests.
+type Replayer struct {
+	dir   string
+	model string
+
+	// Fallback handles requests with no fixture (typically a Recorder, to fill
+	// in missing fixtures). When nil, misses return ErrNoFixture.
+	Fallback service.TranslatorBackend
+}
+
+var (
+	_ service.TranslatorBackend  = (*Replayer)(nil)
+	_ service.GlossaryTranslator = (*Replayer)(nil)
+)
+
+// NewReplayer serves the fixtures in dir that were recorded from model.
+func NewReplayer(dir, model string) (*Replayer, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("fixture directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("fixture directory %s is not a directory", dir)
+	}
+	return &Replayer{dir: dir, model: model}, nil
+}
+
+// Name identifies the backend.
+func (r *Replayer) Name() string {
+	return "replay"
+}
+
+// Lookup returns the fixture for a request.
+func (r *Replayer) Lookup(kind, content, sourceLang, targetLang string, glossary []*nanabushv1.GlossaryTerm) (*Fixture, error) {
+	key := Key(r.model, kind, content, sourceLang, targetLang, glossary)
+	data, err := os.ReadFile(filepath.Join(r.dir, key+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: kind=%s, %s->%s, key=%s", ErrNoFixture, kind, sourceLang, targetLang, key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("invalid fixture %s: %w", key, err)
+	}
+	return &fixture, nil
+}
+
+// TranslateTitle replays a recorded title translation.
+func (r *Replayer) TranslateTitle(ctx context.Context, title, sourceLang, targetLang string) (string, error) {
+	return r.TranslateTitleWithGlossary(ctx, title, sourceLang, targetLang, nil)
+}
+
+// TranslateTitleWithGlossary replays a title translation recorded with the same glossary.
+func (r *Replayer) TranslateTitleWithGlossary(ctx context.Context, title, sourceLang, targetLang string, glossary []*nanabushv1.GlossaryTerm) (string, error) {
+	out, err := r.replay(KindTitle, title, sourceLang, targetLang, glossary)
+	if errors.Is(err, ErrNoFixture) && r.Fallback != nil {
+		if translator, ok := r.Fallback.(service.GlossaryTranslator); ok && len(glossary) > 0 {
+			return translator.TranslateTitleWithGlossary(ctx, title, sourceLang, targetLang, glossary)
+		}
+		return r.Fallback.TranslateTitle(ctx, title, sourceLang, targetLang)
+	}
+	return out, err
+}
+
+// TranslateDocument replays a document's title and markdown separately.
+func (r *Replayer) TranslateDocument(ctx context.Context, doc *nanabushv1.DocumentContent, sourceLang, targetLang string) (*nanabushv1.DocumentContent, error) {
+	return r.TranslateDocumentWithGlossary(ctx, doc, sourceLang, targetLang, nil)
+}
+
+// TranslateDocumentWithGlossary replays a document recorded with the same glossary.
+func (r *Replayer) TranslateDocumentWithGlossary(ctx context.Context, doc *nanabushv1.DocumentContent, sourceLang, targetLang string, glossary []*nanabushv1.GlossaryTerm) (*nanabushv1.DocumentContent, error) {
+	translated, err := r.replayDocument(doc, sourceLang, targetLang, glossary)
+	if errors.Is(err, ErrNoFixture) && r.Fallback != nil {
+		if translator, ok := r.Fallback.(service.GlossaryTranslator); ok && len(glossary) > 0 {
+			return translator.TranslateDocumentWithGlossary(ctx, doc, sourceLang, targetLang, glossary)
+		}
+		return r.Fallback.TranslateDocument(ctx, doc, sourceLang, targetLang)
+	}
+	return translated, err
+}
+
+func (r *Replayer) replayDocument(doc *nanabushv1.DocumentContent, sourceLang, targetLang string, glossary []*nanabushv1.GlossaryTerm) (*nanabushv1.DocumentContent, error) {
+	translated := &nanabushv1.DocumentContent{
+		Slug:     doc.Slug,
+		Metadata: doc.Metadata,
+	}
+	if doc.Title != "" {
+		title, err := r.replay(KindTitle, doc.Title, sourceLang, targetLang, glossary)
+		if err != nil {
+			return nil, fmt.Errorf("title: %w", err)
+		}
+		translated.Title = title
+	}
+	if doc.Markdown != "" {
+		markdown, err := r.replay(KindMarkdown, doc.Markdown, sourceLang, targetLang, glossary)
+		if err != nil {
+			return nil, fmt.Errorf("markdown: %w", err)
+		}
+		translated.Markdown = markdown
+	}
+	return translated, nil
+}
+
+// CheckHealth succeeds unless the fallback backend is unhealthy.
+func (r *Replayer) CheckHealth(ctx context.Context) error {
+	if r.Fallback != nil {
+		return r.Fallback.CheckHealth(ctx)
+	}
+	return nil
+}
+
+func (r *Replayer) replay(kind, content, sourceLang, targetLang string, glossary []*nanabushv1.GlossaryTerm) (string, error) {
+	fixture, err := r.Lookup(kind, content, sourceLang, targetLang, glossary)
+	if err != nil {
+		return "", err
+	}
+	if fixture.Error != "" {
+		return "", errors.New(fixture.Error)
+	}
+	return fixture.Output, nil
+}
@@ -0,0 +1,257 @@
+package replay_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/dasmlab/nanabush/server/pkg/backend/replay"
+	"github.com/dasmlab/nanabush/server/pkg/proto/v1"
+	"github.com/dasmlab/nanabush/server/pkg/service"
+)
+
+// fixtureModel is the model the fixtures in testdata were recorded from.
+const fixtureModel = "test-model"
+
+var discard = log.New(io.Discard, "", 0)
+
+func docRequest(jobID, title, markdown string) *nanabushv1.TranslateRequest {
+	return &nanabushv1.TranslateRequest{
+		JobId:          jobID,
+		Primitive:      nanabushv1.PrimitiveType_PRIMITIVE_DOC_TRANSLATE,
+		SourceLanguage: "en",
+		TargetLanguage: "fr",
+		Source: &nanabushv1.TranslateRequest_Doc{Doc: &nanabushv1.DocumentContent{
+			Title:    title,
+			Markdown: markdown,
+		}},
+	}
+}
+
+func TestTranslateFromFixtures(t *testing.T) {
+	replayer, err := replay.NewReplayer("testdata", fixtureModel)
+	if err != nil {
+		t.Fatalf("NewReplayer: %v", err)
+	}
+	svc := service.NewTranslationService(replayer, discard)
+
+	// The backend saw the email as a placeholder; the service restores it
+	resp, err := svc.Translate(context.Background(), docRequest("job-1", "Deploying the API",
+		"Run the deployment pipeline from the main branch, then check the dashboard for errors. "+
+			"If the rollout fails, contact ops@example.com before retrying.  \r\n"))
+	if err == nil && !resp.Success {
+		err = errors.New(resp.ErrorMessage)
+	}
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+	if want := "Déployer l'API"; resp.TranslatedTitle != want {
+		t.Errorf("title = %q, want %q", resp.TranslatedTitle, want)
+	}
+	want := "Lancez le pipeline de déploiement depuis la branche principale, puis vérifiez le tableau de bord pour repérer les erreurs. " +
+		"Si le déploiement échoue, contactez ops@example.com avant de réessayer."
+	if resp.TranslatedMarkdown != want {
+		t.Errorf("markdown = %q, want %q", resp.TranslatedMarkdown, want)
+	}
+}
+
+func TestTranslateWithoutFixture(t *testing.T) {
+	tests := []struct {
+		name  string
+		model string
+		title string
+	}{
+		{"unrecorded content", fixtureModel, "Rolling back the API"},
+		{"different model", "other-model", "Deploying the API"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replayer, err := replay.NewReplayer("testdata", tt.model)
+			if err != nil {
+				t.Fatalf("NewReplayer: %v", err)
+			}
+			_, err = replayer.TranslateTitle(context.Background(), tt.title, "en", "fr")
+			if !errors.Is(err, replay.ErrNoFixture) {
+				t.Fatalf("TranslateTitle error = %v, want %v", err, replay.ErrNoFixture)
+			}
+		})
+	}
+}
+
+func TestKey(t *testing.T) {
+	base := replay.Key("m", replay.KindMarkdown, "Hello\nworld", "en", "fr", nil)
+	withGlossary := replay.Key("m", replay.KindMarkdown, "Hello\nworld", "en", "fr", testGlossary)
+	tests := []struct {
+		name string
+		key  string
+		same bool
+	}{
+		{"tag case and spacing", replay.Key("m", replay.KindMarkdown, "Hello\nworld", " EN", "Fr ", nil), true},
+		{"line endings and trailing whitespace", replay.Key("m", replay.KindMarkdown, "Hello  \r\nworld\n", "en", "fr", nil), true},
+		{"empty glossary", replay.Key("m", replay.KindMarkdown, "Hello\nworld", "en", "fr", []*nanabushv1.GlossaryTerm{}), true},
+		{"model", replay.Key("n", replay.KindMarkdown, "Hello\nworld", "en", "fr", nil), false},
+		{"kind changes the prompt", replay.Key("m", replay.KindTitle, "Hello\nworld", "en", "fr", nil), false},
+		{"content", replay.Key("m", replay.KindMarkdown, "Hello\nthere", "en", "fr", nil), false},
+		{"target", replay.Key("m", replay.KindMarkdown, "Hello\nworld", "en", "de", nil), false},
+		{"glossary", withGlossary, false},
+	}
+	for _, tt := range tests {
+		if same := tt.key == base; same != tt.same {
+			t.Errorf("%s: same key = %v, want %v", tt.name, same, tt.same)
+		}
+	}
+	otherTarget := []*nanabushv1.GlossaryTerm{{Source: "pipeline", Target: "chaîne"}}
+	if replay.Key("m", replay.KindMarkdown, "Hello\nworld", "en", "fr", otherTarget) == withGlossary {
+		t.Error("glossaries with different targets share a key")
+	}
+}
+
+var testGlossary = []*nanabushv1.GlossaryTerm{{Source: "pipeline", Target: "pipeline"}}
+
+// stubBackend translates every title and document to fixed text.
+type stubBackend struct {
+	calls int
+	err   error
+}
+
+func (b *stubBackend) TranslateTitle(ctx context.Context, title, sourceLang, targetLang string) (string, error) {
+	b.calls++
+	return "Titre", b.err
+}
+
+func (b *stubBackend) TranslateDocument(ctx context.Context, doc *nanabushv1.DocumentContent, sourceLang, targetLang string) (*nanabushv1.DocumentContent, error) {
+	b.calls++
+	if b.err != nil {
+		return nil, b.err
+	}
+	return &nanabushv1.DocumentContent{Title: "Titre", Markdown: "Contenu"}, nil
+}
+
+func (b *stubBackend) CheckHealth(ctx context.Context) error { return nil }
+
+// glossaryStub is a stubBackend that follows glossaries, rendering
+// documents with the first term's target.
+type glossaryStub struct {
+	stubBackend
+	glossaryCalls int
+}
+
+func (b *glossaryStub) TranslateTitleWithGlossary(ctx context.Context, title, sourceLang, targetLang string, glossary []*nanabushv1.GlossaryTerm) (string, error) {
+	b.glossaryCalls++
+	return "Titre " + glossary[0].Target, nil
+}
+
+func (b *glossaryStub) TranslateDocumentWithGlossary(ctx context.Context, doc *nanabushv1.DocumentContent, sourceLang, targetLang string, glossary []*nanabushv1.GlossaryTerm) (*nanabushv1.DocumentContent, error) {
+	b.glossaryCalls++
+	return &nanabushv1.DocumentContent{Title: "Titre " + glossary[0].Target, Markdown: "Contenu " + glossary[0].Target}, nil
+}
+
+func TestRecordThenReplay(t *testing.T) {
+	dir := t.TempDir()
+	backend := &stubBackend{}
+	recorder, err := replay.NewRecorder(dir, fixtureModel, backend, discard)
+	if err != nil {
+		t.Fatalf("NewRecorder: %v", err)
+	}
+	replayer, err := replay.NewReplayer(dir, fixtureModel)
+	if err != nil {
+		t.Fatalf("NewReplayer: %v", err)
+	}
+	replayer.Fallback = recorder
+
+	ctx := context.Background()
+	doc := &nanabushv1.DocumentContent{Title: "Title", Markdown: "Content"}
+	for i := 0; i < 2; i++ {
+		out, err := replayer.TranslateDocument(ctx, doc, "en", "fr")
+		if err != nil {
+			t.Fatalf("TranslateDocument #%d: %v", i+1, err)
+		}
+		if out.Title != "Titre" || out.Markdown != "Contenu" {
+			t.Fatalf("TranslateDocument #%d = %q, %q", i+1, out.Title, out.Markdown)
+		}
+	}
+	if backend.calls != 1 {
+		t.Errorf("backend called %d times, want 1 (the second call is replayed)", backend.calls)
+	}
+
+	fixture, err := replayer.Lookup(replay.KindMarkdown, "Content", "en", "fr", nil)
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if fixture.Model != fixtureModel || fixture.Output != "Contenu" {
+		t.Errorf("fixture = %+v", fixture)
+	}
+
+	// Failed calls are not recorded, so they reach the backend again
+	backend.err = errors.New("model overloaded")
+	if _, err := replayer.TranslateTitle(ctx, "Broken", "en", "fr"); err == nil || err.Error() != "model overloaded" {
+		t.Fatalf("TranslateTitle = %v, want the backend error", err)
+	}
+	if _, err := replayer.Lookup(replay.KindTitle, "Broken", "en", "fr", nil); !errors.Is(err, replay.ErrNoFixture) {
+		t.Fatalf("Lookup after a failed call = %v, want %v", err, replay.ErrNoFixture)
+	}
+	if _, err := replayer.TranslateDocument(ctx, &nanabushv1.DocumentContent{Title: "Broken", Markdown: "Broken too"}, "en", "fr"); err == nil {
+		t.Fatal("TranslateDocument hid the backend error")
+	}
+	if _, err := replayer.Lookup(replay.KindMarkdown, "Broken too", "en", "fr", nil); !errors.Is(err, replay.ErrNoFixture) {
+		t.Fatalf("Lookup after a failed document = %v, want %v", err, replay.ErrNoFixture)
+	}
+	backend.err = nil
+	calls := backend.calls
+	if _, err := replayer.TranslateTitle(ctx, "Broken", "en", "fr"); err != nil {
+		t.Fatalf("TranslateTitle after recovery: %v", err)
+	}
+	if backend.calls != calls+1 {
+		t.Errorf("backend called %d times after recovery, want 1", backend.calls-calls)
+	}
+}
+
+func TestRecordThenReplayWithGlossary(t *testing.T) {
+	dir := t.TempDir()
+	backend := &glossaryStub{}
+	recorder, err := replay.NewRecorder(dir, fixtureModel, backend, discard)
+	if err != nil {
+		t.Fatalf("NewRecorder: %v", err)
+	}
+	replayer, err := replay.NewReplayer(dir, fixtureModel)
+	if err != nil {
+		t.Fatalf("NewReplayer: %v", err)
+	}
+	replayer.Fallback = recorder
+
+	ctx := context.Background()
+	doc := &nanabushv1.DocumentContent{Title: "Title", Markdown: "Content"}
+	for i := 0; i < 2; i++ {
+		out, err := replayer.TranslateDocumentWithGlossary(ctx, doc, "en", "fr", testGlossary)
+		if err != nil {
+			t.Fatalf("TranslateDocumentWithGlossary #%d: %v", i+1, err)
+		}
+		if out.Title != "Titre pipeline" || out.Markdown != "Contenu pipeline" {
+			t.Fatalf("TranslateDocumentWithGlossary #%d = %q, %q", i+1, out.Title, out.Markdown)
+		}
+	}
+	if backend.glossaryCalls != 1 || backend.calls != 0 {
+		t.Errorf("backend called %d times with the glossary and %d without, want 1 and 0", backend.glossaryCalls, backend.calls)
+	}
+
+	fixture, err := replayer.Lookup(replay.KindMarkdown, "Content", "en", "fr", testGlossary)
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if len(fixture.Glossary) != 1 || fixture.Glossary[0].GetTarget() != "pipeline" {
+		t.Errorf("fixture glossary = %v, want %v", fixture.Glossary, testGlossary)
+	}
+
+	// The same document without the glossary is a different request
+	if _, err := replayer.Lookup(replay.KindMarkdown, "Content", "en", "fr", nil); !errors.Is(err, replay.ErrNoFixture) {
+		t.Errorf("Lookup without the glossary = %v, want %v", err, replay.ErrNoFixture)
+	}
+	if _, err := replayer.TranslateDocument(ctx, doc, "en", "fr"); err != nil {
+		t.Fatalf("TranslateDocument: %v", err)
+	}
+	if backend.calls != 1 {
+		t.Errorf("backend called %d times without the glossary, want 1", backend.calls)
+	}
+}
@@ -0,0 +1,228 @@
+package vllm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dasmlab/nanabush/server/pkg/proto/v1"
+	"github.com/dasmlab/nanabush/server/pkg/service"
+)
+
+// DefaultBaseURL is the in-cluster vLLM service.
+const DefaultBaseURL = "http://vllm.nanabush.svc:8000"
+
+// Config configures the vLLM backend.
+type Config struct {
+	// BaseURL of the OpenAI-compatible vLLM server (e.g. http://vllm.nanabush.svc:8000).
+	BaseURL string
+
+	// Model to request; empty uses the first model the server reports.
+	Model string
+
+	// Temperature for generation. Translation wants near-deterministic output.
+	Temperature float64
+
+	// MaxTokens caps generated tokens per request (0 lets the server decide).
+	MaxTokens int
+
+	// HTTPClient is used for requests; nil uses a client with a 10 minute timeout.
+	HTTPClient *http.Client
+}
+
+// Backend translates through a vLLM server's OpenAI-compatible chat API.
+type Backend struct {
+	cfg    Config
+	client *http.Client
+}
+
+var (
+	_ service.TranslatorBackend  = (*Backend)(nil)
+	_ service.ModelLister        = (*Backend)(nil)
+	_ service.GlossaryTranslator = (*Backend)(nil)
+)
+
+// New creates a vLLM backend.
+func New(cfg Config) *Backend {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultBaseURL
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Minute}
+	}
+	return &Backend{cfg: cfg, client: client}
+}
+
+// Name identifies the backend for throughput tracking and capabilities.
+func (b *Backend) Name() string {
+	if b.cfg.Model != "" {
+		return "vllm/" + b.cfg.Model
+	}
+	return "vllm"
+}
+
+// TranslateTitle translates a page title.
+func (b *Backend) TranslateTitle(ctx context.Context, title, sourceLang, targetLang string) (string, error) {
+	return b.TranslateTitleWithGlossary(ctx, title, sourceLang, targetLang, nil)
+}
+
+// TranslateTitleWithGlossary translates a page title using the given term renderings.
+func (b *Backend) TranslateTitleWithGlossary(ctx context.Context, title, sourceLang, targetLang string, glossary []*nanabushv1.GlossaryTerm) (string, error) {
+	out, err := b.translate(ctx, KindTitle, title, sourceLang, targetLang, glossary)
+	if err != nil {
+		return "", err
+	}
+	// Titles are one line; drop anything the model added after it
+	if i := strings.IndexByte(out, '\n'); i >= 0 {
+		out = out[:i]
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// TranslateDocument translates a document's title and markdown.
+func (b *Backend) TranslateDocument(ctx context.Context, doc *nanabushv1.DocumentContent, sourceLang, targetLang string) (*nanabushv1.DocumentContent, error) {
+	return b.TranslateDocumentWithGlossary(ctx, doc, sourceLang, targetLang, nil)
+}
+
+// TranslateDocumentWithGlossary translates a document's title and markdown
+// using the given term renderings.
+func (b *Backend) TranslateDocumentWithGlossary(ctx context.Context, doc *nanabushv1.DocumentContent, sourceLang, targetLang string, glossary []*nanabushv1.GlossaryTerm) (*nanabushv1.DocumentContent, error) {
+	translated := &nanabushv1.DocumentContent{
+		Slug:     doc.Slug,
+		Metadata: doc.Metadata,
+	}
+	if doc.Title != "" {
+		title, err := b.TranslateTitleWithGlossary(ctx, doc.Title, sourceLang, targetLang, glossary)
+		if err != nil {
+			return nil, fmt.Errorf("title: %w", err)
+		}
+		translated.Title = title
+	}
+	if doc.Markdown != "" {
+		markdown, err := b.translate(ctx, KindMarkdown, doc.Markdown, sourceLang, targetLang, glossary)
+		if err != nil {
+			return nil, fmt.Errorf("markdown: %w", err)
+		}
+		translated.Markdown = markdown
+	}
+	return translated, nil
+}
+
+// CheckHealth calls vLLM's /health endpoint.
+func (b *Backend) CheckHealth(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.cfg.BaseURL+"/health", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("vllm health check failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("vllm health check returned %s", resp.Status)
+	}
+	return nil
+}
+
+// Models lists the models served by vLLM.
+func (b *Backend) Models(ctx context.Context) ([]service.ModelInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.cfg.BaseURL+"/v1/models", nil)
+	if err != nil {
+		return nil, err
+	}
+	var body struct {
+		Data []struct {
+			ID          string `json:"id"`
+			MaxModelLen int    `json:"max_model_len"`
+		} `json:"data"`
+	}
+	if err := b.do(req, &body); err != nil {
+		return nil, fmt.Errorf("failed to list vllm models: %w", err)
+	}
+	models := make([]service.ModelInfo, 0, len(body.Data))
+	for _, m := range body.Data {
+		models = append(models, service.ModelInfo{Name: m.ID, ContextWindow: m.MaxModelLen})
+	}
+	return models, nil
+}
+
+// translate sends one chat completion request.
+func (b *Backend) translate(ctx context.Context, kind ContentKind, content, sourceLang, targetLang string, glossary []*nanabushv1.GlossaryTerm) (string, error) {
+	prompt, err := BuildPrompt(kind, content, sourceLang, targetLang, glossary)
+	if err != nil {
+		return "", err
+	}
+
+	model := b.cfg.Model
+	if model == "" {
+		models, err := b.Models(ctx)
+		if err != nil {
+			return "", err
+		}
+		if len(models) == 0 {
+			return "", fmt.Errorf("vllm reports no models")
+		}
+		model = models[0].Name
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"model":       model,
+		"messages":    prompt.Messages,
+		"temperature": b.cfg.Temperature,
+		"max_tokens":  maxTokensOrNil(b.cfg.MaxTokens),
+	})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.cfg.BaseURL+"/v1/chat/completions", bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var body struct {
+		Choices []struct {
+			Message      Message `json:"message"`
+			FinishReason string  `json:"finish_reason"`
+		} `json:"choices"`
+	}
+	if err := b.do(req, &body); err != nil {
+		return "", fmt.Errorf("vllm chat completion failed: %w", err)
+	}
+	if len(body.Choices) == 0 {
+		return "", fmt.Errorf("vllm returned no choices")
+	}
+	if body.Choices[0].FinishReason == "length" {
+		return "", fmt.Errorf("vllm output truncated at max_tokens")
+	}
+	return strings.TrimSpace(StripBoundary(body.Choices[0].Message.Content, prompt.Boundary)), nil
+}
+
+// do executes req and decodes a JSON response into out.
+func (b *Backend) do(req *http.Request, out interface{}) error {
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func maxTokensOrNil(n int) interface{} {
+	if n <= 0 {
+		return nil
+	}
+	return n
+}
@@ -0,0 +1,68 @@
+// Package chunk splits documents into chunks for TranslateStream, so the
+// gateway and the Go client cut them the same way.
+package chunk
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"unicode/utf8"
+)
+
+// MinSize is the smallest chunk size: a chunk must be able to hold any UTF-8
+// character, or the reader could never make progress.
+const MinSize = utf8.UTFMax
+
+// Reader splits a document into chunks of at most size bytes, ending them at
+// a line break when one is near the end and never inside a UTF-8 character.
+type Reader struct {
+	r     io.Reader
+	size  int
+	carry []byte // Read but not yet returned
+	eof   bool
+}
+
+// NewReader splits r into chunks of at most size bytes. size must be at
+// least MinSize.
+func NewReader(r io.Reader, size int) *Reader {
+	return &Reader{r: r, size: size}
+}
+
+// Next returns the next chunk, or io.EOF when the document is exhausted.
+func (c *Reader) Next() (string, error) {
+	buf := make([]byte, c.size)
+	n := copy(buf, c.carry)
+	if !c.eof {
+		m, err := io.ReadFull(c.r, buf[n:])
+		switch {
+		case errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF):
+			c.eof = true
+		case err != nil:
+			return "", err
+		}
+		n += m
+	}
+	buf = buf[:n]
+	if len(buf) == 0 {
+		return "", io.EOF
+	}
+
+	cut := len(buf)
+	if !c.eof {
+		if i := bytes.LastIndexByte(buf, '\n'); i >= len(buf)/2 {
+			cut = i + 1
+		} else {
+			// Back up to the start of a character cut in half
+			for i := len(buf) - 1; i >= 0 && i >= len(buf)-utf8.UTFMax; i-- {
+				if utf8.RuneStart(buf[i]) {
+					if !utf8.FullRune(buf[i:]) {
+						cut = i
+					}
+					break
+				}
+			}
+		}
+	}
+	c.carry = append(c.carry[:0], buf[cut:]...)
+	return string(buf[:cut]), nil
+}
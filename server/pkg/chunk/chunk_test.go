@@ -0,0 +1,80 @@
+package chunk
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestReader(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		size int
+		want []string
+	}{
+		{
+			name: "empty document",
+			doc:  "",
+			size: 8,
+			want: nil,
+		},
+		{
+			name: "fits in one chunk",
+			doc:  "hello",
+			size: 8,
+			want: []string{"hello"},
+		},
+		{
+			name: "ends chunks at a line break in the second half",
+			doc:  "abcd\nefgh\nij",
+			size: 8,
+			want: []string{"abcd\n", "efgh\nij"},
+		},
+		{
+			name: "ignores line breaks in the first half",
+			doc:  "a\nbcdefghij",
+			size: 8,
+			want: []string{"a\nbcdefg", "hij"},
+		},
+		{
+			name: "never splits a character",
+			doc:  "abcdefgé",
+			size: 8,
+			want: []string{"abcdefg", "é"},
+		},
+		{
+			name: "smallest chunk holds any character",
+			doc:  "a€😀b",
+			size: MinSize,
+			want: []string{"a€", "😀", "b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewReader(strings.NewReader(tt.doc), tt.size)
+			var got []string
+			for {
+				chunk, err := c.Next()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					t.Fatalf("Next: %v", err)
+				}
+				if len(chunk) == 0 || len(chunk) > tt.size || !utf8.ValidString(chunk) {
+					t.Fatalf("chunk %q: want 1 to %d bytes of valid UTF-8", chunk, tt.size)
+				}
+				if len(got) > len(tt.doc) {
+					t.Fatalf("more chunks than bytes in the document: %q", got)
+				}
+				got = append(got, chunk)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") || len(got) != len(tt.want) {
+				t.Errorf("chunks = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
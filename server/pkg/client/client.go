@@ -0,0 +1,732 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/dasmlab/nanabush/server/pkg/chunk"
+	"github.com/dasmlab/nanabush/server/pkg/proto/v1"
+	"github.com/dasmlab/nanabush/server/pkg/tlsconfig"
+)
+
+// Metadata keys read by the server (see the service package). They are
+// repeated here so clients do not pull in the server implementation.
+const (
+	clientIDMetadataKey       = "nanabush-client-id"
+	priorityMetadataKey       = "nanabush-priority"
+	versionWarningMetadataKey = "nanabush-version-warning"
+)
+
+// QueueDepthMetadataKey is the heartbeat metadata key for the number of jobs
+// the client has waiting to send. The server shares backend slots between
+// clients in proportion to it (see Backpressure).
+const QueueDepthMetadataKey = "queue_depth"
+
+// Defaults for Config.
+const (
+	DefaultMaxRetries        = 3
+	DefaultRetryBackoff      = 500 * time.Millisecond
+	DefaultHeartbeatInterval = 60 * time.Second
+	DefaultChunkSize         = 64 * 1024
+)
+
+// maxSessionBackoff caps the delay between session reconnection attempts.
+const maxSessionBackoff = 30 * time.Second
+
+// Config configures a Client.
+type Config struct {
+	// Address of the server, e.g. "nanabush-grpc-server.nanabush.svc:50051"
+	Address string
+
+	// Identity sent in RegisterClient
+	ClientName    string
+	ClientVersion string
+	Namespace     string
+	Metadata      map[string]string
+
+	// Insecure disables TLS. Otherwise TLS files are used as in tlsconfig.Client
+	// (system roots when CAFile is empty; mTLS when CertFile/KeyFile are set).
+	Insecure bool
+	TLS      tlsconfig.Files
+
+	// MaxRetries is how many times a call failing with a transient error is retried
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry; it doubles on each retry
+	RetryBackoff time.Duration
+
+	// Logger for registration and heartbeat events (nil uses log.Default)
+	Logger *log.Logger
+
+	// HeartbeatMetadata, if set, is called before each heartbeat to report
+	// client state, e.g. QueueDepthMetadataKey
+	HeartbeatMetadata func() map[string]string
+
+	// OnHeartbeat, if set, is called with every heartbeat response (or
+	// directives pushed on the session) so callers can react to server
+	// directives (drain, backpressure) as they arrive
+	OnHeartbeat func(*nanabushv1.HeartbeatResponse)
+
+	// OnJobCompleted, if set, is called when the server announces that one of
+	// this client's jobs finished. Only sessions deliver these.
+	OnJobCompleted func(*nanabushv1.JobCompleted)
+
+	// DisableSession polls the unary Heartbeat RPC instead of holding a
+	// Session stream open. Servers without Session fall back automatically.
+	DisableSession bool
+
+	// DialOptions are appended to the client's own options
+	DialOptions []grpc.DialOption
+}
+
+// Client is a connection to a Nanabush server that stays registered: it
+// registers on creation, heartbeats at the server's interval, re-registers
+// when the server asks, and attaches its client_id to every call.
+type Client struct {
+	cfg     Config
+	conn    *grpc.ClientConn
+	service nanabushv1.TranslationServiceClient
+	logger  *log.Logger
+
+	mu               sync.RWMutex
+	clientID         string
+	interval         time.Duration
+	drain            bool
+	minClientVersion string
+	versionWarning   string
+	backpressure     *nanabushv1.Backpressure
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// New dials the server, registers and starts heartbeating.
+func New(ctx context.Context, cfg Config) (*Client, error) {
+	c, err := Dial(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Register(ctx); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// Dial connects to the server without registering, for one-off calls. Calls
+// carry no client_id until Register is called.
+func Dial(cfg Config) (*Client, error) {
+	if cfg.Address == "" {
+		return nil, errors.New("address is required")
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	} else if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = DefaultMaxRetries
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = DefaultRetryBackoff
+	}
+	logger := cfg.Logger
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	c := &Client{cfg: cfg, logger: logger}
+
+	creds := insecure.NewCredentials()
+	if !cfg.Insecure {
+		tlsConfig, err := tlsconfig.Client(cfg.TLS)
+		if err != nil {
+			return nil, err
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	}
+	opts := append([]grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{Time: 30 * time.Second, Timeout: 10 * time.Second, PermitWithoutStream: true}),
+		grpc.WithUnaryInterceptor(c.unaryInterceptor),
+		grpc.WithStreamInterceptor(c.streamInterceptor),
+	}, cfg.DialOptions...)
+
+	conn, err := grpc.NewClient(cfg.Address, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create connection to %s: %w", cfg.Address, err)
+	}
+	c.conn = conn
+	c.service = nanabushv1.NewTranslationServiceClient(conn)
+	return c, nil
+}
+
+// Register registers the client and starts heartbeating until Close.
+func (c *Client) Register(ctx context.Context) error {
+	if c.cfg.ClientName == "" {
+		return errors.New("client name is required")
+	}
+	if c.cancel != nil {
+		return errors.New("client is already registered")
+	}
+	if err := c.register(ctx); err != nil {
+		return err
+	}
+
+	loopCtx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.done = make(chan struct{})
+	go c.heartbeatLoop(loopCtx)
+	return nil
+}
+
+// Close stops heartbeating and closes the connection.
+func (c *Client) Close() error {
+	if c.cancel != nil {
+		c.cancel()
+		<-c.done
+	}
+	return c.conn.Close()
+}
+
+// ClientID returns the ID assigned at the last registration.
+func (c *Client) ClientID() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.clientID
+}
+
+// Conn returns the underlying connection, e.g. for the health service.
+func (c *Client) Conn() *grpc.ClientConn {
+	return c.conn
+}
+
+// Service returns the raw generated stub. Calls made through it carry the
+// client_id but are not retried.
+func (c *Client) Service() nanabushv1.TranslationServiceClient {
+	return c.service
+}
+
+// register calls RegisterClient (with retries) and stores the assigned ID.
+func (c *Client) register(ctx context.Context) error {
+	var resp *nanabushv1.RegisterClientResponse
+	var header metadata.MD
+	err := c.retry(ctx, func(ctx context.Context) error {
+		var err error
+		resp, err = c.service.RegisterClient(ctx, &nanabushv1.RegisterClientRequest{
+			ClientName:    c.cfg.ClientName,
+			ClientVersion: c.cfg.ClientVersion,
+			Namespace:     c.cfg.Namespace,
+			Metadata:      c.cfg.Metadata,
+			RegisteredAt:  timestamppb.Now(),
+		}, grpc.Header(&header))
+		return err
+	})
+	if status.Code(err) == codes.FailedPrecondition {
+		return fmt.Errorf("%s refused client version %q: %w", c.cfg.Address, c.cfg.ClientVersion, err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to register with %s: %w", c.cfg.Address, err)
+	}
+	if !resp.Success {
+		return fmt.Errorf("registration rejected: %s", resp.Message)
+	}
+
+	c.mu.Lock()
+	c.clientID = resp.ClientId
+	c.interval = intervalOrDefault(resp.HeartbeatIntervalSeconds)
+	c.versionWarning = ""
+	if warnings := header.Get(versionWarningMetadataKey); len(warnings) > 0 {
+		c.versionWarning = warnings[0]
+	}
+	c.mu.Unlock()
+
+	c.logger.Printf("Registered with %s: client_id=%q, heartbeat_interval=%v", c.cfg.Address, resp.ClientId, c.heartbeatInterval())
+	if warning := c.VersionWarning(); warning != "" {
+		c.logger.Printf("WARNING: %s: %s", c.cfg.Address, warning)
+	}
+	return nil
+}
+
+func (c *Client) heartbeatInterval() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.interval
+}
+
+// heartbeatLoop keeps the registration alive until ctx is cancelled, through
+// a Session stream when the server supports it and unary heartbeats otherwise.
+func (c *Client) heartbeatLoop(ctx context.Context) {
+	defer close(c.done)
+
+	if !c.cfg.DisableSession && c.sessionLoop(ctx) {
+		return
+	}
+	c.pollLoop(ctx)
+}
+
+// sessionLoop holds a Session open, reconnecting with backoff, until ctx is
+// cancelled (returning true) or the server turns out not to support sessions.
+func (c *Client) sessionLoop(ctx context.Context) bool {
+	backoff := c.cfg.RetryBackoff
+	for {
+		established, err := c.runSession(ctx)
+		if ctx.Err() != nil {
+			return true
+		}
+		switch status.Code(err) {
+		case codes.Unimplemented:
+			c.logger.Printf("Server %s does not support sessions, sending heartbeats instead", c.cfg.Address)
+			return false
+		case codes.NotFound:
+			c.logger.Printf("Server no longer knows client %q, re-registering", c.ClientID())
+			if err := c.register(ctx); err != nil && ctx.Err() == nil {
+				c.logger.Printf("Re-registration failed: %v", err)
+			}
+		default:
+			c.logger.Printf("Session with %s ended: %v", c.cfg.Address, err)
+		}
+
+		if established {
+			backoff = c.cfg.RetryBackoff
+		}
+		select {
+		case <-ctx.Done():
+			return true
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > maxSessionBackoff {
+			backoff = maxSessionBackoff
+		}
+	}
+}
+
+// runSession opens one Session and processes server events until it ends.
+// It reports whether the session was established (directives received).
+func (c *Client) runSession(ctx context.Context) (bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := c.service.Session(ctx)
+	if err != nil {
+		return false, err
+	}
+	err = stream.Send(&nanabushv1.SessionRequest{
+		Message: &nanabushv1.SessionRequest_Hello{Hello: &nanabushv1.SessionHello{
+			ClientId:   c.ClientID(),
+			ClientName: c.cfg.ClientName,
+			Metadata:   c.heartbeatMetadata(),
+		}},
+	})
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, err // io.EOF means the server ended the stream; Recv has the status
+	}
+	if c.cfg.HeartbeatMetadata != nil {
+		go c.reportStatus(ctx, stream)
+	}
+
+	established := false
+	for {
+		event, err := stream.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = errors.New("closed by server")
+			}
+			return established, err
+		}
+		switch e := event.Event.(type) {
+		case *nanabushv1.SessionEvent_Directives:
+			if !established {
+				c.logger.Printf("Session open with %s: client_id=%q", c.cfg.Address, c.ClientID())
+				established = true
+			}
+			c.applyDirectives(e.Directives)
+			if c.cfg.OnHeartbeat != nil {
+				c.cfg.OnHeartbeat(e.Directives)
+			}
+		case *nanabushv1.SessionEvent_JobCompleted:
+			if c.cfg.OnJobCompleted != nil {
+				c.cfg.OnJobCompleted(e.JobCompleted)
+			}
+		}
+	}
+}
+
+// reportStatus sends client-reported state on the session at the heartbeat interval.
+func (c *Client) reportStatus(ctx context.Context, stream nanabushv1.TranslationService_SessionClient) {
+	timer := time.NewTimer(c.heartbeatInterval())
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		err := stream.Send(&nanabushv1.SessionRequest{
+			Message: &nanabushv1.SessionRequest_Status{Status: &nanabushv1.SessionStatus{
+				Metadata: c.heartbeatMetadata(),
+			}},
+		})
+		if err != nil {
+			return
+		}
+		timer.Reset(c.heartbeatInterval())
+	}
+}
+
+func (c *Client) heartbeatMetadata() map[string]string {
+	if c.cfg.HeartbeatMetadata == nil {
+		return nil
+	}
+	return c.cfg.HeartbeatMetadata()
+}
+
+// pollLoop sends unary heartbeats until ctx is cancelled.
+func (c *Client) pollLoop(ctx context.Context) {
+
+	timer := time.NewTimer(c.heartbeatInterval())
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		if err := c.heartbeat(ctx); err != nil && ctx.Err() == nil {
+			c.logger.Printf("Heartbeat failed: %v", err)
+		}
+		timer.Reset(c.heartbeatInterval())
+	}
+}
+
+// heartbeat sends one heartbeat and re-registers when the server asks.
+func (c *Client) heartbeat(ctx context.Context) error {
+	callCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req := &nanabushv1.HeartbeatRequest{
+		ClientId:   c.ClientID(),
+		ClientName: c.cfg.ClientName,
+		SentAt:     timestamppb.Now(),
+	}
+	req.Metadata = c.heartbeatMetadata()
+	resp, err := c.service.Heartbeat(callCtx, req)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			c.logger.Printf("Server no longer knows client %q, re-registering", c.ClientID())
+			return c.register(ctx)
+		}
+		return err
+	}
+
+	c.applyDirectives(resp)
+	if c.cfg.OnHeartbeat != nil {
+		c.cfg.OnHeartbeat(resp)
+	}
+	if resp.ReRegisterRequired {
+		c.logger.Printf("Server requested re-registration: %s", resp.Message)
+		return c.register(ctx)
+	}
+	return nil
+}
+
+// applyDirectives records the server directives from a heartbeat response.
+func (c *Client) applyDirectives(resp *nanabushv1.HeartbeatResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if resp.HeartbeatIntervalSeconds > 0 {
+		c.interval = intervalOrDefault(resp.HeartbeatIntervalSeconds)
+	}
+	if resp.Drain && !c.drain {
+		c.logger.Printf("Server %s is draining: finish in-flight work and send new jobs to another replica", c.cfg.Address)
+	}
+	if resp.MinClientVersion != c.minClientVersion && resp.MinClientVersion != "" {
+		c.logger.Printf("Server %s supports clients from version %s (this client is %q)", c.cfg.Address, resp.MinClientVersion, c.cfg.ClientVersion)
+	}
+	c.drain = resp.Drain
+	c.minClientVersion = resp.MinClientVersion
+	c.backpressure = resp.Backpressure
+}
+
+// Draining reports whether the last heartbeat said the server is draining.
+// Callers should send new jobs to another replica.
+func (c *Client) Draining() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.drain
+}
+
+// MinClientVersion returns the oldest client version the server supports,
+// as of the last heartbeat ("" for no minimum).
+func (c *Client) MinClientVersion() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.minClientVersion
+}
+
+// VersionWarning returns the server's warning about this client's version
+// from the last registration, e.g. that it is deprecated ("" if none).
+func (c *Client) VersionWarning() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.versionWarning
+}
+
+// Backpressure returns the load hint from the last heartbeat, or nil before
+// the first heartbeat. MaxConcurrentJobs is this client's share of backend
+// slots (0 for no limit).
+func (c *Client) Backpressure() *nanabushv1.Backpressure {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.backpressure
+}
+
+func intervalOrDefault(seconds int32) time.Duration {
+	if seconds <= 0 {
+		return DefaultHeartbeatInterval
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// unaryInterceptor attaches the client_id to outgoing calls.
+func (c *Client) unaryInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return invoker(c.withClientID(ctx), method, req, reply, cc, opts...)
+}
+
+// streamInterceptor attaches the client_id to outgoing streams.
+func (c *Client) streamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return streamer(c.withClientID(ctx), desc, cc, method, opts...)
+}
+
+func (c *Client) withClientID(ctx context.Context) context.Context {
+	id := c.ClientID()
+	if id == "" {
+		return ctx
+	}
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get(clientIDMetadataKey)) > 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, clientIDMetadataKey, id)
+}
+
+// retryable reports whether a call failing with err may succeed if repeated.
+func retryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted, codes.Aborted:
+		return true
+	default:
+		return false
+	}
+}
+
+// retry runs call, retrying transient failures with exponential backoff.
+func (c *Client) retry(ctx context.Context, call func(ctx context.Context) error) error {
+	backoff := c.cfg.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := call(ctx)
+		if err == nil || !retryable(err) || attempt >= c.cfg.MaxRetries {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// WithPriority sets the scheduling class ("interactive", "normal" or "bulk") for calls made with ctx.
+func WithPriority(ctx context.Context, priority string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, priorityMetadataKey, priority)
+}
+
+// CheckTitle runs a pre-flight check.
+func (c *Client) CheckTitle(ctx context.Context, req *nanabushv1.TitleCheckRequest) (*nanabushv1.TitleCheckResponse, error) {
+	var resp *nanabushv1.TitleCheckResponse
+	err := c.retry(ctx, func(ctx context.Context) error {
+		var err error
+		resp, err = c.service.CheckTitle(ctx, req)
+		return err
+	})
+	return resp, err
+}
+
+// Translate translates a title or document, retrying transient failures.
+func (c *Client) Translate(ctx context.Context, req *nanabushv1.TranslateRequest) (*nanabushv1.TranslateResponse, error) {
+	var resp *nanabushv1.TranslateResponse
+	err := c.retry(ctx, func(ctx context.Context) error {
+		var err error
+		resp, err = c.service.Translate(ctx, req)
+		return err
+	})
+	return resp, err
+}
+
+// SubmitTranslate queues a translation whose result is POSTed to
+// req.CallbackUrl, and returns once the server has accepted it. Verify and
+// decode callbacks with webhook.Receive.
+func (c *Client) SubmitTranslate(ctx context.Context, req *nanabushv1.TranslateRequest) (*nanabushv1.SubmitTranslateResponse, error) {
+	var resp *nanabushv1.SubmitTranslateResponse
+	err := c.retry(ctx, func(ctx context.Context) error {
+		var err error
+		resp, err = c.service.SubmitTranslate(ctx, req)
+		return err
+	})
+	return resp, err
+}
+
+// TranslateTitle is a shorthand for translating one title.
+func (c *Client) TranslateTitle(ctx context.Context, jobID, title, sourceLang, targetLang string) (*nanabushv1.TranslateResponse, error) {
+	return c.Translate(ctx, &nanabushv1.TranslateRequest{
+		JobId:          jobID,
+		Primitive:      nanabushv1.PrimitiveType_PRIMITIVE_TITLE,
+		Source:         &nanabushv1.TranslateRequest_Title{Title: title},
+		SourceLanguage: sourceLang,
+		TargetLanguage: targetLang,
+		Namespace:      c.cfg.Namespace,
+		RequestedAt:    timestamppb.Now(),
+	})
+}
+
+// TranslateBatch translates many items, retrying transient failures of the whole batch.
+func (c *Client) TranslateBatch(ctx context.Context, req *nanabushv1.TranslateBatchRequest) (*nanabushv1.TranslateBatchResponse, error) {
+	var resp *nanabushv1.TranslateBatchResponse
+	err := c.retry(ctx, func(ctx context.Context) error {
+		var err error
+		resp, err = c.service.TranslateBatch(ctx, req)
+		return err
+	})
+	return resp, err
+}
+
+// TranslateCollection translates the pages of a collection with a shared
+// glossary, retrying transient failures of the whole collection.
+func (c *Client) TranslateCollection(ctx context.Context, req *nanabushv1.TranslateCollectionRequest) (*nanabushv1.TranslateCollectionResponse, error) {
+	var resp *nanabushv1.TranslateCollectionResponse
+	err := c.retry(ctx, func(ctx context.Context) error {
+		var err error
+		resp, err = c.service.TranslateCollection(ctx, req)
+		return err
+	})
+	return resp, err
+}
+
+// GetCapabilities describes the server.
+func (c *Client) GetCapabilities(ctx context.Context) (*nanabushv1.GetCapabilitiesResponse, error) {
+	var resp *nanabushv1.GetCapabilitiesResponse
+	err := c.retry(ctx, func(ctx context.Context) error {
+		var err error
+		resp, err = c.service.GetCapabilities(ctx, &nanabushv1.GetCapabilitiesRequest{})
+		return err
+	})
+	return resp, err
+}
+
+// SubmitFeedback records a review of a completed job.
+func (c *Client) SubmitFeedback(ctx context.Context, req *nanabushv1.SubmitFeedbackRequest) (*nanabushv1.SubmitFeedbackResponse, error) {
+	var resp *nanabushv1.SubmitFeedbackResponse
+	err := c.retry(ctx, func(ctx context.Context) error {
+		var err error
+		resp, err = c.service.SubmitFeedback(ctx, req)
+		return err
+	})
+	return resp, err
+}
+
+// TranslateStream sends content through TranslateStream in chunks of at most
+// chunkSize bytes (DefaultChunkSize if <= 0), split as the gateway splits them,
+// and writes the translated chunks to w. Content is buffered so the whole
+// stream can be retried; a retry only happens if nothing has been written to w yet.
+func (c *Client) TranslateStream(ctx context.Context, jobID string, content []byte, chunkSize int, w io.Writer) error {
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	if chunkSize < chunk.MinSize {
+		return status.Error(codes.InvalidArgument, fmt.Sprintf("chunk size must be at least %d bytes", chunk.MinSize))
+	}
+	written := false
+	return c.retry(ctx, func(ctx context.Context) error {
+		err := c.stream(ctx, jobID, content, chunkSize, w, &written)
+		if err != nil && written {
+			// Output already delivered; retrying would duplicate it
+			return status.Error(codes.Internal, err.Error())
+		}
+		return err
+	})
+}
+
+func (c *Client) stream(ctx context.Context, jobID string, content []byte, chunkSize int, w io.Writer, written *bool) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := c.service.TranslateStream(ctx)
+	if err != nil {
+		return err
+	}
+
+	sendErr := make(chan error, 1)
+	go func() {
+		chunks := chunk.NewReader(bytes.NewReader(content), chunkSize)
+		index := int32(0)
+		for {
+			text, err := chunks.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err == nil {
+				err = stream.Send(&nanabushv1.TranslateChunk{JobId: jobID, ChunkIndex: index, Content: text})
+			}
+			if err != nil {
+				sendErr <- err
+				return
+			}
+			index++
+		}
+		if err := stream.Send(&nanabushv1.TranslateChunk{JobId: jobID, ChunkIndex: index, IsFinal: true}); err != nil {
+			sendErr <- err
+			return
+		}
+		sendErr <- stream.CloseSend()
+	}()
+
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if chunk.ErrorMessage != "" {
+			return status.Error(codes.Internal, chunk.ErrorMessage)
+		}
+		if chunk.IsFinal {
+			continue
+		}
+		if _, err := io.WriteString(w, chunk.Content); err != nil {
+			return status.Error(codes.Canceled, fmt.Sprintf("failed to write output: %v", err))
+		}
+		*written = true
+	}
+
+	if err := <-sendErr; err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
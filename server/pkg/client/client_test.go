@@ -0,0 +1,350 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/dasmlab/nanabush/server/pkg/proto/v1"
+)
+
+var discard = log.New(io.Discard, "", 0)
+
+// fakeServer is an in-process TranslationService whose replies each test
+// sets. Session is unimplemented unless session is set.
+type fakeServer struct {
+	nanabushv1.UnimplementedTranslationServiceServer
+
+	translate func(n int) error                                  // Error for the nth Translate call (from 1)
+	heartbeat func(n int) (*nanabushv1.HeartbeatResponse, error) // Reply to the nth Heartbeat
+	session   func(n int, stream nanabushv1.TranslationService_SessionServer) error
+
+	mu            sync.Mutex
+	registrations int
+	heartbeats    int
+	sessions      int
+	translates    int
+	clientIDs     []string // client_id metadata of each Translate call
+	chunks        []string // Content of each streamed chunk
+}
+
+func (f *fakeServer) RegisterClient(ctx context.Context, req *nanabushv1.RegisterClientRequest) (*nanabushv1.RegisterClientResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.registrations++
+	return &nanabushv1.RegisterClientResponse{
+		Success:                  true,
+		ClientId:                 fmt.Sprintf("client-%d", f.registrations),
+		HeartbeatIntervalSeconds: 1,
+	}, nil
+}
+
+func (f *fakeServer) Heartbeat(ctx context.Context, req *nanabushv1.HeartbeatRequest) (*nanabushv1.HeartbeatResponse, error) {
+	f.mu.Lock()
+	f.heartbeats++
+	n := f.heartbeats
+	f.mu.Unlock()
+	if f.heartbeat == nil {
+		return &nanabushv1.HeartbeatResponse{Success: true}, nil
+	}
+	return f.heartbeat(n)
+}
+
+func (f *fakeServer) Session(stream nanabushv1.TranslationService_SessionServer) error {
+	if f.session == nil {
+		return f.UnimplementedTranslationServiceServer.Session(stream)
+	}
+	f.mu.Lock()
+	f.sessions++
+	n := f.sessions
+	f.mu.Unlock()
+	return f.session(n, stream)
+}
+
+func (f *fakeServer) Translate(ctx context.Context, req *nanabushv1.TranslateRequest) (*nanabushv1.TranslateResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	f.mu.Lock()
+	f.translates++
+	n := f.translates
+	f.clientIDs = append(f.clientIDs, strings.Join(md.Get(clientIDMetadataKey), ","))
+	f.mu.Unlock()
+	if f.translate != nil {
+		if err := f.translate(n); err != nil {
+			return nil, err
+		}
+	}
+	return &nanabushv1.TranslateResponse{JobId: req.JobId, Success: true}, nil
+}
+
+// TranslateStream echoes each chunk back.
+func (f *fakeServer) TranslateStream(stream nanabushv1.TranslationService_TranslateStreamServer) error {
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !chunk.IsFinal {
+			f.mu.Lock()
+			f.chunks = append(f.chunks, chunk.Content)
+			f.mu.Unlock()
+		}
+		if err := stream.Send(chunk); err != nil {
+			return err
+		}
+	}
+}
+
+func (f *fakeServer) counts() (registrations, heartbeats, sessions, translates int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.registrations, f.heartbeats, f.sessions, f.translates
+}
+
+// dialFake serves f in process and returns an unregistered client for it.
+func dialFake(t *testing.T, f *fakeServer, cfg Config) *Client {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	nanabushv1.RegisterTranslationServiceServer(server, f)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	cfg.Address = "passthrough:///bufconn"
+	cfg.Insecure = true
+	cfg.Logger = discard
+	if cfg.ClientName == "" {
+		cfg.ClientName = "test"
+	}
+	if cfg.RetryBackoff == 0 {
+		cfg.RetryBackoff = 10 * time.Millisecond
+	}
+	cfg.DialOptions = append(cfg.DialOptions, grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.DialContext(ctx)
+	}))
+	c, err := Dial(cfg)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+// waitFor polls cond until it holds or the test times out.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  int
+		code      codes.Code
+		wantCalls int
+		wantCode  codes.Code
+	}{
+		{"succeeds first time", 0, codes.OK, 1, codes.OK},
+		{"retries transient failures", 2, codes.Unavailable, 3, codes.OK},
+		{"retries resource exhaustion", 1, codes.ResourceExhausted, 2, codes.OK},
+		{"gives up after MaxRetries", 10, codes.Unavailable, 4, codes.Unavailable},
+		{"does not retry other errors", 10, codes.InvalidArgument, 1, codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &fakeServer{translate: func(n int) error {
+				if n <= tt.failures {
+					return status.Error(tt.code, "try again")
+				}
+				return nil
+			}}
+			c := dialFake(t, f, Config{MaxRetries: 3})
+
+			start := time.Now()
+			_, err := c.TranslateTitle(context.Background(), "job-1", "Title", "en", "fr")
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("TranslateTitle: %v, want code %v", err, tt.wantCode)
+			}
+			if _, _, _, calls := f.counts(); calls != tt.wantCalls {
+				t.Errorf("server saw %d calls, want %d", calls, tt.wantCalls)
+			}
+			// Backoff doubles from RetryBackoff: 10ms, 20ms, 40ms
+			wantWait := time.Duration(0)
+			for i, backoff := 1, 10*time.Millisecond; i < tt.wantCalls; i, backoff = i+1, backoff*2 {
+				wantWait += backoff
+			}
+			if elapsed := time.Since(start); elapsed < wantWait {
+				t.Errorf("returned after %v, want at least %v of backoff", elapsed, wantWait)
+			}
+		})
+	}
+}
+
+func TestClientIDInjected(t *testing.T) {
+	f := &fakeServer{}
+	c := dialFake(t, f, Config{DisableSession: true})
+	ctx := context.Background()
+
+	if _, err := c.TranslateTitle(ctx, "job-1", "Title", "en", "fr"); err != nil {
+		t.Fatalf("TranslateTitle: %v", err)
+	}
+	if err := c.Register(ctx); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if _, err := c.TranslateTitle(ctx, "job-2", "Title", "en", "fr"); err != nil {
+		t.Fatalf("TranslateTitle: %v", err)
+	}
+	// A client_id set by the caller is left alone
+	explicit := metadata.AppendToOutgoingContext(ctx, clientIDMetadataKey, "other")
+	if _, err := c.Service().Translate(explicit, &nanabushv1.TranslateRequest{JobId: "job-3"}); err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+
+	f.mu.Lock()
+	got := f.clientIDs
+	f.mu.Unlock()
+	if want := []string{"", "client-1", "other"}; strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("client_id metadata = %q, want %q", got, want)
+	}
+}
+
+func TestHeartbeatReRegisters(t *testing.T) {
+	tests := []struct {
+		name  string
+		reply func(n int) (*nanabushv1.HeartbeatResponse, error)
+		want  int // Registrations after one heartbeat
+	}{
+		{
+			name: "ok",
+			reply: func(int) (*nanabushv1.HeartbeatResponse, error) {
+				return &nanabushv1.HeartbeatResponse{Success: true}, nil
+			},
+			want: 1,
+		},
+		{
+			name: "client unknown",
+			reply: func(int) (*nanabushv1.HeartbeatResponse, error) {
+				return nil, status.Error(codes.NotFound, "client not registered")
+			},
+			want: 2,
+		},
+		{
+			name: "re-registration required",
+			reply: func(int) (*nanabushv1.HeartbeatResponse, error) {
+				return &nanabushv1.HeartbeatResponse{Success: true, ReRegisterRequired: true, Message: "server restarted"}, nil
+			},
+			want: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &fakeServer{heartbeat: tt.reply}
+			c := dialFake(t, f, Config{})
+			if err := c.register(context.Background()); err != nil {
+				t.Fatalf("register: %v", err)
+			}
+			if err := c.heartbeat(context.Background()); err != nil {
+				t.Fatalf("heartbeat: %v", err)
+			}
+			if registrations, _, _, _ := f.counts(); registrations != tt.want {
+				t.Errorf("%d registrations, want %d", registrations, tt.want)
+			}
+			if want := fmt.Sprintf("client-%d", tt.want); c.ClientID() != want {
+				t.Errorf("ClientID = %q, want %q", c.ClientID(), want)
+			}
+		})
+	}
+}
+
+func TestSessionReRegistersOnNotFound(t *testing.T) {
+	f := &fakeServer{session: func(n int, stream nanabushv1.TranslationService_SessionServer) error {
+		if n == 1 {
+			return status.Error(codes.NotFound, "client not registered")
+		}
+		if err := stream.Send(&nanabushv1.SessionEvent{Event: &nanabushv1.SessionEvent_Directives{
+			Directives: &nanabushv1.HeartbeatResponse{Success: true, Drain: true},
+		}}); err != nil {
+			return err
+		}
+		<-stream.Context().Done()
+		return nil
+	}}
+	c := dialFake(t, f, Config{})
+	if err := c.Register(context.Background()); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	waitFor(t, "the directives of the second session", c.Draining)
+	registrations, heartbeats, sessions, _ := f.counts()
+	if registrations != 2 || sessions != 2 || heartbeats != 0 {
+		t.Errorf("%d registrations, %d sessions, %d heartbeats; want 2, 2, 0", registrations, sessions, heartbeats)
+	}
+	if c.ClientID() != "client-2" {
+		t.Errorf("ClientID = %q, want client-2", c.ClientID())
+	}
+}
+
+func TestSessionFallsBackToHeartbeats(t *testing.T) {
+	f := &fakeServer{heartbeat: func(int) (*nanabushv1.HeartbeatResponse, error) {
+		return &nanabushv1.HeartbeatResponse{Success: true, Drain: true}, nil
+	}}
+	c := dialFake(t, f, Config{})
+	if err := c.Register(context.Background()); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	// The first heartbeat is sent after the server's one-second interval
+	waitFor(t, "a heartbeat", c.Draining)
+	if _, heartbeats, sessions, _ := f.counts(); heartbeats == 0 || sessions != 0 {
+		t.Errorf("%d heartbeats, %d sessions; want heartbeats and no sessions", heartbeats, sessions)
+	}
+}
+
+func TestTranslateStream(t *testing.T) {
+	f := &fakeServer{}
+	c := dialFake(t, f, Config{})
+	content := "Déjà vu: naïve café\nüber 😀 straße\n"
+
+	var out strings.Builder
+	if err := c.TranslateStream(context.Background(), "job-1", []byte(content), 5, &out); err != nil {
+		t.Fatalf("TranslateStream: %v", err)
+	}
+	if out.String() != content {
+		t.Errorf("output = %q, want %q", out.String(), content)
+	}
+	f.mu.Lock()
+	chunks := f.chunks
+	f.mu.Unlock()
+	for _, chunk := range chunks {
+		if len(chunk) == 0 || len(chunk) > 5 || !utf8.ValidString(chunk) {
+			t.Errorf("chunk %q: want 1 to 5 bytes of valid UTF-8", chunk)
+		}
+	}
+
+	err := c.TranslateStream(context.Background(), "job-2", []byte(content), 2, &out)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("TranslateStream with 2-byte chunks: %v, want InvalidArgument", err)
+	}
+}
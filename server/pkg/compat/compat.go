@@ -0,0 +1,301 @@
+package compat
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a parsed semantic version. Build metadata is ignored.
+type Version struct {
+	Major, Minor, Patch int
+	Prerelease          string
+
+	// parts is how many of major.minor.patch were given, so "1.2" can match
+	// every 1.2.x in a range.
+	parts int
+}
+
+// Parse parses a semantic version such as "v1.2.3", "1.2.3-rc.1" or "1.2".
+// Missing minor and patch numbers are zero.
+func Parse(s string) (Version, error) {
+	v := Version{}
+	rest := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexByte(rest, '+'); i >= 0 {
+		rest = rest[:i]
+	}
+	if i := strings.IndexByte(rest, '-'); i >= 0 {
+		v.Prerelease = rest[i+1:]
+		rest = rest[:i]
+		if v.Prerelease == "" {
+			return Version{}, fmt.Errorf("invalid version %q: empty pre-release", s)
+		}
+	}
+
+	fields := strings.Split(rest, ".")
+	if len(fields) > 3 || rest == "" {
+		return Version{}, fmt.Errorf("invalid version %q", s)
+	}
+	numbers := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return Version{}, fmt.Errorf("invalid version %q", s)
+		}
+		*numbers[i] = n
+	}
+	v.parts = len(fields)
+	return v, nil
+}
+
+// String formats the version as "1.2.3" or "1.2.3-rc.1".
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1 as v is older than, equal to or newer than o,
+// with pre-releases older than their release as in semver.
+func (v Version) Compare(o Version) int {
+	for _, d := range []int{v.Major - o.Major, v.Minor - o.Minor, v.Patch - o.Patch} {
+		if d != 0 {
+			return sign(d)
+		}
+	}
+	return comparePrerelease(v.Prerelease, o.Prerelease)
+}
+
+func comparePrerelease(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				return sign(an - bn)
+			}
+		case aErr == nil:
+			return -1 // Numeric identifiers sort before alphanumeric ones
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return sign(len(as) - len(bs))
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+// Range is a set of versions: space-separated comparators that must all
+// match, e.g. ">=1.0.0 <1.2.0". A bare version matches exactly, or every
+// version under it when minor or patch is left out ("1.2" is 1.2.x).
+type Range struct {
+	text        string
+	comparators []comparator
+}
+
+type comparator struct {
+	op string
+	v  Version
+}
+
+// ParseRange parses a range such as "<1.0.0", ">=1.3.0 <1.3.4" or "2.0".
+func ParseRange(s string) (Range, error) {
+	r := Range{text: strings.TrimSpace(s)}
+	for _, field := range strings.Fields(s) {
+		op := ""
+		for _, candidate := range []string{">=", "<=", ">", "<", "="} {
+			if strings.HasPrefix(field, candidate) {
+				op = candidate
+				break
+			}
+		}
+		v, err := Parse(strings.TrimPrefix(field, op))
+		if err != nil {
+			return Range{}, fmt.Errorf("invalid range %q: %w", s, err)
+		}
+		r.comparators = append(r.comparators, comparator{op: op, v: v})
+	}
+	if len(r.comparators) == 0 {
+		return Range{}, fmt.Errorf("empty range")
+	}
+	return r, nil
+}
+
+// Contains reports whether v is in the range.
+func (r Range) Contains(v Version) bool {
+	for _, c := range r.comparators {
+		if !c.matches(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the range as it was written.
+func (r Range) String() string {
+	return r.text
+}
+
+func (c comparator) matches(v Version) bool {
+	switch c.op {
+	case ">=":
+		return v.Compare(c.v) >= 0
+	case "<=":
+		return v.Compare(c.v) <= 0
+	case ">":
+		return v.Compare(c.v) > 0
+	case "<":
+		return v.Compare(c.v) < 0
+	}
+	// Bare or "=": exact, or a prefix match on the parts that were given
+	if c.v.parts == 3 || c.v.Prerelease != "" {
+		return v.Compare(c.v) == 0
+	}
+	if v.Major != c.v.Major {
+		return false
+	}
+	return c.v.parts < 2 || v.Minor == c.v.Minor
+}
+
+// Status is the outcome of checking a client version against a Policy.
+type Status int
+
+const (
+	// Supported versions register normally.
+	Supported Status = iota
+	// Unknown versions are not semantic versions; they register with a warning
+	// because the policy cannot be applied. Under a minimum or blocked versions
+	// they are Blocked unless the policy allows unversioned clients.
+	Unknown
+	// Deprecated versions register with a warning.
+	Deprecated
+	// Blocked versions are refused.
+	Blocked
+)
+
+func (s Status) String() string {
+	switch s {
+	case Supported:
+		return "supported"
+	case Unknown:
+		return "unknown"
+	case Deprecated:
+		return "deprecated"
+	case Blocked:
+		return "blocked"
+	}
+	return fmt.Sprintf("Status(%d)", int(s))
+}
+
+// Result is a policy decision with the reason to give the client.
+type Result struct {
+	Status Status
+	Reason string // Empty for Supported
+}
+
+// Policy decides which client versions may register.
+type Policy struct {
+	// Minimum is the oldest version that may register (nil for no minimum)
+	Minimum *Version
+	// Deprecated versions may register but are warned to upgrade
+	Deprecated []Range
+	// Blocked versions are refused, e.g. releases with a known bad bug
+	Blocked []Range
+	// AllowUnversioned lets clients whose version is not a semantic version
+	// register despite a minimum or blocked versions
+	AllowUnversioned bool
+}
+
+// NewPolicy builds a policy from a minimum version (empty for none) and
+// deprecated and blocked range expressions.
+func NewPolicy(minimum string, deprecated, blocked []string) (*Policy, error) {
+	p := &Policy{}
+	if minimum != "" {
+		v, err := Parse(minimum)
+		if err != nil {
+			return nil, fmt.Errorf("minimum: %w", err)
+		}
+		p.Minimum = &v
+	}
+	for _, s := range deprecated {
+		r, err := ParseRange(s)
+		if err != nil {
+			return nil, fmt.Errorf("deprecated: %w", err)
+		}
+		p.Deprecated = append(p.Deprecated, r)
+	}
+	for _, s := range blocked {
+		r, err := ParseRange(s)
+		if err != nil {
+			return nil, fmt.Errorf("blocked: %w", err)
+		}
+		p.Blocked = append(p.Blocked, r)
+	}
+	return p, nil
+}
+
+// Empty reports whether the policy allows every version.
+func (p *Policy) Empty() bool {
+	return p == nil || (p.Minimum == nil && len(p.Deprecated) == 0 && len(p.Blocked) == 0)
+}
+
+// MinimumString returns the minimum version, or "" when there is none.
+func (p *Policy) MinimumString() string {
+	if p == nil || p.Minimum == nil {
+		return ""
+	}
+	return p.Minimum.String()
+}
+
+// Check decides whether a client version may register. A nil or empty
+// policy supports every version.
+func (p *Policy) Check(version string) Result {
+	if p.Empty() {
+		return Result{Status: Supported}
+	}
+	v, err := Parse(version)
+	if err != nil {
+		if (p.Minimum != nil || len(p.Blocked) > 0) && !p.AllowUnversioned {
+			return Result{Status: Blocked, Reason: fmt.Sprintf("client version %q is not a semantic version, so the minimum and blocked versions cannot be checked; send a version such as 1.2.3", version)}
+		}
+		return Result{Status: Unknown, Reason: fmt.Sprintf("client version %q is not a semantic version; compatibility cannot be checked", version)}
+	}
+	if p.Minimum != nil && v.Compare(*p.Minimum) < 0 {
+		return Result{Status: Blocked, Reason: fmt.Sprintf("client version %s is older than the minimum supported version %s, upgrade the client", v, p.Minimum)}
+	}
+	for _, r := range p.Blocked {
+		if r.Contains(v) {
+			return Result{Status: Blocked, Reason: fmt.Sprintf("client version %s is blocked (%s), upgrade the client", v, r)}
+		}
+	}
+	for _, r := range p.Deprecated {
+		if r.Contains(v) {
+			return Result{Status: Deprecated, Reason: fmt.Sprintf("client version %s is deprecated (%s) and will stop being supported, upgrade the client", v, r)}
+		}
+	}
+	return Result{Status: Supported}
+}
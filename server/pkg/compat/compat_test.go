@@ -0,0 +1,149 @@
+package compat
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "1.2.3", want: "1.2.3"},
+		{in: "v1.2.3", want: "1.2.3"},
+		{in: " 1.2.3 ", want: "1.2.3"},
+		{in: "1.2", want: "1.2.0"},
+		{in: "1", want: "1.0.0"},
+		{in: "1.2.3-rc.1", want: "1.2.3-rc.1"},
+		{in: "1.2.3+build.5", want: "1.2.3"},
+		{in: "1.2.3-rc.1+build.5", want: "1.2.3-rc.1"},
+		{in: "", wantErr: true},
+		{in: "dev", wantErr: true},
+		{in: "v", wantErr: true},
+		{in: "1.2.3.4", wantErr: true},
+		{in: "1.-2.3", wantErr: true},
+		{in: "1.2.x", wantErr: true},
+		{in: "1.2.3-", wantErr: true},
+	}
+	for _, tt := range tests {
+		v, err := Parse(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Parse(%q) = %v, want an error", tt.in, v)
+			}
+			continue
+		}
+		if err != nil || v.String() != tt.want {
+			t.Errorf("Parse(%q) = %v, %v; want %s", tt.in, v, err, tt.want)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	// Each version is older than the next, as in the semver spec's example
+	ordered := []string{
+		"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-beta.2",
+		"1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1", "1.1.0", "1.10.0", "2.0.0",
+	}
+	for i := range ordered {
+		for j := range ordered {
+			a, b := mustParse(t, ordered[i]), mustParse(t, ordered[j])
+			want := sign(i - j)
+			if got := a.Compare(b); got != want {
+				t.Errorf("%s.Compare(%s) = %d, want %d", a, b, got, want)
+			}
+		}
+	}
+}
+
+func TestRangeContains(t *testing.T) {
+	tests := []struct {
+		rng string
+		in  []string
+		out []string
+	}{
+		{"<1.0.0", []string{"0.9.9", "1.0.0-rc.1"}, []string{"1.0.0", "1.0.1"}},
+		{">=1.3.0 <1.3.4", []string{"1.3.0", "1.3.3"}, []string{"1.2.9", "1.3.4", "1.3.0-rc.1"}},
+		{">1.2.0", []string{"1.2.1"}, []string{"1.2.0"}},
+		{"<=1.2.0", []string{"1.2.0"}, []string{"1.2.1"}},
+		{"1.2.3", []string{"1.2.3"}, []string{"1.2.4", "1.2.3-rc.1"}},
+		{"=1.2.3", []string{"1.2.3"}, []string{"1.2.4"}},
+		{"1.2", []string{"1.2.0", "1.2.9"}, []string{"1.3.0", "2.2.0"}},
+		{"v2", []string{"2.0.0", "2.9.1"}, []string{"1.9.9", "3.0.0"}},
+		{"1.6.0-rc.1", []string{"1.6.0-rc.1"}, []string{"1.6.0", "1.6.0-rc.2"}},
+	}
+	for _, tt := range tests {
+		r, err := ParseRange(tt.rng)
+		if err != nil {
+			t.Errorf("ParseRange(%q): %v", tt.rng, err)
+			continue
+		}
+		for _, v := range tt.in {
+			if !r.Contains(mustParse(t, v)) {
+				t.Errorf("%q does not contain %s", tt.rng, v)
+			}
+		}
+		for _, v := range tt.out {
+			if r.Contains(mustParse(t, v)) {
+				t.Errorf("%q contains %s", tt.rng, v)
+			}
+		}
+	}
+
+	for _, bad := range []string{"", "   ", ">=", "<1.x", ">=1.0.0 dev"} {
+		if _, err := ParseRange(bad); err == nil {
+			t.Errorf("ParseRange(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	policy, err := NewPolicy("1.0.0", []string{"<1.4.0"}, []string{">=1.5.0 <1.5.2"})
+	if err != nil {
+		t.Fatalf("NewPolicy: %v", err)
+	}
+	deprecatedOnly, err := NewPolicy("", []string{"<1.4.0"}, nil)
+	if err != nil {
+		t.Fatalf("NewPolicy: %v", err)
+	}
+	allowing := *policy
+	allowing.AllowUnversioned = true
+
+	tests := []struct {
+		name       string
+		policy     *Policy
+		version    string
+		want       Status
+		wantReason string
+	}{
+		{"no policy", nil, "dev", Supported, ""},
+		{"supported", policy, "1.4.0", Supported, ""},
+		{"older than the minimum", policy, "0.9.0", Blocked, "older than the minimum"},
+		{"pre-release of the minimum", policy, "1.0.0-rc.1", Blocked, "older than the minimum"},
+		{"blocked range", policy, "v1.5.1", Blocked, "is blocked (>=1.5.0 <1.5.2)"},
+		{"deprecated range", policy, "1.2.0", Deprecated, "is deprecated (<1.4.0)"},
+		{"unversioned under a minimum", policy, "dev", Blocked, "not a semantic version"},
+		{"empty under a minimum", policy, "", Blocked, "not a semantic version"},
+		{"unversioned allowed", &allowing, "dev", Unknown, "cannot be checked"},
+		{"unversioned with only deprecations", deprecatedOnly, "dev", Unknown, "cannot be checked"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.policy.Check(tt.version)
+			if got.Status != tt.want || !strings.Contains(got.Reason, tt.wantReason) || (tt.wantReason == "") != (got.Reason == "") {
+				t.Errorf("Check(%q) = %v %q, want %v with a reason containing %q", tt.version, got.Status, got.Reason, tt.want, tt.wantReason)
+			}
+		})
+	}
+}
+
+func mustParse(t *testing.T, s string) Version {
+	t.Helper()
+	v, err := Parse(s)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", s, err)
+	}
+	return v
+}
@@ -0,0 +1,529 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/dasmlab/nanabush/server/pkg/compat"
+	"github.com/dasmlab/nanabush/server/pkg/jobs"
+	"github.com/dasmlab/nanabush/server/pkg/languages"
+	"github.com/dasmlab/nanabush/server/pkg/quality"
+	"github.com/dasmlab/nanabush/server/pkg/service"
+	"github.com/dasmlab/nanabush/server/pkg/webhook"
+)
+
+// EnvPrefix is prepended to a flag name, upper-cased with dashes turned into
+// underscores, to form its environment variable: -backend-url is
+// NANABUSH_BACKEND_URL.
+const EnvPrefix = "NANABUSH_"
+
+// Config is the server configuration.
+type Config struct {
+	Server    Server    `yaml:"server"`
+	TLS       TLS       `yaml:"tls"`
+	Backend   Backend   `yaml:"backend"`
+	Clients   Clients   `yaml:"clients"`
+	Scheduler Scheduler `yaml:"scheduler"`
+	Limits    Limits    `yaml:"limits"`
+	Languages Languages `yaml:"languages"`
+	Features  Features  `yaml:"features"`
+	Sanitizer Sanitizer `yaml:"sanitizer"`
+	Audit     Audit     `yaml:"audit"`
+	Feedback  Feedback  `yaml:"feedback"`
+	Admin     Admin     `yaml:"admin"`
+	Shutdown  Shutdown  `yaml:"shutdown"`
+	Health    Health    `yaml:"health"`
+	Webhooks  Webhooks  `yaml:"webhooks"`
+}
+
+// Server is the listener configuration.
+type Server struct {
+	Port        int `yaml:"port"`
+	HTTPPort    int `yaml:"http_port"`
+	GatewayPort int `yaml:"gateway_port"`
+}
+
+// TLS configures server certificates, and mTLS when CA is set.
+type TLS struct {
+	Insecure bool   `yaml:"insecure"`
+	Cert     string `yaml:"cert"`
+	Key      string `yaml:"key"`
+	CA       string `yaml:"ca"`
+}
+
+// Backend selects the translation backend.
+type Backend struct {
+	URL          string `yaml:"url"`
+	Model        string `yaml:"model"`
+	FixturesDir  string `yaml:"fixtures_dir"`
+	FixturesMode string `yaml:"fixtures_mode"`
+}
+
+// Clients controls client registrations.
+type Clients struct {
+	HeartbeatInterval  time.Duration `yaml:"heartbeat_interval"`
+	IdleTimeout        time.Duration `yaml:"idle_timeout"`
+	CleanupInterval    time.Duration `yaml:"cleanup_interval"`
+	RegistrationExpiry time.Duration `yaml:"registration_expiry"`
+
+	MinVersion         string   `yaml:"min_version"`
+	DeprecatedVersions []string `yaml:"deprecated_versions"`
+	BlockedVersions    []string `yaml:"blocked_versions"`
+	// AllowUnversioned registers clients without a semantic version despite
+	// MinVersion or BlockedVersions
+	AllowUnversioned bool `yaml:"allow_unversioned"`
+}
+
+// Scheduler configures how backend slots are shared.
+type Scheduler struct {
+	MaxConcurrent     int            `yaml:"max_concurrent"`
+	StarvationTimeout time.Duration  `yaml:"starvation_timeout"`
+	NamespaceWeights  map[string]int `yaml:"namespace_weights"`
+}
+
+// Limits bounds request sizes and memory use.
+type Limits struct {
+	MaxDocumentChars int           `yaml:"max_document_chars"`
+	JobHistory       int           `yaml:"job_history"`
+	JobRetention     time.Duration `yaml:"job_retention"`
+}
+
+// Languages lists the supported language pairs.
+type Languages struct {
+	Pairs string `yaml:"pairs"`
+}
+
+// Features toggles optional processing.
+type Features struct {
+	OutputGuard     bool    `yaml:"output_guard"`
+	QualityScoring  bool    `yaml:"quality_scoring"`
+	ReviewThreshold float64 `yaml:"review_threshold"`
+	PIIScrubbing    bool    `yaml:"pii_scrubbing"`
+}
+
+// Sanitizer configures PII scrubbing.
+type Sanitizer struct {
+	RulesFile       string   `yaml:"rules_file"`
+	InternalDomains []string `yaml:"internal_domains"`
+	HashKey         string   `yaml:"hash_key"`
+}
+
+// Audit configures the audit log.
+type Audit struct {
+	Log string `yaml:"log"`
+}
+
+// Feedback configures the SubmitFeedback dataset.
+type Feedback struct {
+	Dir string `yaml:"dir"`
+}
+
+// Admin configures access to the AdminService.
+type Admin struct {
+	Token     string   `yaml:"token"`
+	ClientCNs []string `yaml:"client_cns"`
+}
+
+// Shutdown configures how the server drains on SIGTERM.
+type Shutdown struct {
+	DrainTimeout   time.Duration `yaml:"drain_timeout"`
+	JobHistoryFile string        `yaml:"job_history_file"`
+}
+
+// Health configures the backend health watcher behind the health statuses.
+type Health struct {
+	CheckInterval    time.Duration `yaml:"check_interval"`
+	CheckTimeout     time.Duration `yaml:"check_timeout"`
+	FailureThreshold int           `yaml:"failure_threshold"`
+	SuccessThreshold int           `yaml:"success_threshold"`
+}
+
+// Webhooks configures job callbacks to callback_url.
+type Webhooks struct {
+	Secret         string        `yaml:"secret"`
+	AllowedHosts   []string      `yaml:"allowed_hosts"`
+	MaxAttempts    int           `yaml:"max_attempts"`
+	InitialBackoff time.Duration `yaml:"initial_backoff"`
+	MaxBackoff     time.Duration `yaml:"max_backoff"`
+	Timeout        time.Duration `yaml:"timeout"`
+	DeadLetterFile string        `yaml:"dead_letter_file"`
+}
+
+// Default returns the configuration used when nothing is set.
+func Default() Config {
+	return Config{
+		Server: Server{Port: 50051, HTTPPort: 8080},
+		TLS:    TLS{Insecure: true},
+		Backend: Backend{
+			FixturesMode: "replay",
+		},
+		Clients: Clients{
+			HeartbeatInterval:  60 * time.Second,
+			IdleTimeout:        15 * time.Minute,
+			CleanupInterval:    5 * time.Minute,
+			RegistrationExpiry: 24 * time.Hour,
+		},
+		Scheduler: Scheduler{
+			MaxConcurrent:     4,
+			StarvationTimeout: 2 * time.Minute,
+		},
+		Limits: Limits{
+			MaxDocumentChars: service.DefaultMaxDocumentChars,
+			JobHistory:       jobs.DefaultCapacity,
+			JobRetention:     jobs.DefaultRetention,
+		},
+		Languages: Languages{Pairs: languages.DefaultPairs},
+		Features: Features{
+			OutputGuard:     true,
+			QualityScoring:  true,
+			ReviewThreshold: quality.DefaultReviewThreshold,
+			PIIScrubbing:    true,
+		},
+		Audit:    Audit{Log: "-"},
+		Shutdown: Shutdown{DrainTimeout: 5 * time.Minute},
+		Health: Health{
+			CheckInterval:    10 * time.Second,
+			CheckTimeout:     5 * time.Second,
+			FailureThreshold: 3,
+			SuccessThreshold: 2,
+		},
+		Webhooks: Webhooks{
+			MaxAttempts:    webhook.DefaultConfig().MaxAttempts,
+			InitialBackoff: webhook.DefaultConfig().InitialBackoff,
+			MaxBackoff:     webhook.DefaultConfig().MaxBackoff,
+			Timeout:        webhook.DefaultConfig().Timeout,
+		},
+	}
+}
+
+// Validate checks the configuration and reports every problem found.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Server.Port > 0 && c.Server.Port < 65536, "server.port: %d is not a valid port", c.Server.Port)
+	check(c.Server.HTTPPort >= 0 && c.Server.HTTPPort < 65536, "server.http_port: %d is not a valid port", c.Server.HTTPPort)
+	check(c.Server.HTTPPort != c.Server.Port, "server.http_port: must differ from port (%d)", c.Server.Port)
+	check(c.Server.GatewayPort >= 0 && c.Server.GatewayPort < 65536, "server.gateway_port: %d is not a valid port", c.Server.GatewayPort)
+	check(c.Server.GatewayPort == 0 || (c.Server.GatewayPort != c.Server.Port && c.Server.GatewayPort != c.Server.HTTPPort),
+		"server.gateway_port: must differ from port and http_port")
+	if !c.TLS.Insecure {
+		check(c.TLS.Cert != "" && c.TLS.Key != "", "tls: cert and key are required unless insecure")
+	}
+	check(len(c.Admin.ClientCNs) == 0 || (!c.TLS.Insecure && c.TLS.CA != ""), "admin.client_cns requires mTLS (tls.insecure=false and tls.ca)")
+
+	switch c.Backend.FixturesMode {
+	case "replay", "record", "replay-or-record":
+	default:
+		check(false, "backend.fixtures_mode: unknown mode %q (want replay, record or replay-or-record)", c.Backend.FixturesMode)
+	}
+
+	check(c.Clients.HeartbeatInterval >= time.Second, "clients.heartbeat_interval: %v is shorter than 1s", c.Clients.HeartbeatInterval)
+	check(c.Clients.IdleTimeout > c.Clients.HeartbeatInterval, "clients.idle_timeout: %v must be longer than heartbeat_interval (%v)", c.Clients.IdleTimeout, c.Clients.HeartbeatInterval)
+	check(c.Clients.CleanupInterval > 0, "clients.cleanup_interval: must be positive")
+	check(c.Clients.RegistrationExpiry >= c.Clients.IdleTimeout, "clients.registration_expiry: %v must not be shorter than idle_timeout (%v)", c.Clients.RegistrationExpiry, c.Clients.IdleTimeout)
+	if _, err := c.VersionPolicy(); err != nil {
+		check(false, "clients: %v", err)
+	}
+
+	check(c.Scheduler.MaxConcurrent > 0, "scheduler.max_concurrent: must be at least 1")
+	check(c.Scheduler.StarvationTimeout >= 0, "scheduler.starvation_timeout: must not be negative")
+	for ns, w := range c.Scheduler.NamespaceWeights {
+		check(ns != "" && w > 0, "scheduler.namespace_weights: invalid weight for namespace %q: %d", ns, w)
+	}
+
+	check(c.Limits.MaxDocumentChars >= 0, "limits.max_document_chars: must not be negative")
+	check(c.Limits.JobHistory > 0, "limits.job_history: must be at least 1")
+	check(c.Limits.JobRetention > 0, "limits.job_retention: must be positive")
+	if _, err := languages.ParsePairs(c.Languages.Pairs); err != nil {
+		check(false, "languages.pairs: %v", err)
+	}
+
+	check(c.Features.ReviewThreshold >= 0 && c.Features.ReviewThreshold <= 1, "features.review_threshold: %v is outside 0..1", c.Features.ReviewThreshold)
+	check(c.Feedback.Dir == "" || c.Features.PIIScrubbing, "feedback.dir requires features.pii_scrubbing: the feedback dataset must be sanitized")
+	check(c.Shutdown.DrainTimeout >= 0, "shutdown.drain_timeout: must not be negative")
+	check(c.Health.CheckInterval > 0, "health.check_interval: must be positive")
+	check(c.Health.CheckTimeout > 0, "health.check_timeout: must be positive")
+	check(c.Health.FailureThreshold > 0, "health.failure_threshold: must be at least 1")
+	check(c.Health.SuccessThreshold > 0, "health.success_threshold: must be at least 1")
+	check(c.Webhooks.MaxAttempts > 0, "webhooks.max_attempts: must be at least 1")
+	check(c.Webhooks.InitialBackoff > 0 && c.Webhooks.InitialBackoff <= c.Webhooks.MaxBackoff,
+		"webhooks.initial_backoff: must be positive and not longer than max_backoff (%v)", c.Webhooks.MaxBackoff)
+	check(c.Webhooks.Timeout > 0, "webhooks.timeout: must be positive")
+	check(c.Webhooks.DeadLetterFile == "" || c.Webhooks.Secret != "", "webhooks.dead_letter_file requires webhooks.secret")
+
+	return errors.Join(errs...)
+}
+
+// VersionPolicy builds the client version policy, or nil when it is empty.
+func (c *Config) VersionPolicy() (*compat.Policy, error) {
+	policy, err := compat.NewPolicy(c.Clients.MinVersion, c.Clients.DeprecatedVersions, c.Clients.BlockedVersions)
+	if err != nil || policy.Empty() {
+		return nil, err
+	}
+	policy.AllowUnversioned = c.Clients.AllowUnversioned
+	return policy, nil
+}
+
+// RestartRequired returns the settings that differ between c and next but
+// only take effect on restart. Client timings and version policy, scheduler
+// settings, the document limit, the review threshold, admin credentials and
+// the drain timeout can change at runtime.
+func (c *Config) RestartRequired(next *Config) []string {
+	fixed := func(cfg *Config) map[string]interface{} {
+		return map[string]interface{}{
+			"server":                    cfg.Server,
+			"tls":                       cfg.TLS,
+			"backend":                   cfg.Backend,
+			"languages":                 cfg.Languages,
+			"limits.job_history":        cfg.Limits.JobHistory,
+			"limits.job_retention":      cfg.Limits.JobRetention,
+			"features.output_guard":     cfg.Features.OutputGuard,
+			"features.quality_scoring":  cfg.Features.QualityScoring,
+			"features.pii_scrubbing":    cfg.Features.PIIScrubbing,
+			"sanitizer":                 cfg.Sanitizer,
+			"audit":                     cfg.Audit,
+			"feedback":                  cfg.Feedback,
+			"admin.enabled":             cfg.Admin.Token != "" || len(cfg.Admin.ClientCNs) > 0,
+			"shutdown.job_history_file": cfg.Shutdown.JobHistoryFile,
+			"health":                    cfg.Health,
+			"webhooks":                  cfg.Webhooks,
+		}
+	}
+	before, after := fixed(c), fixed(next)
+	var changed []string
+	for name, value := range before {
+		if !reflect.DeepEqual(value, after[name]) {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// Loader builds the configuration from, in increasing precedence: defaults,
+// a YAML file, NANABUSH_* environment variables and command-line flags. It
+// can load again (e.g. on SIGHUP) with the same command line.
+type Loader struct {
+	fs    *flag.FlagSet
+	cfg   *Config           // Flag targets
+	path  string            // -config
+	given map[string]string // Flags set on the command line
+}
+
+// NewLoader registers the configuration flags, and -config, on fs.
+func NewLoader(fs *flag.FlagSet) *Loader {
+	l := &Loader{fs: fs, cfg: &Config{}}
+	*l.cfg = Default()
+	c := l.cfg
+
+	fs.StringVar(&l.path, "config", "", "YAML configuration file (settings there are overridden by NANABUSH_* variables and flags)")
+
+	fs.IntVar(&c.Server.Port, "port", c.Server.Port, "gRPC server port")
+	fs.IntVar(&c.Server.HTTPPort, "http-port", c.Server.HTTPPort, "HTTP port for the /healthz and /readyz probes (0 disables)")
+	fs.IntVar(&c.Server.GatewayPort, "gateway-port", c.Server.GatewayPort, "Port for the REST/JSON gateway to TranslationService, with the gRPC TLS settings (0 disables)")
+	fs.BoolVar(&c.TLS.Insecure, "insecure", c.TLS.Insecure, "Run server in insecure mode (no TLS)")
+	fs.StringVar(&c.TLS.Cert, "tls-cert", c.TLS.Cert, "Path to TLS server certificate")
+	fs.StringVar(&c.TLS.Key, "tls-key", c.TLS.Key, "Path to TLS server private key")
+	fs.StringVar(&c.TLS.CA, "tls-ca", c.TLS.CA, "Path to CA certificate for client verification (mTLS)")
+
+	fs.StringVar(&c.Backend.URL, "backend-url", c.Backend.URL, "vLLM OpenAI-compatible base URL (empty for placeholder translations)")
+	fs.StringVar(&c.Backend.Model, "backend-model", c.Backend.Model, "Model to request from the backend (empty uses the first model it serves)")
+	fs.StringVar(&c.Backend.FixturesDir, "fixtures-dir", c.Backend.FixturesDir, "Record or replay backend calls as fixtures in this directory (for offline testing)")
+	fs.StringVar(&c.Backend.FixturesMode, "fixtures-mode", c.Backend.FixturesMode, "Fixture mode: replay (never call the backend), record, or replay-or-record")
+
+	fs.DurationVar(&c.Clients.HeartbeatInterval, "heartbeat-interval", c.Clients.HeartbeatInterval, "Heartbeat interval recommended to clients")
+	fs.DurationVar(&c.Clients.IdleTimeout, "idle-timeout", c.Clients.IdleTimeout, "Remove clients without a heartbeat or session for this long")
+	fs.DurationVar(&c.Clients.CleanupInterval, "cleanup-interval", c.Clients.CleanupInterval, "How often idle clients and expired jobs are removed")
+	fs.DurationVar(&c.Clients.RegistrationExpiry, "registration-expiry", c.Clients.RegistrationExpiry, "How long a registration lasts before the client must re-register")
+	fs.StringVar(&c.Clients.MinVersion, "min-client-version", c.Clients.MinVersion, "Oldest client version allowed to register (semver, announced in heartbeats)")
+	fs.Var((*listValue)(&c.Clients.DeprecatedVersions), "deprecated-client-versions", "Comma-separated semver ranges that register with a deprecation warning, e.g. \"<1.4.0\"")
+	fs.Var((*listValue)(&c.Clients.BlockedVersions), "blocked-client-versions", "Comma-separated semver ranges refused at registration, e.g. \">=1.5.0 <1.5.2\"")
+	fs.BoolVar(&c.Clients.AllowUnversioned, "allow-unversioned-clients", c.Clients.AllowUnversioned, "Register clients whose version is not semver despite -min-client-version or -blocked-client-versions")
+
+	fs.IntVar(&c.Scheduler.MaxConcurrent, "max-concurrent", c.Scheduler.MaxConcurrent, "Maximum concurrent translation jobs sent to the backend")
+	fs.DurationVar(&c.Scheduler.StarvationTimeout, "starvation-timeout", c.Scheduler.StarvationTimeout, "Queue wait after which a job is promoted ahead of all priority classes (0 disables)")
+	fs.Var((*weightsValue)(&c.Scheduler.NamespaceWeights), "namespace-weights", "Fair-share weights per namespace, e.g. \"glooscap=4,batch=1\"")
+
+	fs.IntVar(&c.Limits.MaxDocumentChars, "max-document-chars", c.Limits.MaxDocumentChars, "Largest markdown document accepted by Translate")
+	fs.IntVar(&c.Limits.JobHistory, "job-history", c.Limits.JobHistory, "Completed jobs kept in memory for SubmitFeedback")
+	fs.DurationVar(&c.Limits.JobRetention, "job-retention", c.Limits.JobRetention, "How long completed jobs are kept for SubmitFeedback")
+	fs.StringVar(&c.Languages.Pairs, "language-pairs", c.Languages.Pairs, "Supported language pairs as source:target,target;source:target (BCP 47)")
+
+	fs.BoolVar(&c.Features.OutputGuard, "output-guard", c.Features.OutputGuard, "Reject backend output that does not look like a translation (prompt-injection defense)")
+	fs.BoolVar(&c.Features.QualityScoring, "quality-scoring", c.Features.QualityScoring, "Attach a reference-free quality score to successful translations")
+	fs.Float64Var(&c.Features.ReviewThreshold, "review-threshold", c.Features.ReviewThreshold, "Quality score below which responses are marked needs_review")
+	fs.BoolVar(&c.Features.PIIScrubbing, "pii-scrubbing", c.Features.PIIScrubbing, "Mask PII and secrets before content reaches the backend")
+	fs.StringVar(&c.Sanitizer.RulesFile, "sanitizer-rules", c.Sanitizer.RulesFile, "Path to a JSON file with extra sanitizer rules and internal domains")
+	fs.Var((*listValue)(&c.Sanitizer.InternalDomains), "internal-domains", "Comma-separated hostname suffixes treated as internal (overrides the rules file)")
+	fs.StringVar(&c.Sanitizer.HashKey, "pii-hash-key", c.Sanitizer.HashKey, "HMAC key for the hashes of masked values in audit records (empty uses a random key per process)")
+
+	fs.StringVar(&c.Audit.Log, "audit-log", c.Audit.Log, "Hash-chained audit log of translation calls (file path, \"-\" for stdout, empty disables)")
+	fs.StringVar(&c.Feedback.Dir, "feedback-dir", c.Feedback.Dir, "Directory for the SubmitFeedback retraining dataset (empty disables feedback capture)")
+	fs.StringVar(&c.Admin.Token, "admin-token", c.Admin.Token, "Bearer token for the AdminService (empty disables token access)")
+	fs.Var((*listValue)(&c.Admin.ClientCNs), "admin-client-cns", "Comma-separated mTLS client certificate common names allowed to use the AdminService")
+
+	fs.DurationVar(&c.Shutdown.DrainTimeout, "drain-timeout", c.Shutdown.DrainTimeout, "On SIGTERM, how long to wait for running jobs before interrupting them")
+	fs.StringVar(&c.Shutdown.JobHistoryFile, "job-history-file", c.Shutdown.JobHistoryFile, "File the job history (IDs, languages and interrupted jobs, never content) is saved to on shutdown and restored from on start (empty disables)")
+
+	fs.DurationVar(&c.Health.CheckInterval, "health-check-interval", c.Health.CheckInterval, "How often the backend health is checked")
+	fs.DurationVar(&c.Health.CheckTimeout, "health-check-timeout", c.Health.CheckTimeout, "Timeout of each backend health check")
+	fs.IntVar(&c.Health.FailureThreshold, "health-failure-threshold", c.Health.FailureThreshold, "Failed backend health checks in a row before the server reports NOT_SERVING")
+	fs.IntVar(&c.Health.SuccessThreshold, "health-success-threshold", c.Health.SuccessThreshold, "Successful backend health checks in a row before the server reports SERVING again")
+
+	fs.StringVar(&c.Webhooks.Secret, "webhook-secret", c.Webhooks.Secret, "HMAC secret signing job callbacks (empty disables callback_url and SubmitTranslate)")
+	fs.Var((*listValue)(&c.Webhooks.AllowedHosts), "webhook-allowed-hosts", "Comma-separated callback hosts allowed, e.g. \"glooscap.svc,*.example.com\" (empty allows public addresses only)")
+	fs.IntVar(&c.Webhooks.MaxAttempts, "webhook-max-attempts", c.Webhooks.MaxAttempts, "Delivery attempts before a callback is dead-lettered")
+	fs.DurationVar(&c.Webhooks.InitialBackoff, "webhook-initial-backoff", c.Webhooks.InitialBackoff, "Wait before the first callback retry, doubling per attempt")
+	fs.DurationVar(&c.Webhooks.MaxBackoff, "webhook-max-backoff", c.Webhooks.MaxBackoff, "Longest wait between callback retries")
+	fs.DurationVar(&c.Webhooks.Timeout, "webhook-timeout", c.Webhooks.Timeout, "Timeout of each callback attempt")
+	fs.StringVar(&c.Webhooks.DeadLetterFile, "webhook-dead-letters", c.Webhooks.DeadLetterFile, "JSONL file recording undeliverable callbacks with their payload (empty keeps them in memory only)")
+	return l
+}
+
+// Parse parses the command line and remembers which flags it set.
+func (l *Loader) Parse(args []string) error {
+	if err := l.fs.Parse(args); err != nil {
+		return err
+	}
+	l.given = make(map[string]string)
+	l.fs.Visit(func(f *flag.Flag) {
+		l.given[f.Name] = f.Value.String()
+	})
+	if l.path == "" {
+		l.path = os.Getenv(EnvPrefix + "CONFIG")
+	}
+	return nil
+}
+
+// Path returns the configuration file, or "" when there is none.
+func (l *Loader) Path() string {
+	return l.path
+}
+
+// Load reads the file and environment again, applies the command-line flags
+// on top and validates the result.
+func (l *Loader) Load() (*Config, error) {
+	*l.cfg = Default()
+
+	if l.path != "" {
+		if err := readFile(l.path, l.cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	var errs []error
+	l.fs.VisitAll(func(f *flag.Flag) {
+		if f.Name == "config" {
+			return
+		}
+		name := EnvPrefix + strings.ToUpper(strings.ReplaceAll(f.Name, "-", "_"))
+		if value := os.Getenv(name); value != "" {
+			if err := f.Value.Set(value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %v", name, err))
+			}
+		}
+	})
+	for name, value := range l.given {
+		if err := l.fs.Lookup(name).Value.Set(value); err != nil {
+			errs = append(errs, fmt.Errorf("-%s: %v", name, err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	if err := l.cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	// Every source assigns fresh slices and maps, so the copy shares nothing
+	// the next Load changes
+	cfg := *l.cfg
+	return &cfg, nil
+}
+
+// readFile decodes a YAML file over cfg. Unknown keys are errors so typos
+// do not go unnoticed.
+func readFile(path string, cfg *Config) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer f.Close()
+
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// listValue is a comma-separated flag value. Setting it replaces the list.
+type listValue []string
+
+func (v *listValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return strings.Join(*v, ",")
+}
+
+func (v *listValue) Set(value string) error {
+	*v = nil
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*v = append(*v, item)
+		}
+	}
+	return nil
+}
+
+// weightsValue is a "ns=weight,..." flag value. Setting it replaces the map.
+type weightsValue map[string]int
+
+func (v *weightsValue) String() string {
+	if v == nil || len(*v) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(*v))
+	for ns, w := range *v {
+		pairs = append(pairs, ns+"="+strconv.Itoa(w))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (v *weightsValue) Set(value string) error {
+	weights := make(map[string]int)
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		ns, w, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || ns == "" {
+			return fmt.Errorf("expected namespace=weight, got %q", pair)
+		}
+		weight, err := strconv.Atoi(w)
+		if err != nil || weight <= 0 {
+			return fmt.Errorf("invalid weight for namespace %q: %q", ns, w)
+		}
+		weights[ns] = weight
+	}
+	*v = weights
+	return nil
+}
@@ -0,0 +1,198 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDefaultIsValid(t *testing.T) {
+	cfg := Default()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Default().Validate() = %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		want   string // Substring of the error, "" for valid
+	}{
+		{"gateway on its own port", func(c *Config) { c.Server.GatewayPort = 8081 }, ""},
+		{"invalid port", func(c *Config) { c.Server.Port = 70000 }, "server.port"},
+		{"http port clash", func(c *Config) { c.Server.HTTPPort = c.Server.Port }, "server.http_port: must differ"},
+		{"gateway port clash", func(c *Config) { c.Server.GatewayPort = c.Server.HTTPPort }, "server.gateway_port: must differ"},
+		{"tls without cert", func(c *Config) { c.TLS.Insecure = false }, "tls: cert and key"},
+		{"admin CNs without mTLS", func(c *Config) { c.Admin.ClientCNs = []string{"ops"} }, "admin.client_cns requires mTLS"},
+		{"unknown fixtures mode", func(c *Config) { c.Backend.FixturesMode = "live" }, "backend.fixtures_mode"},
+		{"short heartbeat", func(c *Config) { c.Clients.HeartbeatInterval = time.Millisecond }, "clients.heartbeat_interval"},
+		{"idle timeout below heartbeat", func(c *Config) { c.Clients.IdleTimeout = time.Second }, "clients.idle_timeout"},
+		{"bad version policy", func(c *Config) { c.Clients.MinVersion = "one" }, "clients:"},
+		{"no concurrency", func(c *Config) { c.Scheduler.MaxConcurrent = 0 }, "scheduler.max_concurrent"},
+		{"zero weight", func(c *Config) { c.Scheduler.NamespaceWeights = map[string]int{"a": 0} }, "scheduler.namespace_weights"},
+		{"bad language pairs", func(c *Config) { c.Languages.Pairs = "en" }, "languages.pairs"},
+		{"review threshold above 1", func(c *Config) { c.Features.ReviewThreshold = 1.5 }, "features.review_threshold"},
+		{"feedback without scrubbing", func(c *Config) { c.Feedback.Dir = "/data"; c.Features.PIIScrubbing = false }, "feedback.dir requires"},
+		{"negative drain timeout", func(c *Config) { c.Shutdown.DrainTimeout = -time.Second }, "shutdown.drain_timeout"},
+		{"no health threshold", func(c *Config) { c.Health.FailureThreshold = 0 }, "health.failure_threshold"},
+		{"backoff above max", func(c *Config) { c.Webhooks.InitialBackoff = time.Hour }, "webhooks.initial_backoff"},
+		{"dead letters without secret", func(c *Config) { c.Webhooks.DeadLetterFile = "/data/dead.jsonl" }, "webhooks.dead_letter_file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			tt.modify(&cfg)
+			err := cfg.Validate()
+			switch {
+			case tt.want == "" && err != nil:
+				t.Fatalf("Validate() = %v, want nil", err)
+			case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+				t.Fatalf("Validate() = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	cfg := Default()
+	cfg.Server.Port = 0
+	cfg.Scheduler.MaxConcurrent = 0
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "server.port") || !strings.Contains(err.Error(), "scheduler.max_concurrent") {
+		t.Fatalf("Validate() = %v, want both problems", err)
+	}
+}
+
+func TestRestartRequired(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		want   []string
+	}{
+		{"nothing changed", func(c *Config) {}, nil},
+		{"runtime settings", func(c *Config) {
+			c.Clients.HeartbeatInterval = 2 * time.Minute
+			c.Scheduler.MaxConcurrent = 8
+			c.Limits.MaxDocumentChars = 10
+			c.Features.ReviewThreshold = 0.9
+			c.Shutdown.DrainTimeout = time.Minute
+		}, nil},
+		{"port", func(c *Config) { c.Server.Port = 50052 }, []string{"server"}},
+		{"sorted", func(c *Config) {
+			c.Webhooks.Secret = "s"
+			c.Backend.URL = "http://vllm:8000"
+			c.Features.PIIScrubbing = false
+		}, []string{"backend", "features.pii_scrubbing", "webhooks"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := Default()
+			after := Default()
+			tt.modify(&after)
+			got := before.RestartRequired(&after)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("RestartRequired = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Enabling the AdminService is a restart; rotating its token is not
+	before, after := Default(), Default()
+	after.Admin.Token = "token"
+	if got := before.RestartRequired(&after); strings.Join(got, ",") != "admin.enabled" {
+		t.Fatalf("RestartRequired after enabling admin = %v, want [admin.enabled]", got)
+	}
+	before.Admin.Token = "old"
+	if got := before.RestartRequired(&after); len(got) != 0 {
+		t.Fatalf("RestartRequired after rotating the admin token = %v, want none", got)
+	}
+}
+
+func TestLoaderPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "nanabush.yaml")
+	yaml := "server:\n  port: 6000\nscheduler:\n  max_concurrent: 2\nlimits:\n  max_document_chars: 100\n"
+	if err := os.WriteFile(path, []byte(yaml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvPrefix+"MAX_CONCURRENT", "3")
+	t.Setenv(EnvPrefix+"MAX_DOCUMENT_CHARS", "200")
+	t.Setenv(EnvPrefix+"NAMESPACE_WEIGHTS", "glooscap=4, batch=1")
+
+	l := NewLoader(flag.NewFlagSet("test", flag.ContinueOnError))
+	if err := l.Parse([]string{"-config", path, "-max-document-chars", "300"}); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	cfg, err := l.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Server.Port != 6000 {
+		t.Errorf("port = %d, want 6000 from the file", cfg.Server.Port)
+	}
+	if cfg.Scheduler.MaxConcurrent != 3 {
+		t.Errorf("max_concurrent = %d, want 3 from the environment", cfg.Scheduler.MaxConcurrent)
+	}
+	if cfg.Limits.MaxDocumentChars != 300 {
+		t.Errorf("max_document_chars = %d, want 300 from the flag", cfg.Limits.MaxDocumentChars)
+	}
+	if w := cfg.Scheduler.NamespaceWeights; len(w) != 2 || w["glooscap"] != 4 || w["batch"] != 1 {
+		t.Errorf("namespace_weights = %v", w)
+	}
+	if cfg.Clients.HeartbeatInterval != Default().Clients.HeartbeatInterval {
+		t.Errorf("heartbeat_interval = %v, want the default", cfg.Clients.HeartbeatInterval)
+	}
+
+	// Loading again re-reads the file, and the flag still wins
+	yaml = strings.Replace(yaml, "6000", "6001", 1)
+	if err := os.WriteFile(path, []byte(yaml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	next, err := l.Load()
+	if err != nil {
+		t.Fatalf("second Load: %v", err)
+	}
+	if next.Server.Port != 6001 || next.Limits.MaxDocumentChars != 300 {
+		t.Errorf("after reload: port %d, max_document_chars %d; want 6001, 300", next.Server.Port, next.Limits.MaxDocumentChars)
+	}
+	if cfg.Server.Port != 6000 {
+		t.Errorf("reload changed the earlier config's port to %d", cfg.Server.Port)
+	}
+}
+
+func TestLoaderErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		env  map[string]string
+		want string
+	}{
+		{"unknown key", "server:\n  prot: 1\n", nil, "field prot not found"},
+		{"invalid value", "", map[string]string{EnvPrefix + "MAX_CONCURRENT": "many"}, EnvPrefix + "MAX_CONCURRENT"},
+		{"bad weights", "", map[string]string{EnvPrefix + "NAMESPACE_WEIGHTS": "a=0"}, "invalid weight"},
+		{"fails validation", "scheduler:\n  max_concurrent: 0\n", nil, "invalid configuration"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "nanabush.yaml")
+			if err := os.WriteFile(path, []byte(tt.yaml), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			l := NewLoader(flag.NewFlagSet("test", flag.ContinueOnError))
+			if err := l.Parse([]string{"-config", path}); err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			_, err := l.Load()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Load() = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package estimator
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultWindow is the number of recent jobs kept per backend and language pair.
+	DefaultWindow = 50
+
+	// DefaultTokensPerSecond is assumed until a backend has reported any throughput.
+	DefaultTokensPerSecond = 20.0
+
+	// charsPerToken approximates how many characters make up one model token.
+	charsPerToken = 4
+
+	// minSamples is the number of observations needed before a language pair's
+	// own model is trusted over the backend-wide model.
+	minSamples = 5
+)
+
+// Estimate is a predicted job duration with a confidence band.
+type Estimate struct {
+	Point time.Duration
+	Low   time.Duration
+	High  time.Duration
+
+	// Samples is the number of observations behind the estimate (0 means defaults were used).
+	Samples int
+}
+
+// sample is one completed job.
+type sample struct {
+	tokens  float64
+	seconds float64
+}
+
+// throughput is a rolling window of samples.
+type throughput struct {
+	samples []sample
+	next    int
+}
+
+func (t *throughput) add(s sample, window int) {
+	if len(t.samples) < window {
+		t.samples = append(t.samples, s)
+		return
+	}
+	t.samples[t.next] = s
+	t.next = (t.next + 1) % window
+}
+
+// rate returns the aggregate tokens per second and the standard deviation of
+// per-job rates across the window.
+func (t *throughput) rate() (mean, stddev float64) {
+	var tokens, seconds float64
+	for _, s := range t.samples {
+		tokens += s.tokens
+		seconds += s.seconds
+	}
+	if seconds <= 0 {
+		return 0, 0
+	}
+	mean = tokens / seconds
+
+	var sumSq float64
+	for _, s := range t.samples {
+		if s.seconds <= 0 {
+			continue
+		}
+		d := s.tokens/s.seconds - mean
+		sumSq += d * d
+	}
+	if n := len(t.samples); n > 1 {
+		stddev = math.Sqrt(sumSq / float64(n-1))
+	}
+	return mean, stddev
+}
+
+type pairKey struct {
+	backend string
+	source  string
+	target  string
+}
+
+// Estimator predicts translation time from observed backend throughput.
+// It keeps a rolling tokens-per-second model per backend and language pair,
+// falling back to the backend-wide model for pairs with too few samples.
+//
+// Tokens are always source (input) tokens: Observe records how long a job
+// of that many source tokens took, and Estimate is asked about source tokens,
+// which are all that is known before translating. The output/input length
+// ratio of the pair is therefore part of the measured rate.
+type Estimator struct {
+	window int
+
+	mu       sync.Mutex
+	pairs    map[pairKey]*throughput
+	backends map[string]*throughput
+}
+
+// New creates an Estimator keeping the last window jobs per model.
+func New(window int) *Estimator {
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	return &Estimator{
+		window:   window,
+		pairs:    make(map[pairKey]*throughput),
+		backends: make(map[string]*throughput),
+	}
+}
+
+// EstimateTokens approximates the number of model tokens in chars characters.
+func EstimateTokens(chars int) int {
+	if chars <= 0 {
+		return 1
+	}
+	return (chars + charsPerToken - 1) / charsPerToken
+}
+
+// Observe records a completed backend call that translated tokens source tokens.
+func (e *Estimator) Observe(backend, sourceLang, targetLang string, tokens int, elapsed time.Duration) {
+	if tokens <= 0 || elapsed <= 0 {
+		return
+	}
+	s := sample{tokens: float64(tokens), seconds: elapsed.Seconds()}
+	key := newPairKey(backend, sourceLang, targetLang)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	pair, ok := e.pairs[key]
+	if !ok {
+		pair = &throughput{}
+		e.pairs[key] = pair
+	}
+	pair.add(s, e.window)
+
+	all, ok := e.backends[backend]
+	if !ok {
+		all = &throughput{}
+		e.backends[backend] = all
+	}
+	all.add(s, e.window)
+}
+
+// Estimate predicts how long translating tokens source tokens will take on backend
+// for the given language pair, excluding any queue wait.
+func (e *Estimator) Estimate(backend, sourceLang, targetLang string, tokens int) Estimate {
+	if tokens <= 0 {
+		tokens = 1
+	}
+
+	e.mu.Lock()
+	model := e.pairs[newPairKey(backend, sourceLang, targetLang)]
+	if model == nil || len(model.samples) < minSamples {
+		if all := e.backends[backend]; all != nil {
+			model = all
+		}
+	}
+	var mean, stddev float64
+	samples := 0
+	if model != nil {
+		mean, stddev = model.rate()
+		samples = len(model.samples)
+	}
+	e.mu.Unlock()
+
+	if mean <= 0 {
+		// No history: use the default rate with a wide band.
+		point := float64(tokens) / DefaultTokensPerSecond
+		return Estimate{
+			Point: seconds(point),
+			Low:   seconds(point / 2),
+			High:  seconds(point * 2),
+		}
+	}
+
+	fast := mean + stddev
+	slow := mean - stddev
+	if slow < mean/4 {
+		slow = mean / 4
+	}
+	return Estimate{
+		Point:   seconds(float64(tokens) / mean),
+		Low:     seconds(float64(tokens) / fast),
+		High:    seconds(float64(tokens) / slow),
+		Samples: samples,
+	}
+}
+
+func newPairKey(backend, sourceLang, targetLang string) pairKey {
+	return pairKey{
+		backend: backend,
+		source:  strings.ToLower(sourceLang),
+		target:  strings.ToLower(targetLang),
+	}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
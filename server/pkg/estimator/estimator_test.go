@@ -0,0 +1,129 @@
+package estimator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		chars int
+		want  int
+	}{
+		{-1, 1},
+		{0, 1},
+		{1, 1},
+		{4, 1},
+		{5, 2},
+		{400, 100},
+	}
+	for _, tt := range tests {
+		if got := EstimateTokens(tt.chars); got != tt.want {
+			t.Errorf("EstimateTokens(%d) = %d, want %d", tt.chars, got, tt.want)
+		}
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	type observation struct {
+		source, target string
+		tokens         int
+		elapsed        time.Duration
+	}
+	repeat := func(n int, o observation) []observation {
+		out := make([]observation, n)
+		for i := range out {
+			out[i] = o
+		}
+		return out
+	}
+
+	tests := []struct {
+		name         string
+		observed     []observation
+		source       string
+		target       string
+		tokens       int
+		wantPoint    time.Duration
+		wantSamples  int
+		wantExactish bool // Low == High == Point when every sample has the same rate
+	}{
+		{
+			name:      "no history uses the default rate",
+			source:    "en",
+			target:    "fr",
+			tokens:    40,
+			wantPoint: 2 * time.Second, // 40 / DefaultTokensPerSecond
+		},
+		{
+			name:         "observed source tokens per second",
+			observed:     repeat(minSamples, observation{"en", "fr", 100, time.Second}),
+			source:       "en",
+			target:       "fr",
+			tokens:       250,
+			wantPoint:    2500 * time.Millisecond,
+			wantSamples:  minSamples,
+			wantExactish: true,
+		},
+		{
+			name:        "language tags are case-insensitive",
+			observed:    repeat(minSamples, observation{"EN", "FR", 100, time.Second}),
+			source:      "en",
+			target:      "fr",
+			tokens:      100,
+			wantPoint:   time.Second,
+			wantSamples: minSamples,
+		},
+		{
+			name: "pairs with few samples fall back to the backend",
+			observed: append(
+				repeat(minSamples, observation{"en", "de", 50, time.Second}),
+				observation{"en", "fr", 500, time.Second},
+			),
+			source:      "en",
+			target:      "fr",
+			tokens:      100,
+			wantPoint:   time.Duration(float64(time.Second) * 100 / (750.0 / 6)),
+			wantSamples: minSamples + 1,
+		},
+		{
+			name:        "window keeps only recent jobs",
+			observed:    append(repeat(3, observation{"en", "fr", 10, time.Second}), repeat(DefaultWindow, observation{"en", "fr", 100, time.Second})...),
+			source:      "en",
+			target:      "fr",
+			tokens:      100,
+			wantPoint:   time.Second,
+			wantSamples: DefaultWindow,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := New(0)
+			for _, o := range tt.observed {
+				e.Observe("vllm", o.source, o.target, o.tokens, o.elapsed)
+			}
+			got := e.Estimate("vllm", tt.source, tt.target, tt.tokens)
+			if diff := got.Point - tt.wantPoint; diff < -time.Millisecond || diff > time.Millisecond {
+				t.Errorf("Point = %v, want %v", got.Point, tt.wantPoint)
+			}
+			if got.Samples != tt.wantSamples {
+				t.Errorf("Samples = %d, want %d", got.Samples, tt.wantSamples)
+			}
+			if got.Low > got.Point || got.High < got.Point {
+				t.Errorf("band %v-%v does not contain %v", got.Low, got.High, got.Point)
+			}
+			if tt.wantExactish && (got.Low != got.Point || got.High != got.Point) {
+				t.Errorf("band %v-%v, want exactly %v for identical samples", got.Low, got.High, got.Point)
+			}
+		})
+	}
+}
+
+func TestObserveIgnoresEmptySamples(t *testing.T) {
+	e := New(0)
+	e.Observe("vllm", "en", "fr", 0, time.Second)
+	e.Observe("vllm", "en", "fr", 100, 0)
+	if got := e.Estimate("vllm", "en", "fr", 20); got.Samples != 0 {
+		t.Fatalf("Samples = %d after empty observations, want 0", got.Samples)
+	}
+}
@@ -0,0 +1,326 @@
+package eval
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dasmlab/nanabush/server/pkg/proto/v1"
+	"github.com/dasmlab/nanabush/server/pkg/quality"
+	"github.com/dasmlab/nanabush/server/pkg/service"
+)
+
+// Segment kinds in a corpus.
+const (
+	KindTitle    = "title"
+	KindMarkdown = "markdown"
+)
+
+// Segment is one source/reference pair in an evaluation corpus.
+type Segment struct {
+	ID             string `json:"id"`
+	Kind           string `json:"kind,omitempty"` // "title" or "markdown" (default)
+	SourceLanguage string `json:"source_language"`
+	TargetLanguage string `json:"target_language"`
+	Source         string `json:"source"`
+	Reference      string `json:"reference"`
+}
+
+// LoadCorpus reads a JSON lines corpus of segments.
+func LoadCorpus(path string) ([]Segment, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var segments []Segment
+	ids := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var seg Segment
+		if err := json.Unmarshal(scanner.Bytes(), &seg); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		if seg.ID == "" {
+			seg.ID = fmt.Sprintf("line-%d", line)
+		}
+		if ids[seg.ID] {
+			return nil, fmt.Errorf("%s:%d: duplicate id %q", path, line, seg.ID)
+		}
+		ids[seg.ID] = true
+		if seg.Kind == "" {
+			seg.Kind = KindMarkdown
+		}
+		if seg.Kind != KindTitle && seg.Kind != KindMarkdown {
+			return nil, fmt.Errorf("%s:%d: kind must be %q or %q", path, line, KindTitle, KindMarkdown)
+		}
+		if seg.SourceLanguage == "" || seg.TargetLanguage == "" || seg.Source == "" || seg.Reference == "" {
+			return nil, fmt.Errorf("%s:%d: source_language, target_language, source and reference are required", path, line)
+		}
+		segments = append(segments, seg)
+	}
+	return segments, scanner.Err()
+}
+
+// Scores are the metrics for a set of segments.
+type Scores struct {
+	Segments int     `json:"segments"`
+	Failed   int     `json:"failed"`
+	BLEU     float64 `json:"bleu"`
+	ChrF     float64 `json:"chrf"`
+	// Markup is the mean fraction of source markdown structure preserved (markdown segments only)
+	Markup float64 `json:"markup"`
+}
+
+// SegmentResult is the outcome of one segment.
+type SegmentResult struct {
+	ID     string  `json:"id"`
+	Pair   string  `json:"pair"`
+	Output string  `json:"output,omitempty"`
+	Error  string  `json:"error,omitempty"`
+	ChrF   float64 `json:"chrf"`
+	Markup float64 `json:"markup"`
+}
+
+// Report is the result of an evaluation run. Saved reports are used as baselines.
+type Report struct {
+	Backend   string             `json:"backend"`
+	Corpus    string             `json:"corpus"`
+	StartedAt time.Time          `json:"started_at"`
+	Duration  string             `json:"duration"`
+	Overall   Scores             `json:"overall"`
+	ByPair    map[string]*Scores `json:"by_pair"`
+	Results   []SegmentResult    `json:"results"`
+}
+
+// Run translates every segment with backend and scores the output.
+// Failed segments count as empty output.
+func Run(ctx context.Context, backend service.TranslatorBackend, segments []Segment, concurrency int) *Report {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	report := &Report{
+		StartedAt: time.Now().UTC(),
+		ByPair:    make(map[string]*Scores),
+		Results:   make([]SegmentResult, len(segments)),
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for i := range segments {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			report.Results[i] = translateSegment(ctx, backend, segments[i])
+		}(i)
+	}
+	wg.Wait()
+
+	overall := Stats{}
+	pairStats := make(map[string]*Stats)
+	markupSum := make(map[string]float64)
+	markupCount := make(map[string]int)
+	for i, seg := range segments {
+		result := report.Results[i]
+		pair := result.Pair
+
+		var segStats Stats
+		segStats.Add(result.Output, seg.Reference)
+		overall.Merge(segStats)
+		if pairStats[pair] == nil {
+			pairStats[pair] = &Stats{}
+			report.ByPair[pair] = &Scores{}
+		}
+		pairStats[pair].Merge(segStats)
+
+		scores := report.ByPair[pair]
+		scores.Segments++
+		report.Overall.Segments++
+		if result.Error != "" {
+			scores.Failed++
+			report.Overall.Failed++
+		}
+		if seg.Kind == KindMarkdown {
+			markupSum[pair] += result.Markup
+			markupCount[pair]++
+			markupSum[""] += result.Markup
+			markupCount[""]++
+		}
+	}
+
+	report.Overall.BLEU = overall.BLEU()
+	report.Overall.ChrF = overall.ChrF()
+	report.Overall.Markup = mean(markupSum[""], markupCount[""])
+	for pair, stats := range pairStats {
+		report.ByPair[pair].BLEU = stats.BLEU()
+		report.ByPair[pair].ChrF = stats.ChrF()
+		report.ByPair[pair].Markup = mean(markupSum[pair], markupCount[pair])
+	}
+	report.Duration = time.Since(report.StartedAt).Round(time.Millisecond).String()
+	return report
+}
+
+func translateSegment(ctx context.Context, backend service.TranslatorBackend, seg Segment) SegmentResult {
+	result := SegmentResult{
+		ID:     seg.ID,
+		Pair:   seg.SourceLanguage + "-" + seg.TargetLanguage,
+		Markup: 1,
+	}
+
+	var err error
+	if seg.Kind == KindTitle {
+		result.Output, err = backend.TranslateTitle(ctx, seg.Source, seg.SourceLanguage, seg.TargetLanguage)
+	} else {
+		var doc *nanabushv1.DocumentContent
+		doc, err = backend.TranslateDocument(ctx, &nanabushv1.DocumentContent{Markdown: seg.Source}, seg.SourceLanguage, seg.TargetLanguage)
+		if err == nil && doc != nil {
+			result.Output = doc.Markdown
+		}
+	}
+	if err != nil {
+		result.Error = err.Error()
+		result.Output = ""
+	}
+
+	var stats Stats
+	stats.Add(result.Output, seg.Reference)
+	result.ChrF = stats.ChrF()
+	if seg.Kind == KindMarkdown {
+		result.Markup = quality.MarkupPreserved(seg.Source, result.Output)
+	}
+	return result
+}
+
+// LoadReport reads a saved report.
+func LoadReport(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("invalid report %s: %w", path, err)
+	}
+	return &report, nil
+}
+
+// Save writes the report as indented JSON.
+func (r *Report) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Thresholds are the largest drops from the baseline that still pass.
+type Thresholds struct {
+	// BLEU and ChrF are in points (0-100 scale)
+	BLEU float64
+	ChrF float64
+	// Markup is a fraction (0-1)
+	Markup float64
+}
+
+// Regression is a metric that dropped more than its threshold, or a pair of
+// the baseline missing from the current run.
+type Regression struct {
+	Scope    string // "overall" or a language pair
+	Metric   string // Empty when Missing
+	Baseline float64
+	Current  float64
+	Missing  bool
+}
+
+// String describes the regression.
+func (r Regression) String() string {
+	if r.Missing {
+		return fmt.Sprintf("%s missing from this run", r.Scope)
+	}
+	return fmt.Sprintf("%s %s dropped %.2f -> %.2f", r.Scope, r.Metric, r.Baseline, r.Current)
+}
+
+// Compare returns the metrics in current that dropped more than the thresholds
+// below baseline, and the baseline pairs current has no scores for. Pairs new
+// in current are not compared.
+func Compare(baseline, current *Report, thresholds Thresholds) []Regression {
+	var regressions []Regression
+	check := func(scope string, base, cur *Scores) {
+		drop := func(metric string, base, cur, threshold float64) {
+			if base-cur > threshold {
+				regressions = append(regressions, Regression{Scope: scope, Metric: metric, Baseline: base, Current: cur})
+			}
+		}
+		drop("bleu", base.BLEU, cur.BLEU, thresholds.BLEU)
+		drop("chrf", base.ChrF, cur.ChrF, thresholds.ChrF)
+		drop("markup", base.Markup, cur.Markup, thresholds.Markup)
+	}
+
+	check("overall", &baseline.Overall, &current.Overall)
+	for _, pair := range sortedPairs(baseline) {
+		if cur, ok := current.ByPair[pair]; ok {
+			check(pair, baseline.ByPair[pair], cur)
+		} else {
+			regressions = append(regressions, Regression{Scope: pair, Missing: true})
+		}
+	}
+	return regressions
+}
+
+// WriteSummary prints a table of scores, with deltas against baseline when given.
+func WriteSummary(w io.Writer, current, baseline *Report) {
+	row := func(scope string, cur *Scores, base *Scores) {
+		if base == nil {
+			fmt.Fprintf(w, "%-12s %5d %6d %8.2f %8.2f %8.3f\n", scope, cur.Segments, cur.Failed, cur.BLEU, cur.ChrF, cur.Markup)
+			return
+		}
+		fmt.Fprintf(w, "%-12s %5d %6d %8.2f (%+.2f) %8.2f (%+.2f) %8.3f (%+.3f)\n", scope, cur.Segments, cur.Failed,
+			cur.BLEU, cur.BLEU-base.BLEU, cur.ChrF, cur.ChrF-base.ChrF, cur.Markup, cur.Markup-base.Markup)
+	}
+
+	fmt.Fprintf(w, "%-12s %5s %6s %8s %8s %8s\n", "scope", "segs", "failed", "bleu", "chrf", "markup")
+	var base *Scores
+	if baseline != nil {
+		base = &baseline.Overall
+	}
+	row("overall", &current.Overall, base)
+	for _, pair := range sortedPairs(current) {
+		base = nil
+		if baseline != nil {
+			base = baseline.ByPair[pair]
+		}
+		row(pair, current.ByPair[pair], base)
+	}
+}
+
+func sortedPairs(r *Report) []string {
+	pairs := make([]string, 0, len(r.ByPair))
+	for pair := range r.ByPair {
+		pairs = append(pairs, pair)
+	}
+	sort.Strings(pairs)
+	return pairs
+}
+
+func mean(sum float64, n int) float64 {
+	if n == 0 {
+		return 1
+	}
+	return sum / float64(n)
+}
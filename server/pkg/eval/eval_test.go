@@ -0,0 +1,62 @@
+package eval
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	baseline := &Report{
+		Overall: Scores{BLEU: 40, ChrF: 60, Markup: 1},
+		ByPair: map[string]*Scores{
+			"en-fr": {BLEU: 40, ChrF: 60, Markup: 1},
+			"en-de": {BLEU: 35, ChrF: 58, Markup: 1},
+		},
+	}
+	thresholds := Thresholds{BLEU: 1, ChrF: 1, Markup: 0.02}
+
+	tests := []struct {
+		name    string
+		overall Scores
+		byPair  map[string]*Scores
+		want    []string
+	}{
+		{
+			name:    "within thresholds",
+			overall: Scores{BLEU: 39.5, ChrF: 59.5, Markup: 0.99},
+			byPair: map[string]*Scores{
+				"en-fr": {BLEU: 39.5, ChrF: 59.5, Markup: 0.99},
+				"en-de": {BLEU: 36, ChrF: 58, Markup: 1},
+			},
+		},
+		{
+			name:    "metric dropped",
+			overall: Scores{BLEU: 40, ChrF: 60, Markup: 1},
+			byPair: map[string]*Scores{
+				"en-fr": {BLEU: 38, ChrF: 60, Markup: 0.9},
+				"en-de": {BLEU: 35, ChrF: 58, Markup: 1},
+			},
+			want: []string{"en-fr bleu dropped 40.00 -> 38.00", "en-fr markup dropped 1.00 -> 0.90"},
+		},
+		{
+			name:    "pair missing from the run",
+			overall: Scores{BLEU: 40, ChrF: 60, Markup: 1},
+			byPair: map[string]*Scores{
+				"en-fr": {BLEU: 40, ChrF: 60, Markup: 1},
+				"en-es": {BLEU: 10, ChrF: 20, Markup: 0},
+			},
+			want: []string{"en-de missing from this run"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, regression := range Compare(baseline, &Report{Overall: tt.overall, ByPair: tt.byPair}, thresholds) {
+				got = append(got, regression.String())
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("regressions = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package eval
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Stats accumulates sufficient statistics for corpus-level BLEU and chrF.
+// Corpus scores are computed from summed counts, not averaged segment scores.
+type Stats struct {
+	// BLEU: clipped n-gram matches and hypothesis n-gram totals for n=1..4
+	bleuMatches [4]int
+	bleuTotals  [4]int
+	hypLen      int
+	refLen      int
+
+	// chrF: character n-gram matches and totals for n=1..6
+	chrMatches [6]int
+	chrHyp     [6]int
+	chrRef     [6]int
+}
+
+// Add accumulates one hypothesis/reference segment.
+func (s *Stats) Add(hypothesis, reference string) {
+	hypTokens := tokenize(hypothesis)
+	refTokens := tokenize(reference)
+	s.hypLen += len(hypTokens)
+	s.refLen += len(refTokens)
+	for n := 1; n <= 4; n++ {
+		matches, total := ngramOverlap(hypTokens, refTokens, n)
+		s.bleuMatches[n-1] += matches
+		s.bleuTotals[n-1] += total
+	}
+
+	hypChars := chars(hypothesis)
+	refChars := chars(reference)
+	for n := 1; n <= 6; n++ {
+		matches, hypTotal := ngramOverlap(hypChars, refChars, n)
+		s.chrMatches[n-1] += matches
+		s.chrHyp[n-1] += hypTotal
+		if len(refChars) >= n {
+			s.chrRef[n-1] += len(refChars) - n + 1
+		}
+	}
+}
+
+// Merge adds other's counts into s.
+func (s *Stats) Merge(other Stats) {
+	for i := range s.bleuMatches {
+		s.bleuMatches[i] += other.bleuMatches[i]
+		s.bleuTotals[i] += other.bleuTotals[i]
+	}
+	s.hypLen += other.hypLen
+	s.refLen += other.refLen
+	for i := range s.chrMatches {
+		s.chrMatches[i] += other.chrMatches[i]
+		s.chrHyp[i] += other.chrHyp[i]
+		s.chrRef[i] += other.chrRef[i]
+	}
+}
+
+// BLEU returns BLEU-4 with brevity penalty, 0-100.
+func (s *Stats) BLEU() float64 {
+	if s.hypLen == 0 {
+		return 0
+	}
+	logPrecision := 0.0
+	for n := 0; n < 4; n++ {
+		if s.bleuTotals[n] == 0 || s.bleuMatches[n] == 0 {
+			return 0
+		}
+		logPrecision += math.Log(float64(s.bleuMatches[n]) / float64(s.bleuTotals[n]))
+	}
+	brevity := 1.0
+	if s.hypLen < s.refLen {
+		brevity = math.Exp(1 - float64(s.refLen)/float64(s.hypLen))
+	}
+	return round2(100 * brevity * math.Exp(logPrecision/4))
+}
+
+// ChrF returns chrF2 (character 6-grams, recall weighted twice precision), 0-100.
+func (s *Stats) ChrF() float64 {
+	const beta = 2.0
+	precision, recall := 0.0, 0.0
+	orders := 0
+	for n := 0; n < 6; n++ {
+		if s.chrHyp[n] == 0 || s.chrRef[n] == 0 {
+			continue
+		}
+		precision += float64(s.chrMatches[n]) / float64(s.chrHyp[n])
+		recall += float64(s.chrMatches[n]) / float64(s.chrRef[n])
+		orders++
+	}
+	if orders == 0 {
+		return 0
+	}
+	precision /= float64(orders)
+	recall /= float64(orders)
+	if precision+recall == 0 {
+		return 0
+	}
+	f := (1 + beta*beta) * precision * recall / (beta*beta*precision + recall)
+	return round2(100 * f)
+}
+
+// tokenizeRules are the rules of the 13a tokenizer (mteval-v13a.pl) used
+// by sacreBLEU, applied in order, so scores match sacreBLEU's.
+var tokenizeRules = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	// ASCII punctuation and symbols, except apostrophes, hyphens, periods and commas
+	{regexp.MustCompile("([{|}~\\[\\\\\\]^_` !\"#$%&()*+:;<=>?@/])"), " ${1} "},
+	// Periods and commas, unless between digits
+	{regexp.MustCompile(`([^0-9])([.,])`), "${1} ${2} "},
+	{regexp.MustCompile(`([.,])([^0-9])`), " ${1} ${2}"},
+	// Hyphens after a digit
+	{regexp.MustCompile(`([0-9])(-)`), "${1} ${2} "},
+}
+
+var entities = strings.NewReplacer("&quot;", `"`, "&amp;", "&", "&lt;", "<", "&gt;", ">")
+
+// tokenize splits text into words and punctuation marks with sacreBLEU's
+// 13a tokenizer.
+func tokenize(text string) []string {
+	text = strings.ReplaceAll(text, "<skipped>", "")
+	text = strings.ReplaceAll(text, "-\n", "")
+	text = entities.Replace(strings.ReplaceAll(text, "\n", " "))
+	text = " " + text + " "
+	for _, rule := range tokenizeRules {
+		text = rule.pattern.ReplaceAllString(text, rule.replacement)
+	}
+	return strings.Fields(text)
+}
+
+// chars returns the characters of text with whitespace removed, as chrF does.
+func chars(text string) []string {
+	var out []string
+	for _, r := range text {
+		if !unicode.IsSpace(r) {
+			out = append(out, string(r))
+		}
+	}
+	return out
+}
+
+// ngramOverlap returns the clipped count of hypothesis n-grams found in the
+// reference, and the total number of hypothesis n-grams.
+func ngramOverlap(hyp, ref []string, n int) (int, int) {
+	if len(hyp) < n {
+		return 0, 0
+	}
+	refCounts := make(map[string]int)
+	for i := 0; i+n <= len(ref); i++ {
+		refCounts[strings.Join(ref[i:i+n], "\x00")]++
+	}
+	matches := 0
+	total := 0
+	for i := 0; i+n <= len(hyp); i++ {
+		gram := strings.Join(hyp[i:i+n], "\x00")
+		if refCounts[gram] > 0 {
+			refCounts[gram]--
+			matches++
+		}
+		total++
+	}
+	return matches, total
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
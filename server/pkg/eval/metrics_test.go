@@ -0,0 +1,99 @@
+package eval
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"The dog bit the man.", "The dog bit the man ."},
+		{"It wasn't surprising.", "It wasn't surprising ."},
+		{"Version 1.5, released 2024-01-02, costs $3,000.", "Version 1.5 , released 2024 - 01 - 02 , costs $ 3,000 ."},
+		{"A state-of-the-art (new) tool: see a/b?", "A state-of-the-art ( new ) tool : see a / b ?"},
+		{"Use &lt;b&gt; tags", "Use < b > tags"},
+		{"« Déjà vu »", "« Déjà vu »"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(tokenize(tt.text), " "); got != tt.want {
+			t.Errorf("tokenize(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+// The expected scores are sacreBLEU's corpus BLEU (13a tokenizer, no
+// smoothing) and chrF2 for the same segments.
+func TestMetrics(t *testing.T) {
+	tests := []struct {
+		name     string
+		pairs    [][2]string // Hypothesis, reference
+		wantBLEU float64
+		wantChrF float64
+	}{
+		{
+			name:     "identical",
+			pairs:    [][2]string{{"The dog bit the man.", "The dog bit the man."}},
+			wantBLEU: 100,
+			wantChrF: 100,
+		},
+		{
+			name: "corpus",
+			pairs: [][2]string{
+				{"The dog bit the man.", "The dog bit the man."},
+				{"It wasn't surprising.", "It was not unexpected."},
+				{"The man had just bitten him.", "The man bit him first."},
+			},
+			wantBLEU: 45.07,
+			wantChrF: 50.04,
+		},
+		{
+			name: "no matching 4-grams",
+			pairs: [][2]string{
+				{"Redémarrez le service, puis vérifiez les journaux.", "Redémarrez le service puis consultez les journaux."},
+				{"La version 1.5 est-elle prise en charge ?", "La version 1.5 est prise en charge."},
+			},
+			wantBLEU: 0,
+			wantChrF: 75.92,
+		},
+		{
+			name:     "shorter than 4 words",
+			pairs:    [][2]string{{"the cat", "the cat sat on the mat"}},
+			wantBLEU: 0,
+			wantChrF: 27.25,
+		},
+		{
+			name:     "empty hypothesis",
+			pairs:    [][2]string{{"", "The dog bit the man."}},
+			wantBLEU: 0,
+			wantChrF: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stats Stats
+			for _, pair := range tt.pairs {
+				stats.Add(pair[0], pair[1])
+			}
+			if got := stats.BLEU(); got != tt.wantBLEU {
+				t.Errorf("BLEU = %v, want %v", got, tt.wantBLEU)
+			}
+			if got := stats.ChrF(); got != tt.wantChrF {
+				t.Errorf("ChrF = %v, want %v", got, tt.wantChrF)
+			}
+
+			// Corpus scores come from summed counts, so merging per-segment stats gives the same result
+			var merged Stats
+			for _, pair := range tt.pairs {
+				var segment Stats
+				segment.Add(pair[0], pair[1])
+				merged.Merge(segment)
+			}
+			if merged != stats {
+				t.Errorf("merged stats %+v, want %+v", merged, stats)
+			}
+		})
+	}
+}
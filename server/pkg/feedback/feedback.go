@@ -0,0 +1,233 @@
+package feedback
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SchemaVersion is the dataset version records are written to. It changes
+// whenever Record changes incompatibly, so the retrain pipeline can pin one.
+const SchemaVersion = "v1"
+
+// ManifestFile describes the dataset version directory.
+const ManifestFile = "manifest.json"
+
+// Statuses recorded for a review.
+const (
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+)
+
+// ErrDuplicate is returned by Append when the job already has a record.
+var ErrDuplicate = errors.New("job already has feedback")
+
+// Record is one reviewed translation. All text fields are scrubbed of PII
+// before the record is written.
+type Record struct {
+	SchemaVersion string    `json:"schema_version"`
+	ID            string    `json:"id"`
+	JobID         string    `json:"job_id"`
+	Status        string    `json:"status"`
+	Reviewer      string    `json:"reviewer,omitempty"`
+	ReviewedAt    time.Time `json:"reviewed_at"`
+
+	Namespace      string    `json:"namespace,omitempty"`
+	SourceLanguage string    `json:"source_language"`
+	TargetLanguage string    `json:"target_language"`
+	Model          string    `json:"model,omitempty"`
+	TranslatedAt   time.Time `json:"translated_at"`
+
+	SourceTitle       string `json:"source_title,omitempty"`
+	SourceMarkdown    string `json:"source_markdown,omitempty"`
+	OutputTitle       string `json:"output_title,omitempty"`
+	OutputMarkdown    string `json:"output_markdown,omitempty"`
+	CorrectedTitle    string `json:"corrected_title,omitempty"`
+	CorrectedMarkdown string `json:"corrected_markdown,omitempty"`
+	Comment           string `json:"comment,omitempty"`
+
+	// Masked counts the values replaced by placeholders, per sanitizer rule
+	Masked map[string]int `json:"masked,omitempty"`
+}
+
+// Manifest summarises a dataset version for consumers.
+type Manifest struct {
+	SchemaVersion string         `json:"schema_version"`
+	Records       int            `json:"records"`
+	Files         map[string]int `json:"files"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+// Dataset appends feedback records to <dir>/<SchemaVersion>/feedback-YYYY-MM-DD.jsonl
+// and keeps manifest.json in that directory up to date. Each job is
+// recorded at most once.
+type Dataset struct {
+	mu       sync.Mutex
+	dir      string
+	manifest Manifest
+	reviewed map[string]string // Job ID to feedback ID
+}
+
+// Open opens (creating if needed) the dataset under dir.
+func Open(dir string) (*Dataset, error) {
+	versionDir := filepath.Join(dir, SchemaVersion)
+	if err := os.MkdirAll(versionDir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create feedback dataset directory: %w", err)
+	}
+
+	d := &Dataset{
+		dir: versionDir,
+		manifest: Manifest{
+			SchemaVersion: SchemaVersion,
+			Files:         make(map[string]int),
+		},
+		reviewed: make(map[string]string),
+	}
+
+	// Rebuild counts from the shards on disk rather than trusting an old manifest
+	shards, err := filepath.Glob(filepath.Join(versionDir, "feedback-*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	for _, shard := range shards {
+		n, err := readShard(shard, d.reviewed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read feedback shard %s: %w", shard, err)
+		}
+		d.manifest.Files[filepath.Base(shard)] = n
+		d.manifest.Records += n
+	}
+	return d, nil
+}
+
+// Version returns the dataset version records are written to.
+func (d *Dataset) Version() string {
+	return SchemaVersion
+}
+
+// Dir returns the dataset version directory.
+func (d *Dataset) Dir() string {
+	return d.dir
+}
+
+// Append writes a record, assigning its ID, schema version and review time
+// if unset. It returns ErrDuplicate if the record's job was already reviewed.
+func (d *Dataset) Append(r Record) (Record, error) {
+	r.SchemaVersion = SchemaVersion
+	if r.ID == "" {
+		id, err := newID()
+		if err != nil {
+			return r, err
+		}
+		r.ID = id
+	}
+	if r.ReviewedAt.IsZero() {
+		r.ReviewedAt = time.Now().UTC()
+	}
+
+	line, err := json.Marshal(r)
+	if err != nil {
+		return r, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if id, ok := d.reviewed[r.JobID]; ok && r.JobID != "" {
+		return r, fmt.Errorf("%w: job %q was reviewed in %s", ErrDuplicate, r.JobID, id)
+	}
+
+	shard := "feedback-" + r.ReviewedAt.UTC().Format("2006-01-02") + ".jsonl"
+	f, err := os.OpenFile(filepath.Join(d.dir, shard), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o640)
+	if err != nil {
+		return r, fmt.Errorf("failed to open feedback shard: %w", err)
+	}
+	_, err = f.Write(append(line, '\n'))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return r, fmt.Errorf("failed to write feedback record: %w", err)
+	}
+
+	if r.JobID != "" {
+		d.reviewed[r.JobID] = r.ID
+	}
+	d.manifest.Files[shard]++
+	d.manifest.Records++
+	d.manifest.UpdatedAt = time.Now().UTC()
+	if err := d.writeManifestLocked(); err != nil {
+		return r, err
+	}
+	return r, nil
+}
+
+// Manifest returns a copy of the current manifest.
+func (d *Dataset) Manifest() Manifest {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	m := d.manifest
+	m.Files = make(map[string]int, len(d.manifest.Files))
+	for name, n := range d.manifest.Files {
+		m.Files[name] = n
+	}
+	return m
+}
+
+// writeManifestLocked atomically replaces manifest.json.
+func (d *Dataset) writeManifestLocked() error {
+	data, err := json.MarshalIndent(d.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(d.dir, ManifestFile+".tmp")
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o640); err != nil {
+		return fmt.Errorf("failed to write feedback manifest: %w", err)
+	}
+	return os.Rename(tmp, filepath.Join(d.dir, ManifestFile))
+}
+
+// readShard counts the records in a shard and adds their jobs to reviewed.
+func readShard(path string, reviewed map[string]string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	n := 0
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		n++
+		var r Record
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			return n, fmt.Errorf("record %d: %w", n, err)
+		}
+		if r.JobID != "" {
+			reviewed[r.JobID] = r.ID
+		}
+	}
+	return n, scanner.Err()
+}
+
+func newID() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate feedback id: %w", err)
+	}
+	return "fb-" + hex.EncodeToString(b), nil
+}
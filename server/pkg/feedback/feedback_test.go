@@ -0,0 +1,73 @@
+package feedback
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppend(t *testing.T) {
+	dir := t.TempDir()
+	d, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	reviewedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	record, err := d.Append(Record{JobID: "job-1", Status: StatusApproved, ReviewedAt: reviewedAt})
+	if err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	if !strings.HasPrefix(record.ID, "fb-") || record.SchemaVersion != SchemaVersion {
+		t.Errorf("record %+v, want an fb- ID and schema %s", record, SchemaVersion)
+	}
+	if _, err := d.Append(Record{JobID: "job-2", Status: StatusRejected}); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+
+	manifest := d.Manifest()
+	if manifest.Records != 2 || manifest.Files["feedback-2026-03-01.jsonl"] != 1 {
+		t.Errorf("manifest %+v, want two records, one in the 2026-03-01 shard", manifest)
+	}
+	if _, err := os.Stat(filepath.Join(dir, SchemaVersion, ManifestFile)); err != nil {
+		t.Errorf("manifest not written: %v", err)
+	}
+
+	if _, err := d.Append(Record{JobID: "job-1", Status: StatusRejected}); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("second review of job-1: err = %v, want ErrDuplicate", err)
+	}
+	if d.Manifest().Records != 2 {
+		t.Errorf("duplicate was counted: %+v", d.Manifest())
+	}
+}
+
+func TestOpenExisting(t *testing.T) {
+	dir := t.TempDir()
+	d, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	for _, jobID := range []string{"job-1", "job-2", "job-3"} {
+		if _, err := d.Append(Record{JobID: jobID, Status: StatusApproved}); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+
+	// Counts and reviewed jobs are rebuilt from the shards
+	reopened, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if got := reopened.Manifest().Records; got != 3 {
+		t.Errorf("reopened dataset has %d records, want 3", got)
+	}
+	if _, err := reopened.Append(Record{JobID: "job-2", Status: StatusRejected}); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("review of job-2 after reopening: err = %v, want ErrDuplicate", err)
+	}
+	if _, err := reopened.Append(Record{JobID: "job-4", Status: StatusRejected}); err != nil {
+		t.Errorf("Append: %v", err)
+	}
+}
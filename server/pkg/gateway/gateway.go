@@ -0,0 +1,83 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/dasmlab/nanabush/server/pkg/proto/v1"
+	"github.com/dasmlab/nanabush/server/pkg/service"
+)
+
+// MaxRequestBytes is the largest request body accepted, the same as the gRPC
+// server's default receive limit.
+const MaxRequestBytes = 4 << 20
+
+// MetadataPrefix marks headers passed through as gRPC metadata unchanged in
+// both directions, e.g. Nanabush-Client-Id and Nanabush-Priority on requests
+// and Nanabush-Version-Warning on responses.
+const MetadataPrefix = "nanabush-"
+
+// New returns the REST/JSON gateway for translation. Unary TranslationService
+// methods are served at the paths in their google.api.http annotations and
+// call translation directly, so they see the same validation, limits,
+// scheduling, draining and audit as gRPC calls. TranslateStream is served as
+// server-sent events (see StreamPath).
+func New(ctx context.Context, translation *service.TranslationService, logger *log.Logger) (http.Handler, error) {
+	if logger == nil {
+		logger = translation.Logger
+	}
+	mux := runtime.NewServeMux(
+		runtime.WithIncomingHeaderMatcher(incomingHeader),
+		runtime.WithOutgoingHeaderMatcher(outgoingHeader),
+		// Field names as in the proto, like nanabushctl -json
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+			MarshalOptions:   protojson.MarshalOptions{UseProtoNames: true},
+			UnmarshalOptions: protojson.UnmarshalOptions{DiscardUnknown: true},
+		}),
+	)
+	if err := nanabushv1.RegisterTranslationServiceHandlerServer(ctx, mux, translation); err != nil {
+		return nil, fmt.Errorf("failed to register gateway handlers: %w", err)
+	}
+
+	streams := &streamHandler{mux: mux, translation: translation, logger: logger}
+	if err := mux.HandlePath(http.MethodPost, StreamPath, streams.serve); err != nil {
+		return nil, fmt.Errorf("failed to register %s: %w", StreamPath, err)
+	}
+	return limitBody(mux), nil
+}
+
+// limitBody rejects request bodies over MaxRequestBytes.
+func limitBody(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > MaxRequestBytes {
+			http.Error(w, fmt.Sprintf("request body larger than %d bytes", MaxRequestBytes), http.StatusRequestEntityTooLarge)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, MaxRequestBytes)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// incomingHeader passes nanabush-* headers through as metadata, and other
+// headers as the gateway does by default.
+func incomingHeader(key string) (string, bool) {
+	if lower := strings.ToLower(key); strings.HasPrefix(lower, MetadataPrefix) {
+		return lower, true
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+// outgoingHeader returns nanabush-* metadata as plain headers, and other
+// metadata with the gateway's Grpc-Metadata- prefix.
+func outgoingHeader(key string) (string, bool) {
+	if strings.HasPrefix(key, MetadataPrefix) {
+		return key, true
+	}
+	return runtime.MetadataHeaderPrefix + key, true
+}
@@ -0,0 +1,191 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/dasmlab/nanabush/server/pkg/chunk"
+	"github.com/dasmlab/nanabush/server/pkg/proto/v1"
+	"github.com/dasmlab/nanabush/server/pkg/service"
+)
+
+// StreamPath serves TranslateStream as server-sent events. The request body
+// is the raw document, split into chunks for the service; query parameters are job_id (required) and
+// chunk_size. Each translated chunk is a "chunk" event, the final one a
+// "done" event, and a failure an "error" event, all with JSON data. It only
+// wraps TranslateStream, which still echoes placeholder chunks without
+// calling the backend (capabilities report streaming_supported=false).
+const StreamPath = "/v1/translate:stream"
+
+const streamMethod = "/nanabush.v1.TranslationService/TranslateStream"
+
+// defaultChunkSize is the chunk size when the request does not set one, the
+// same as the Go client's.
+const defaultChunkSize = 64 * 1024
+
+var chunkJSON = protojson.MarshalOptions{UseProtoNames: true}
+
+type streamHandler struct {
+	mux         *runtime.ServeMux
+	translation *service.TranslationService
+	logger      *log.Logger
+}
+
+func (h *streamHandler) serve(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	_, marshaler := runtime.MarshalerForRequest(h.mux, r)
+	fail := func(ctx context.Context, err error) {
+		runtime.HTTPError(ctx, h.mux, marshaler, w, r, err)
+	}
+
+	ctx, err := runtime.AnnotateIncomingContext(r.Context(), h.mux, r, streamMethod, runtime.WithHTTPPathPattern(StreamPath))
+	if err != nil {
+		fail(r.Context(), err)
+		return
+	}
+	query := r.URL.Query()
+	jobID := query.Get("job_id")
+	if jobID == "" {
+		fail(ctx, status.Error(codes.InvalidArgument, "job_id is required"))
+		return
+	}
+	chunkSize := defaultChunkSize
+	if value := query.Get("chunk_size"); value != "" {
+		chunkSize, err = strconv.Atoi(value)
+		if err != nil || chunkSize < chunk.MinSize || chunkSize > MaxRequestBytes {
+			fail(ctx, status.Error(codes.InvalidArgument, fmt.Sprintf("chunk_size must be between %d and %d", chunk.MinSize, MaxRequestBytes)))
+			return
+		}
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		fail(ctx, status.Error(codes.Internal, "streaming is not supported by this connection"))
+		return
+	}
+	// Read the whole body first: HTTP/1.1 servers close the request body once
+	// the response starts, and the body is bounded by MaxRequestBytes anyway.
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			fail(ctx, status.Error(codes.ResourceExhausted, fmt.Sprintf("request body larger than %d bytes", MaxRequestBytes)))
+		} else {
+			fail(ctx, status.Error(codes.InvalidArgument, fmt.Sprintf("failed to read request body: %v", err)))
+		}
+		return
+	}
+
+	stream := &sseStream{
+		ctx:     ctx,
+		jobID:   jobID,
+		chunks:  chunk.NewReader(bytes.NewReader(body), chunkSize),
+		w:       w,
+		flusher: flusher,
+	}
+	err = h.translation.TranslateStream(stream)
+	if err == nil {
+		return
+	}
+	if !stream.started {
+		// Nothing sent yet, so the error can still be an HTTP status
+		fail(ctx, err)
+		return
+	}
+	st := status.Convert(err)
+	data, _ := json.Marshal(struct {
+		Code    codes.Code `json:"code"`
+		Message string     `json:"message"`
+	}{st.Code(), st.Message()})
+	stream.event("error", string(data))
+	h.logger.Printf("Gateway stream failed: job_id=%q, err=%v", jobID, err)
+}
+
+// sseStream feeds the request body to TranslateStream in chunks and writes
+// what it sends back as server-sent events.
+type sseStream struct {
+	ctx     context.Context
+	jobID   string
+	chunks  *chunk.Reader
+	index   int32
+	final   bool // The final chunk was received
+	w       http.ResponseWriter
+	flusher http.Flusher
+	started bool // Response headers were written
+}
+
+// Recv returns the next chunk of the request body, then a final chunk, then io.EOF.
+func (s *sseStream) Recv() (*nanabushv1.TranslateChunk, error) {
+	if s.final {
+		return nil, io.EOF
+	}
+	content, err := s.chunks.Next()
+	if errors.Is(err, io.EOF) {
+		s.final = true
+		return &nanabushv1.TranslateChunk{JobId: s.jobID, ChunkIndex: s.index, IsFinal: true}, nil
+	}
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("failed to read request body: %v", err))
+	}
+	chunk := &nanabushv1.TranslateChunk{JobId: s.jobID, ChunkIndex: s.index, Content: content}
+	s.index++
+	return chunk, nil
+}
+
+// Send writes a translated chunk as a "chunk" event, or "done" when final.
+func (s *sseStream) Send(chunk *nanabushv1.TranslateChunk) error {
+	data, err := chunkJSON.Marshal(chunk)
+	if err != nil {
+		return err
+	}
+	name := "chunk"
+	if chunk.IsFinal {
+		name = "done"
+	}
+	return s.event(name, string(data))
+}
+
+// event writes one server-sent event and flushes it to the client.
+func (s *sseStream) event(name, data string) error {
+	if !s.started {
+		header := s.w.Header()
+		header.Set("Content-Type", "text/event-stream")
+		header.Set("Cache-Control", "no-cache")
+		header.Set("X-Accel-Buffering", "no") // Keep proxies from buffering events
+		s.w.WriteHeader(http.StatusOK)
+		s.started = true
+	}
+	if _, err := fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", name, data); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return s.ctx.Err()
+}
+
+func (s *sseStream) Context() context.Context     { return s.ctx }
+func (s *sseStream) SetHeader(metadata.MD) error  { return nil }
+func (s *sseStream) SendHeader(metadata.MD) error { return nil }
+func (s *sseStream) SetTrailer(metadata.MD)       {}
+func (s *sseStream) SendMsg(m interface{}) error  { return s.Send(m.(*nanabushv1.TranslateChunk)) }
+func (s *sseStream) RecvMsg(m interface{}) error {
+	chunk, err := s.Recv()
+	if err != nil {
+		return err
+	}
+	msg := m.(*nanabushv1.TranslateChunk)
+	proto.Reset(msg)
+	proto.Merge(msg, chunk)
+	return nil
+}
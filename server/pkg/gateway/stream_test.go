@@ -0,0 +1,48 @@
+package gateway
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dasmlab/nanabush/server/pkg/service"
+)
+
+func TestStreamChunkSize(t *testing.T) {
+	handler, err := New(context.Background(), service.NewTranslationService(nil, log.New(io.Discard, "", 0)), nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	tests := []struct {
+		chunkSize  string
+		wantStatus int
+	}{
+		{"", http.StatusOK},
+		{"4", http.StatusOK},
+		{"1", http.StatusBadRequest},
+		{"3", http.StatusBadRequest},
+		{"0", http.StatusBadRequest},
+		{"-1", http.StatusBadRequest},
+		{"lots", http.StatusBadRequest},
+		{"4194305", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		url := StreamPath + "?job_id=job-1"
+		if tt.chunkSize != "" {
+			url += "&chunk_size=" + tt.chunkSize
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, url, strings.NewReader("héllo\nwörld")))
+		if rec.Code != tt.wantStatus {
+			t.Errorf("chunk_size=%q: status %d, want %d: %s", tt.chunkSize, rec.Code, tt.wantStatus, rec.Body)
+			continue
+		}
+		if tt.wantStatus == http.StatusOK && !strings.Contains(rec.Body.String(), "event: done\n") {
+			t.Errorf("chunk_size=%q: no done event in %q", tt.chunkSize, rec.Body)
+		}
+	}
+}
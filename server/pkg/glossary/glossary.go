@@ -0,0 +1,440 @@
+// Package glossary finds terms that recur across the pages of a collection
+// and checks how consistently a set of translations rendered them.
+package glossary
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/dasmlab/nanabush/server/pkg/langid"
+)
+
+// DefaultMaxTerms is the default cap on terms returned by Extract.
+const DefaultMaxTerms = 50
+
+// MinDocuments is how many documents a term must appear in to be extracted.
+const MinDocuments = 2
+
+// maxPhraseWords is the longest phrase considered a term.
+const maxPhraseWords = 4
+
+var (
+	// skipPattern matches text that is copied unchanged by translation:
+	// code, URLs, link targets, HTML tags and {{...}} placeholders.
+	skipPattern = regexp.MustCompile("(?s)```.*?```|`[^`\n]*`|\\]\\([^)]*\\)|https?://\\S+|<[^>\n]+>|\\{\\{[^}\n]*\\}\\}")
+	// wordPattern matches one word; apostrophes and hyphens join its parts.
+	wordPattern = regexp.MustCompile(`[\p{L}\p{N}]+(?:['’-][\p{L}\p{N}]+)*`)
+	// segmentBreak ends a run of words: any punctuation other than a hyphen or apostrophe.
+	segmentBreak = regexp.MustCompile(`[^\p{L}\p{N}\s'’-]+|\n`)
+)
+
+// stopwords are common English words that never start or end a term. There
+// is no list for other languages, so Extract only looks for lowercase phrases
+// in English text: elsewhere it could not tell "build pipeline" from a phrase
+// such as "pour les".
+var stopwords = toSet(`a about above after again all also an and any are as at be because been before
+being below between both but by can could did do does doing down during each few for from further had has
+have having he her here hers him his how i if in into is it its itself just may me might more most must my
+no nor not now of off on once only or other our ours out over own same see she should so some such than
+that the their theirs them then there these they this those through to too under until up use used using
+very was we were what when where which while who whom why will with would you your yours`)
+
+// Term is a candidate term found by Extract.
+type Term struct {
+	// Source is the most common spelling of the term
+	Source string
+	// Documents is the number of documents that use it
+	Documents int
+	// Occurrences is the number of times it is used across all documents
+	Occurrences int
+}
+
+// Pair is a glossary entry: a source term and the rendering it must get.
+type Pair struct {
+	Source string
+	Target string
+}
+
+// candidate accumulates the uses of one term while extracting.
+type candidate struct {
+	spellings   map[string]int
+	documents   map[int]bool
+	occurrences int
+	phrases     int // Occurrences found as lowercase phrases, which overlap
+}
+
+// Extract returns the terms that recur across at least MinDocuments of docs,
+// written in sourceLang (a BCP 47 tag), most used first, at most maxTerms of
+// them (DefaultMaxTerms when <= 0).
+//
+// Candidates are capitalized phrases ("Release Manager"), technical words
+// (CamelCase, acronyms, words with digits) and, in English only, lowercase
+// phrases of two or three words without common words ("build pipeline",
+// "point of sale"). Code, URLs, HTML and placeholders are ignored.
+// A shorter term that only ever appears inside a longer one is dropped.
+func Extract(docs []string, sourceLang string, maxTerms int) []Term {
+	if maxTerms <= 0 {
+		maxTerms = DefaultMaxTerms
+	}
+
+	candidates := make(map[string]*candidate)
+	add := func(doc int, words []string, fromPhrase bool) {
+		phrase := strings.Join(words, " ")
+		key := strings.ToLower(phrase)
+		c, ok := candidates[key]
+		if !ok {
+			c = &candidate{spellings: make(map[string]int), documents: make(map[int]bool)}
+			candidates[key] = c
+		}
+		c.spellings[phrase]++
+		c.documents[doc] = true
+		c.occurrences++
+		if fromPhrase {
+			c.phrases++
+		}
+	}
+
+	// Words also used in lower case ("contact") are ordinary words when they
+	// are capitalized only because they start a sentence ("Contact Support Team")
+	docSegments := make([][][]string, len(docs))
+	lowercase := make(map[string]bool)
+	for i, doc := range docs {
+		docSegments[i] = segments(doc)
+		for _, segment := range docSegments[i] {
+			for _, word := range segment {
+				if !capitalized(word) {
+					lowercase[word] = true
+				}
+			}
+		}
+	}
+
+	english := langid.PrimarySubtag(sourceLang) == "en"
+	for i, segs := range docSegments {
+		for _, segment := range segs {
+			for _, words := range candidatesIn(segment, lowercase) {
+				add(i, words, false)
+			}
+			if english {
+				for _, words := range phrasesIn(segment) {
+					add(i, words, true)
+				}
+			}
+		}
+	}
+
+	// Only recurring candidates count, and a shorter one that is always part
+	// of a longer one is dropped. Only lowercase phrases overlap: "build
+	// pipeline" is counted again inside "build pipeline cache", whereas a
+	// capitalized run is taken whole, so "Console" is only a candidate where
+	// it is used without "Admin".
+	recurring := make(map[string]*candidate)
+	for key, c := range candidates {
+		if len(c.documents) >= MinDocuments {
+			recurring[key] = c
+		}
+	}
+	subsumed := make(map[string]bool)
+	for key, c := range recurring {
+		words := strings.Fields(key)
+		for n := 1; n < len(words); n++ {
+			for start := 0; start+n <= len(words); start++ {
+				sub := strings.Join(words[start:start+n], " ")
+				if s, ok := recurring[sub]; ok && s.phrases == s.occurrences && s.occurrences <= c.phrases {
+					subsumed[sub] = true
+				}
+			}
+		}
+	}
+
+	terms := make([]Term, 0, len(recurring))
+	for key, c := range recurring {
+		if subsumed[key] {
+			continue
+		}
+		terms = append(terms, Term{
+			Source:      preferredSpelling(c.spellings),
+			Documents:   len(c.documents),
+			Occurrences: c.occurrences,
+		})
+	}
+
+	sort.Slice(terms, func(i, j int) bool {
+		si := terms[i].Documents*2 + terms[i].Occurrences
+		sj := terms[j].Documents*2 + terms[j].Occurrences
+		if si != sj {
+			return si > sj
+		}
+		return strings.ToLower(terms[i].Source) < strings.ToLower(terms[j].Source)
+	})
+	if len(terms) > maxTerms {
+		terms = terms[:maxTerms]
+	}
+	return terms
+}
+
+// segments splits text into runs of words that can form a term, with the
+// text that translation leaves alone removed.
+func segments(text string) [][]string {
+	text = skipPattern.ReplaceAllString(text, "\n")
+	var out [][]string
+	for _, part := range segmentBreak.Split(text, -1) {
+		words := wordPattern.FindAllString(part, -1)
+		if len(words) > 0 {
+			out = append(out, words)
+		}
+	}
+	return out
+}
+
+// candidatesIn returns the capitalized and technical candidate terms in one
+// segment. lowercase holds the words seen in lower case anywhere in the
+// collection.
+func candidatesIn(words []string, lowercase map[string]bool) [][]string {
+	var out [][]string
+
+	// Capitalized runs. A lone capitalized word is only a term when it is
+	// technical or not the first word of the segment (which may just start
+	// a sentence, heading or list item).
+	for start := 0; start < len(words); {
+		if !capitalized(words[start]) {
+			start++
+			continue
+		}
+		end := start
+		for end < len(words) && capitalized(words[end]) {
+			end++
+		}
+		first, last := start, end
+		start = end
+		// "The Release Manager" starts a sentence; the term is "Release Manager"
+		for first < last && stopwords[strings.ToLower(words[first])] {
+			first++
+		}
+		for last > first && stopwords[strings.ToLower(words[last-1])] {
+			last--
+		}
+		if first == 0 && last-first > 2 && lowercase[strings.ToLower(words[0])] {
+			first++
+		}
+		run := words[first:last]
+		switch {
+		case len(run) == 1:
+			if technical(run[0]) || first > 0 {
+				out = append(out, run)
+			}
+		case len(run) > 1 && len(run) <= maxPhraseWords:
+			out = append(out, run)
+		}
+	}
+
+	// Technical words inside lowercase text, e.g. "the k8s cluster"
+	for _, word := range words {
+		if !capitalized(word) && technical(word) {
+			out = append(out, []string{word})
+		}
+	}
+	return out
+}
+
+// phrasesIn returns the lowercase phrases of two or three words in one segment.
+func phrasesIn(words []string) [][]string {
+	var out [][]string
+	for n := 2; n <= 3; n++ {
+		for start := 0; start+n <= len(words); start++ {
+			phrase := words[start : start+n]
+			if lowercasePhrase(phrase) {
+				out = append(out, phrase)
+			}
+		}
+	}
+	return out
+}
+
+// lowercasePhrase reports whether words form a lowercase term candidate:
+// no stopwords other than "of" between the first and last word.
+func lowercasePhrase(words []string) bool {
+	for i, word := range words {
+		if capitalized(word) || technical(word) || !hasLetter(word) {
+			return false
+		}
+		if stopwords[word] {
+			if word != "of" || i == 0 || i == len(words)-1 {
+				return false
+			}
+			continue
+		}
+		if utf8.RuneCountInString(word) < 3 {
+			return false
+		}
+	}
+	return true
+}
+
+// capitalized reports whether word starts with an upper-case letter.
+func capitalized(word string) bool {
+	r, _ := utf8.DecodeRuneInString(word)
+	return unicode.IsUpper(r)
+}
+
+// technical reports whether word looks like an identifier or product name:
+// an acronym, CamelCase or letters mixed with digits.
+func technical(word string) bool {
+	var upper, lower, digit int
+	for i, r := range word {
+		switch {
+		case unicode.IsUpper(r):
+			upper++
+			if i > 0 && lower > 0 {
+				return true // CamelCase, e.g. "GitOps", "iPhone"
+			}
+		case unicode.IsLower(r):
+			lower++
+		case unicode.IsDigit(r):
+			digit++
+		}
+	}
+	letters := upper + lower
+	if letters == 0 {
+		return false
+	}
+	if lower == 0 && upper >= 2 {
+		return true // Acronym, e.g. "API"
+	}
+	return digit > 0 // e.g. "k8s", "S3"
+}
+
+// hasLetter reports whether word contains a letter.
+func hasLetter(word string) bool {
+	for _, r := range word {
+		if unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// Page is one translated document checked by Check.
+type Page struct {
+	// Index identifies the document in the report
+	Index       int
+	Source      string
+	Translation string
+}
+
+// Usage is how consistently one glossary term was rendered.
+type Usage struct {
+	Pair
+	// Documents is the number of pages whose source uses the term
+	Documents int
+	// Consistent is the number of those pages whose translation contains the target
+	Consistent int
+	// Inconsistent lists the Index of the other pages
+	Inconsistent []int
+}
+
+// Report is the result of Check.
+type Report struct {
+	// Score is the share of term uses (one per term and page) rendered as
+	// the glossary says, 0-1; 1 when no page uses any term
+	Score float64
+	// Terms has one entry per glossary pair, in glossary order
+	Terms []Usage
+	// InconsistentTerms counts the terms with at least one inconsistent page
+	InconsistentTerms int
+}
+
+// Check reports, for each pair, which pages use the source term and whether
+// their translation contains the target. Matching is case-insensitive on
+// whole words and ignores code, URLs, HTML and placeholders.
+func Check(pairs []Pair, pages []Page) Report {
+	sources := make([]string, len(pages))
+	translations := make([]string, len(pages))
+	for i, page := range pages {
+		sources[i] = prose(page.Source)
+		translations[i] = prose(page.Translation)
+	}
+
+	report := Report{Terms: make([]Usage, 0, len(pairs))}
+	uses, consistent := 0, 0
+	for _, pair := range pairs {
+		usage := Usage{Pair: pair}
+		source, target := matcher(pair.Source), matcher(pair.Target)
+		for i, page := range pages {
+			if source == nil || !source.MatchString(sources[i]) {
+				continue
+			}
+			usage.Documents++
+			if target != nil && target.MatchString(translations[i]) {
+				usage.Consistent++
+			} else {
+				usage.Inconsistent = append(usage.Inconsistent, page.Index)
+			}
+		}
+		if len(usage.Inconsistent) > 0 {
+			report.InconsistentTerms++
+		}
+		uses += usage.Documents
+		consistent += usage.Consistent
+		report.Terms = append(report.Terms, usage)
+	}
+
+	report.Score = 1
+	if uses > 0 {
+		report.Score = float64(consistent) / float64(uses)
+	}
+	return report
+}
+
+// Relevant returns the pairs whose source term appears in text.
+func Relevant(pairs []Pair, text string) []Pair {
+	text = prose(text)
+	var out []Pair
+	for _, pair := range pairs {
+		if re := matcher(pair.Source); re != nil && re.MatchString(text) {
+			out = append(out, pair)
+		}
+	}
+	return out
+}
+
+// prose returns text without the parts translation leaves alone.
+func prose(text string) string {
+	return skipPattern.ReplaceAllString(text, "\n")
+}
+
+// matcher compiles a case-insensitive whole-word pattern for term, allowing
+// any whitespace between its words. It returns nil for a blank term.
+func matcher(term string) *regexp.Regexp {
+	fields := strings.Fields(term)
+	if len(fields) == 0 {
+		return nil
+	}
+	for i, field := range fields {
+		fields[i] = regexp.QuoteMeta(field)
+	}
+	return regexp.MustCompile(`(?i)(?:^|[^\p{L}\p{N}])` + strings.Join(fields, `\s+`) + `(?:$|[^\p{L}\p{N}])`)
+}
+
+// preferredSpelling returns the most common spelling, breaking ties alphabetically.
+func preferredSpelling(spellings map[string]int) string {
+	best, count := "", 0
+	for spelling, n := range spellings {
+		if n > count || n == count && spelling < best {
+			best, count = spelling, n
+		}
+	}
+	return best
+}
+
+// toSet splits a whitespace-separated word list into a set.
+func toSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(words) {
+		set[word] = true
+	}
+	return set
+}
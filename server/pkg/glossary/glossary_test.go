@@ -0,0 +1,194 @@
+package glossary
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name     string
+		lang     string
+		docs     []string
+		maxTerms int
+		want     []string
+	}{
+		{
+			name: "capitalized phrase and technical word",
+			lang: "en",
+			docs: []string{
+				"Ask the Release Manager before a GitOps change.",
+				"The Release Manager approves every GitOps rollout.",
+			},
+			want: []string{"GitOps", "Release Manager"},
+		},
+		{
+			name: "needs two documents",
+			lang: "en",
+			docs: []string{
+				"The Release Manager and the Release Manager again.",
+				"Nothing in common here.",
+			},
+		},
+		{
+			name: "leading stopwords dropped",
+			lang: "en",
+			docs: []string{
+				"Page the Release Manager on call.",
+				"The Release Manager signs off.",
+				"Only the Release Manager can tag.",
+			},
+			want: []string{"Release Manager"},
+		},
+		{
+			name: "capitalized word also used on its own",
+			lang: "en",
+			docs: []string{
+				"Open the Console, then the Admin Console.",
+				"The Console lists pods; the Admin Console lists nodes.",
+			},
+			want: []string{"Admin Console", "Console"},
+		},
+		{
+			name: "word capitalized only to start a sentence",
+			lang: "en",
+			docs: []string{
+				"Contact the owner. Contact Support Team if needed.",
+				"Contact Support Team first, then contact the owner.",
+			},
+			want: []string{"Support Team"},
+		},
+		{
+			name: "lowercase phrases in English",
+			lang: "en",
+			docs: []string{
+				"Every build pipeline runs at the point of sale.",
+				"The build pipeline is shared with the point of sale.",
+			},
+			want: []string{"build pipeline", "point of sale"},
+		},
+		{
+			name: "lowercase phrase only used inside a longer one",
+			lang: "en",
+			docs: []string{
+				"Clear the build pipeline cache.",
+				"The build pipeline cache fills up.",
+			},
+			want: []string{"build pipeline cache"},
+		},
+		{
+			name: "lowercase phrase also used on its own",
+			lang: "en",
+			docs: []string{
+				"Clear the build pipeline cache, then rerun the build pipeline.",
+				"The build pipeline cache slows every build pipeline.",
+			},
+			want: []string{"build pipeline", "build pipeline cache"},
+		},
+		{
+			name: "no lowercase phrases without a stopword list",
+			lang: "fr-CA",
+			docs: []string{
+				"La chaîne de construction pour les équipes utilise GitOps.",
+				"Chaque chaîne de construction pour les équipes passe par GitOps.",
+			},
+			want: []string{"GitOps"},
+		},
+		{
+			name: "code, links and placeholders ignored",
+			lang: "en",
+			docs: []string{
+				"Run `kubectl apply` with {{ReleaseName}} from [the docs](https://example.com/GitOps).",
+				"Run `kubectl apply` with {{ReleaseName}} from <a href=\"GitOps\">the docs</a>.",
+			},
+		},
+		{
+			name: "capped to the most used",
+			lang: "en",
+			docs: []string{
+				"The API and the SDK and the API again.",
+				"The API and the SDK.",
+			},
+			maxTerms: 1,
+			want:     []string{"API"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, term := range Extract(tt.docs, tt.lang, tt.maxTerms) {
+				got = append(got, term.Source)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Extract = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractCounts(t *testing.T) {
+	docs := []string{
+		"The k8s cluster runs on k8s nodes.",
+		"Upgrade the K8s control plane.",
+		"Nothing here.",
+	}
+	want := []Term{{Source: "k8s", Documents: 2, Occurrences: 3}}
+	if got := Extract(docs, "en", 0); !reflect.DeepEqual(got, want) {
+		t.Errorf("Extract = %+v, want %+v", got, want)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	pairs := []Pair{
+		{Source: "Release Manager", Target: "responsable des versions"},
+		{Source: "GitOps", Target: "GitOps"},
+		{Source: "canary", Target: "canari"},
+	}
+	pages := []Page{
+		{Index: 0, Source: "Ask the release  manager.", Translation: "Demandez au Responsable des versions."},
+		{Index: 1, Source: "The Release Manager uses GitOps.", Translation: "Le gestionnaire de version utilise GitOps."},
+		{Index: 4, Source: "GitOps rollouts", Translation: "Déploiements `GitOps`"},
+		{Index: 5, Source: "See `canary` or the GitOpsy tool.", Translation: "Voir `canary`."},
+	}
+
+	got := Check(pairs, pages)
+	want := Report{
+		Score: 2.0 / 4,
+		Terms: []Usage{
+			{Pair: pairs[0], Documents: 2, Consistent: 1, Inconsistent: []int{1}},
+			{Pair: pairs[1], Documents: 2, Consistent: 1, Inconsistent: []int{4}},
+			{Pair: pairs[2]},
+		},
+		InconsistentTerms: 2,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Check = %+v, want %+v", got, want)
+	}
+
+	if got := Check(pairs, nil); got.Score != 1 || got.InconsistentTerms != 0 {
+		t.Errorf("Check without pages = %+v, want a score of 1", got)
+	}
+}
+
+func TestRelevant(t *testing.T) {
+	pairs := []Pair{
+		{Source: "Release Manager", Target: "responsable des versions"},
+		{Source: "API", Target: "API"},
+		{Source: "pipeline", Target: "chaîne"},
+		{Source: " ", Target: "blank"},
+	}
+	tests := []struct {
+		text string
+		want []Pair
+	}{
+		{"Ask the release\nmanager about the API.", pairs[:2]},
+		{"The APIs and pipelines", nil},
+		{"Call `api` at https://example.com/pipeline", nil},
+		{"Pipeline: build", pairs[2:3]},
+	}
+	for _, tt := range tests {
+		if got := Relevant(pairs, tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Relevant(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package guard
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dasmlab/nanabush/server/pkg/langid"
+)
+
+// Check names reported in violations.
+const (
+	CheckEmptyOutput     = "empty_output"
+	CheckLengthRatio     = "length_ratio"
+	CheckLanguage        = "language_mismatch"
+	CheckInstructionText = "instruction_text"
+)
+
+// Config tunes the output checks.
+type Config struct {
+	// MinLengthRatio and MaxLengthRatio bound output length relative to the source.
+	MinLengthRatio float64
+	MaxLengthRatio float64
+
+	// MinCheckedChars is the source length below which length and language
+	// checks are skipped (short titles vary too much to judge).
+	MinCheckedChars int
+
+	// LanguageConfidence is the detection confidence needed to call a language mismatch.
+	LanguageConfidence float64
+}
+
+// DefaultConfig returns the default thresholds.
+func DefaultConfig() Config {
+	return Config{
+		MinLengthRatio:     0.33,
+		MaxLengthRatio:     3.0,
+		MinCheckedChars:    80,
+		LanguageConfidence: 0.5,
+	}
+}
+
+// Violation explains why an output was rejected.
+type Violation struct {
+	Check  string
+	Reason string
+}
+
+// Error implements error.
+func (v *Violation) Error() string {
+	return fmt.Sprintf("output rejected (%s): %s", v.Check, v.Reason)
+}
+
+// structuralPatterns match prompt and chat-template syntax that a translation
+// never produces: leaked content delimiters and model control tokens. They
+// are language-independent, so they are checked for every pair.
+var structuralPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`NANABUSH-CONTENT-`),
+	regexp.MustCompile(`<\|(?:im_start|im_end|im_sep|endoftext|eot_id|start_header_id|end_header_id|begin_of_text|system|user|assistant)\|>`),
+	regexp.MustCompile(`\[/?INST\]|<</?SYS>>`),
+}
+
+// phrase is one kind of text typical of a model following injected
+// instructions or talking about the task instead of translating, as written
+// in each language the guard knows (keyed by primary language subtag).
+type phrase struct {
+	name     string
+	patterns map[string]*regexp.Regexp
+}
+
+// phrases are looked for in the output in the target language and in
+// English, which models fall back to when they refuse or leak the prompt.
+// Regexp \b only sees ASCII word boundaries, so it is not used next to
+// accented letters.
+var phrases = []phrase{
+	newPhrase("ignore instructions", map[string]string{
+		"en": `\b(?:ignore|disregard|forget)\b.{0,30}\b(?:previous|prior|above|earlier)\b.{0,20}\binstructions?\b`,
+		"fr": `\b(?:ignor|oubli)\w*\b.{0,40}\binstructions?\b`,
+		"es": `\b(?:ignor|olvid)\w*\b.{0,40}\binstrucci(?:ón|ones)`,
+		"de": `\b(?:ignorier|vergiss|vergess)\w*\b.{0,40}(?:anweisung|instruktion)|(?:anweisung|instruktion)\w*\b.{0,40}\b(?:ignorier|vergess)`,
+		"it": `\b(?:ignor|dimentic)\w*\b.{0,40}\bistruzion[ei]\b`,
+		"pt": `\b(?:ignor|esque[cç])\w*.{0,40}\binstru[cç](?:ão|ões)`,
+		"nl": `\b(?:negeer|vergeet)\w*\b.{0,40}\binstructies?\b`,
+	}),
+	newPhrase("system prompt", map[string]string{
+		"en": `\bsystem prompt\b`,
+		"fr": `\b(?:prompt|invite|message) syst[eè]me\b`,
+		"es": `\b(?:prompt|indicaci[oó]n|mensaje) del sistema\b`,
+		"de": `\bsystem-?prompt\b|\bsystemanweisung`,
+		"it": `\b(?:prompt|messaggio) di sistema\b`,
+		"pt": `\b(?:prompt|mensagem) do sistema\b`,
+		"nl": `\bsysteem-?prompt\b`,
+	}),
+	newPhrase("language model", map[string]string{
+		"en": `\bas an ai\b|\blanguage models?\b`,
+		"fr": `\bmod[eè]les? de langage\b|\ben tant qu['’](?:ia|intelligence artificielle)\b`,
+		"es": `\bmodelos? de lenguaje\b|\bcomo (?:una )?(?:ia|inteligencia artificial)\b`,
+		"de": `\bsprachmodell|\bals (?:eine )?ki\b`,
+		"it": `\bmodell[oi] (?:di|del) linguaggio\b|\bin quanto (?:ia|intelligenza artificiale)\b`,
+		"pt": `\bmodelos? de linguagem\b|\bcomo (?:uma )?(?:ia|intelig[eê]ncia artificial)\b`,
+		"nl": `\btaalmodel|\bals (?:een )?ai\b`,
+	}),
+	newPhrase("refusal", map[string]string{
+		"en": `\bi(?:'m| am) (?:sorry|unable)\b|\bi can(?:not|'t) (?:help|assist|translate|comply)\b`,
+		"fr": `\bje suis (?:d[ée]sol|navr)|\bje ne peux pas (?:vous )?(?:aider|traduire|r[ée]pondre)`,
+		"es": `\blo siento\b|\bno puedo (?:ayudar|traducir|cumplir)`,
+		"de": `\bes tut mir leid\b|\bich kann\b.{0,20}\bnicht\b.{0,10}(?:helfen|übersetzen)`,
+		"it": `\bmi dispiace\b|\bnon posso (?:aiutar|tradurre)`,
+		"pt": `\bsinto muito\b|\bn[aã]o posso (?:ajudar|traduzir)`,
+		"nl": `\bhet spijt me\b|\bik kan (?:je |u )?niet (?:helpen|vertalen)`,
+	}),
+	newPhrase("translation preamble", map[string]string{
+		"en": `\bhere(?: is|'s) (?:the|your) translat`,
+		"fr": `\bvoici (?:la|votre) traduction`,
+		"es": `\baqu[ií] (?:est[aá]|tienes) (?:la|tu|su) traducci`,
+		"de": `\bhier ist (?:die|deine|ihre) übersetzung`,
+		"it": `\becco (?:la|la tua) traduzion`,
+		"pt": `\baqui (?:est[aá]|vai) (?:a|sua) tradu[cç]`,
+		"nl": `\bhier is (?:de|je|uw) vertaling`,
+	}),
+}
+
+// newPhrase compiles the case-insensitive patterns of a phrase.
+func newPhrase(name string, patterns map[string]string) phrase {
+	p := phrase{name: name, patterns: make(map[string]*regexp.Regexp, len(patterns))}
+	for lang, pattern := range patterns {
+		p.patterns[lang] = regexp.MustCompile(`(?i)` + pattern)
+	}
+	return p
+}
+
+// find returns the first text of the phrase in text written in one of langs.
+func (p phrase) find(text string, langs ...string) string {
+	for _, lang := range langs {
+		if pattern, ok := p.patterns[lang]; ok {
+			if match := pattern.FindString(text); match != "" {
+				return match
+			}
+		}
+	}
+	return ""
+}
+
+// in reports whether text contains the phrase in any language.
+func (p phrase) in(text string) bool {
+	for _, pattern := range p.patterns {
+		if pattern.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasPhrases reports whether the guard knows the phrases of lang.
+func hasPhrases(lang string) bool {
+	_, ok := phrases[0].patterns[langid.PrimarySubtag(lang)]
+	return ok
+}
+
+// Guard checks that backend output is a translation of the source rather than
+// something else (a refusal, leaked prompt, or output steered by injected text).
+type Guard struct {
+	cfg    Config
+	langID *langid.Identifier
+}
+
+// New creates a Guard. langID may be nil to skip the language check.
+func New(cfg Config, langID *langid.Identifier) *Guard {
+	return &Guard{cfg: cfg, langID: langID}
+}
+
+// Check compares a translation from sourceLang against its source. It returns
+// nil when the output looks like a translation into targetLang.
+func (g *Guard) Check(source, output, sourceLang, targetLang string) *Violation {
+	source = strings.TrimSpace(source)
+	output = strings.TrimSpace(output)
+	if source == "" {
+		return nil
+	}
+	if output == "" {
+		return &Violation{Check: CheckEmptyOutput, Reason: "backend returned no text"}
+	}
+
+	// Prompt and template syntax never belongs in a translation, whatever the pair
+	for _, pattern := range structuralPatterns {
+		if match := pattern.FindString(output); match != "" && !pattern.MatchString(source) {
+			return unexpectedText(match)
+		}
+	}
+
+	// Instruction-like phrases are only suspicious when the source does not
+	// say the same thing in its own language. Without the source language's
+	// phrases that cannot be told, unless source and output share a language.
+	if hasPhrases(sourceLang) || langid.SameLanguage(sourceLang, targetLang) {
+		for _, p := range phrases {
+			if match := p.find(output, langid.PrimarySubtag(targetLang), "en"); match != "" && !p.in(source) {
+				return unexpectedText(match)
+			}
+		}
+	}
+
+	sourceLen := utf8.RuneCountInString(source)
+	if sourceLen < g.cfg.MinCheckedChars {
+		return nil
+	}
+
+	ratio := float64(utf8.RuneCountInString(output)) / float64(sourceLen)
+	if ratio < g.cfg.MinLengthRatio || ratio > g.cfg.MaxLengthRatio {
+		return &Violation{Check: CheckLengthRatio, Reason: fmt.Sprintf("output/source length ratio %.2f outside [%.2f, %.2f]",
+			ratio, g.cfg.MinLengthRatio, g.cfg.MaxLengthRatio)}
+	}
+
+	if g.langID != nil && g.langID.Knows(targetLang) {
+		result := g.langID.Detect(output)
+		if result.Confidence >= g.cfg.LanguageConfidence && !langid.SameLanguage(result.Language, targetLang) {
+			return &Violation{Check: CheckLanguage, Reason: fmt.Sprintf("output detected as %q (confidence %.2f), expected %q",
+				result.Language, result.Confidence, targetLang)}
+		}
+	}
+
+	return nil
+}
+
+// unexpectedText reports instruction-like text found in the output but not in the source.
+func unexpectedText(match string) *Violation {
+	return &Violation{Check: CheckInstructionText, Reason: fmt.Sprintf("output contains %q, which is not in the source", match)}
+}
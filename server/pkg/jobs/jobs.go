@@ -0,0 +1,224 @@
+package jobs
+
+import (
+	"container/list"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/dasmlab/nanabush/server/pkg/proto/v1"
+)
+
+// Defaults for the completed job history.
+const (
+	DefaultCapacity  = 1000
+	DefaultRetention = 24 * time.Hour
+)
+
+// Job is a completed translation kept for follow-up calls such as feedback.
+type Job struct {
+	JobID     string                   `json:"job_id"`
+	Namespace string                   `json:"namespace,omitempty"`
+	ClientID  string                   `json:"client_id,omitempty"`
+	Primitive nanabushv1.PrimitiveType `json:"primitive"`
+
+	SourceLanguage string `json:"source_language,omitempty"`
+	TargetLanguage string `json:"target_language,omitempty"`
+	Model          string `json:"model,omitempty"`
+
+	// Content is only kept when a consumer needs it (e.g. the feedback dataset).
+	// It is the raw request and response, before PII scrubbing, so it stays in
+	// memory: Save leaves it out.
+	SourceTitle    string `json:"source_title,omitempty"`
+	SourceMarkdown string `json:"source_markdown,omitempty"`
+	OutputTitle    string `json:"output_title,omitempty"`
+	OutputMarkdown string `json:"output_markdown,omitempty"`
+
+	// Interrupted is set when the server shut down before the job finished;
+	// CompletedAt is then when it was interrupted. Only the metadata above is
+	// kept, never the request, so an interrupted job does not resume here or
+	// after a restart: its caller is told to resubmit it (Translate callers get
+	// UNAVAILABLE, SubmitTranslate jobs a callback with that status).
+	Interrupted bool `json:"interrupted,omitempty"`
+
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+// Store is a bounded in-memory history of completed jobs. The oldest jobs are
+// evicted once capacity is reached, and jobs older than the retention period
+// are not returned.
+type Store struct {
+	mu        sync.Mutex
+	capacity  int
+	retention time.Duration
+	order     *list.List // Oldest first
+	byID      map[string]*list.Element
+}
+
+// NewStore creates a Store. Zero values use the defaults.
+func NewStore(capacity int, retention time.Duration) *Store {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+	return &Store{
+		capacity:  capacity,
+		retention: retention,
+		order:     list.New(),
+		byID:      make(map[string]*list.Element),
+	}
+}
+
+// Put records a completed job, replacing any earlier job with the same ID.
+func (s *Store) Put(job Job) {
+	if job.CompletedAt.IsZero() {
+		job.CompletedAt = time.Now()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.byID[job.JobID]; ok {
+		s.order.Remove(elem)
+	}
+	s.byID[job.JobID] = s.order.PushBack(&job)
+
+	for s.order.Len() > s.capacity {
+		oldest := s.order.Front()
+		delete(s.byID, oldest.Value.(*Job).JobID)
+		s.order.Remove(oldest)
+	}
+}
+
+// Get returns a job if it is still retained.
+func (s *Store) Get(jobID string) (Job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.byID[jobID]
+	if !ok {
+		return Job{}, false
+	}
+	job := elem.Value.(*Job)
+	if time.Since(job.CompletedAt) > s.retention {
+		delete(s.byID, jobID)
+		s.order.Remove(elem)
+		return Job{}, false
+	}
+	return *job, true
+}
+
+// List returns the retained jobs, newest first.
+func (s *Store) List() []Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := make([]Job, 0, s.order.Len())
+	for elem := s.order.Back(); elem != nil; elem = elem.Prev() {
+		job := elem.Value.(*Job)
+		if time.Since(job.CompletedAt) > s.retention {
+			break
+		}
+		jobs = append(jobs, *job)
+	}
+	return jobs
+}
+
+// Len returns the number of jobs held, including expired jobs not yet pruned.
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+// Prune removes jobs older than the retention period.
+func (s *Store) Prune() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := 0
+	for elem := s.order.Front(); elem != nil; {
+		job := elem.Value.(*Job)
+		if time.Since(job.CompletedAt) <= s.retention {
+			break
+		}
+		next := elem.Next()
+		delete(s.byID, job.JobID)
+		s.order.Remove(elem)
+		elem = next
+		removed++
+	}
+	return removed
+}
+
+// Save writes the retained jobs to path, replacing it atomically, so a
+// restarted server can Restore them. Only job metadata is written, never
+// content, so restored jobs cannot be reviewed and interrupted jobs are a
+// list to resubmit, not work that resumes.
+func (s *Store) Save(path string) (int, error) {
+	jobs := s.List()
+	for i := range jobs {
+		jobs[i].SourceTitle, jobs[i].SourceMarkdown = "", ""
+		jobs[i].OutputTitle, jobs[i].OutputMarkdown = "", ""
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create job history file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	// Oldest first, so Restore replays them in completion order
+	encoder := json.NewEncoder(tmp)
+	for i := len(jobs) - 1; i >= 0; i-- {
+		if err := encoder.Encode(jobs[i]); err != nil {
+			tmp.Close()
+			return 0, fmt.Errorf("failed to write job history file: %w", err)
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, fmt.Errorf("failed to write job history file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, fmt.Errorf("failed to write job history file: %w", err)
+	}
+	return len(jobs), nil
+}
+
+// Restore adds the jobs saved at path. A missing file restores nothing;
+// jobs past the retention period are skipped.
+func (s *Store) Restore(path string) (int, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to open job history file: %w", err)
+	}
+	defer f.Close()
+
+	restored := 0
+	decoder := json.NewDecoder(f)
+	for {
+		var job Job
+		err := decoder.Decode(&job)
+		if errors.Is(err, io.EOF) {
+			return restored, nil
+		}
+		if err != nil {
+			return restored, fmt.Errorf("failed to read job history file %s: %w", path, err)
+		}
+		if job.JobID == "" || time.Since(job.CompletedAt) > s.retention {
+			continue
+		}
+		s.Put(job)
+		restored++
+	}
+}
@@ -0,0 +1,160 @@
+package jobs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStore(t *testing.T) {
+	s := NewStore(2, time.Hour)
+	s.Put(Job{JobID: "a"})
+	s.Put(Job{JobID: "b"})
+	s.Put(Job{JobID: "a", Model: "again"}) // Replaces a, which becomes the newest
+	s.Put(Job{JobID: "c"})                 // Evicts b, the oldest
+
+	if _, ok := s.Get("b"); ok {
+		t.Error("b was not evicted")
+	}
+	if job, ok := s.Get("a"); !ok || job.Model != "again" {
+		t.Errorf("Get(a) = %+v, %v; want the replacement", job, ok)
+	}
+	var ids []string
+	for _, job := range s.List() {
+		ids = append(ids, job.JobID)
+	}
+	if strings.Join(ids, ",") != "c,a" {
+		t.Errorf("List = %v, want [c a] (newest first)", ids)
+	}
+}
+
+func TestStoreRetention(t *testing.T) {
+	s := NewStore(10, time.Hour)
+	s.Put(Job{JobID: "old", CompletedAt: time.Now().Add(-2 * time.Hour)})
+	s.Put(Job{JobID: "new"})
+
+	if list := s.List(); len(list) != 1 || list[0].JobID != "new" {
+		t.Errorf("List = %+v, want only the new job", list)
+	}
+	if removed := s.Prune(); removed != 1 || s.Len() != 1 {
+		t.Errorf("Prune removed %d, %d left; want 1, 1", removed, s.Len())
+	}
+	s.Put(Job{JobID: "expired", CompletedAt: time.Now().Add(-2 * time.Hour)})
+	if _, ok := s.Get("expired"); ok {
+		t.Error("Get returned a job past the retention period")
+	}
+}
+
+func TestSaveRestore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.jsonl")
+	completed := time.Now().Add(-time.Minute).Truncate(time.Second)
+
+	s := NewStore(10, time.Hour)
+	s.Put(Job{JobID: "expired", CompletedAt: time.Now().Add(-2 * time.Hour)})
+	s.Put(Job{
+		JobID:          "done",
+		Namespace:      "wiki",
+		SourceLanguage: "en",
+		TargetLanguage: "fr",
+		SourceTitle:    "Contact ops@example.com",
+		SourceMarkdown: "Call 555-123-4567",
+		OutputTitle:    "Contactez ops@example.com",
+		OutputMarkdown: "Appelez le 555-123-4567",
+		CompletedAt:    completed,
+	})
+	s.Put(Job{JobID: "cut", Interrupted: true, CompletedAt: completed.Add(time.Second)})
+
+	saved, err := s.Save(path)
+	if err != nil || saved != 2 {
+		t.Fatalf("Save = %d, %v; want 2 jobs", saved, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, raw := range []string{"example.com", "555-123-4567"} {
+		if strings.Contains(string(data), raw) {
+			t.Errorf("saved file contains %q:\n%s", raw, data)
+		}
+	}
+
+	restoredStore := NewStore(10, time.Hour)
+	restored, err := restoredStore.Restore(path)
+	if err != nil || restored != 2 {
+		t.Fatalf("Restore = %d, %v; want 2 jobs", restored, err)
+	}
+	done, ok := restoredStore.Get("done")
+	if !ok || done.Namespace != "wiki" || done.TargetLanguage != "fr" || !done.CompletedAt.Equal(completed) {
+		t.Errorf("restored job = %+v", done)
+	}
+	if done.SourceTitle != "" || done.SourceMarkdown != "" || done.OutputTitle != "" || done.OutputMarkdown != "" {
+		t.Errorf("restored job has content: %+v", done)
+	}
+	if cut, ok := restoredStore.Get("cut"); !ok || !cut.Interrupted {
+		t.Errorf("restored interrupted job = %+v, %v", cut, ok)
+	}
+	if list := restoredStore.List(); len(list) != 2 || list[0].JobID != "cut" {
+		t.Errorf("restored order = %+v, want cut (newest) first", list)
+	}
+
+	// Saving leaves the in-memory content alone
+	if job, _ := s.Get("done"); job.SourceTitle == "" {
+		t.Error("Save cleared the content of the live job")
+	}
+}
+
+func TestRestore(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStore(10, time.Hour)
+	if n, err := s.Restore(filepath.Join(dir, "missing.jsonl")); n != 0 || err != nil {
+		t.Errorf("Restore of a missing file = %d, %v; want 0, nil", n, err)
+	}
+
+	path := filepath.Join(dir, "broken.jsonl")
+	line := `{"job_id":"ok","completed_at":"` + time.Now().Format(time.RFC3339) + `"}`
+	if err := os.WriteFile(path, []byte(line+"\n{\"job_id\":\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	n, err := s.Restore(path)
+	if n != 1 || err == nil {
+		t.Errorf("Restore of a truncated file = %d, %v; want 1 and an error", n, err)
+	}
+}
+
+func TestRunning(t *testing.T) {
+	r := NewRunning()
+	ctx1, finish1 := r.Start(context.Background(), RunningJob{JobID: "j"})
+	ctx2, finish2 := r.Start(context.Background(), RunningJob{JobID: "j"}) // Same ID, tracked separately
+	_, finish3 := r.Start(context.Background(), RunningJob{JobID: "k"})
+	if r.Len() != 3 {
+		t.Fatalf("Len = %d, want 3", r.Len())
+	}
+
+	if _, ok := r.Cancel("j"); !ok {
+		t.Fatal("Cancel(j) found nothing")
+	}
+	if !Cancelled(ctx1) || !Cancelled(ctx2) {
+		t.Error("both jobs named j should be cancelled")
+	}
+	finish1()
+	finish2()
+	finish2() // Finishing twice is harmless
+
+	waitCtx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := r.Wait(waitCtx); err == nil {
+		t.Error("Wait returned while k was still running")
+	}
+
+	interrupted := r.Interrupt()
+	if len(interrupted) != 1 || interrupted[0].JobID != "k" {
+		t.Errorf("Interrupt = %+v, want k", interrupted)
+	}
+	finish3()
+	if err := r.Wait(context.Background()); err != nil {
+		t.Errorf("Wait with no jobs = %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dasmlab/nanabush/server/pkg/proto/v1"
+)
+
+// ErrCancelled is the cancellation cause of a job cancelled with Running.Cancel.
+var ErrCancelled = errors.New("job cancelled by an administrator")
+
+// ErrShutdown is the cancellation cause of jobs interrupted with Running.Interrupt.
+var ErrShutdown = errors.New("server shut down before the job finished")
+
+// RunningJob is a job that is queued for or running on the backend.
+type RunningJob struct {
+	JobID     string
+	Namespace string
+	ClientID  string
+	Primitive nanabushv1.PrimitiveType
+
+	SourceLanguage string
+	TargetLanguage string
+
+	StartedAt time.Time
+}
+
+// Running tracks in-flight jobs so they can be listed and cancelled.
+type Running struct {
+	mu   sync.Mutex
+	jobs map[string]*runningEntry
+	idle chan struct{} // Closed while no jobs are running
+}
+
+type runningEntry struct {
+	job    RunningJob
+	cancel context.CancelCauseFunc
+}
+
+// NewRunning creates an empty tracker.
+func NewRunning() *Running {
+	idle := make(chan struct{})
+	close(idle)
+	return &Running{
+		jobs: make(map[string]*runningEntry),
+		idle: idle,
+	}
+}
+
+// Start tracks a job until the returned finish function is called. The
+// returned context is cancelled with ErrCancelled if the job is cancelled.
+// A job ID that is already running is tracked under a unique suffix so the
+// earlier job can still be found.
+func (r *Running) Start(ctx context.Context, job RunningJob) (context.Context, func()) {
+	if job.StartedAt.IsZero() {
+		job.StartedAt = time.Now()
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	r.mu.Lock()
+	key := job.JobID
+	for i := 2; r.jobs[key] != nil; i++ {
+		key = job.JobID + "#" + strconv.Itoa(i)
+	}
+	if len(r.jobs) == 0 {
+		r.idle = make(chan struct{})
+	}
+	r.jobs[key] = &runningEntry{job: job, cancel: cancel}
+	r.mu.Unlock()
+
+	var once sync.Once
+	return ctx, func() {
+		once.Do(func() {
+			cancel(nil)
+			r.mu.Lock()
+			delete(r.jobs, key)
+			if len(r.jobs) == 0 {
+				close(r.idle)
+			}
+			r.mu.Unlock()
+		})
+	}
+}
+
+// Cancel cancels every running job with the given ID and returns the first.
+func (r *Running) Cancel(jobID string) (RunningJob, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var first RunningJob
+	found := false
+	for _, entry := range r.jobs {
+		if entry.job.JobID != jobID {
+			continue
+		}
+		entry.cancel(ErrCancelled)
+		if !found || entry.job.StartedAt.Before(first.StartedAt) {
+			first = entry.job
+		}
+		found = true
+	}
+	return first, found
+}
+
+// List returns the running jobs, oldest first.
+func (r *Running) List() []RunningJob {
+	r.mu.Lock()
+	jobs := make([]RunningJob, 0, len(r.jobs))
+	for _, entry := range r.jobs {
+		jobs = append(jobs, entry.job)
+	}
+	r.mu.Unlock()
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].StartedAt.Before(jobs[j].StartedAt)
+	})
+	return jobs
+}
+
+// Len returns the number of running jobs.
+func (r *Running) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.jobs)
+}
+
+// Wait blocks until no jobs are running or ctx is done, whichever is first.
+func (r *Running) Wait(ctx context.Context) error {
+	r.mu.Lock()
+	idle := r.idle
+	r.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Interrupt cancels every running job with ErrShutdown and returns them,
+// oldest first. The jobs stay tracked until their callers finish them.
+func (r *Running) Interrupt() []RunningJob {
+	r.mu.Lock()
+	for _, entry := range r.jobs {
+		entry.cancel(ErrShutdown)
+	}
+	r.mu.Unlock()
+	return r.List()
+}
+
+// Cancelled reports whether ctx (or a parent) was cancelled by Running.Cancel.
+func Cancelled(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), ErrCancelled)
+}
+
+// Interrupted reports whether ctx (or a parent) was cancelled by Running.Interrupt.
+func Interrupted(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), ErrShutdown)
+}
@@ -0,0 +1,259 @@
+//go:build ignore
+
+// gen writes the language profiles in profiles/. Only the ranked n-grams are
+// written, not the training text.
+//
+// The committed profiles are built from the translated messages of the GNU
+// gettext catalogs a Debian system installs under /usr/share/locale (see
+// CORPUS.md for the packages and their licenses); English comes from the
+// catalogs' source strings:
+//
+//	go run gen.go -locale /usr/share/locale [-heldout /tmp/heldout]
+//
+// Every tenth message is left out of training; -heldout writes those messages
+// to <dir>/<lang>.txt for measuring accuracy. A corpus of plain text files
+// named after the language (fr.txt, de.txt, ...) can be used instead:
+//
+//	go run gen.go -corpus /path/to/corpus [-out profiles]
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dasmlab/nanabush/server/pkg/langid"
+)
+
+// locales maps each profile to the gettext locale directories it is built from.
+var locales = map[string][]string{
+	"de": {"de"},
+	"es": {"es"},
+	"fr": {"fr"},
+	"it": {"it"},
+	"nl": {"nl"},
+	"pt": {"pt", "pt_BR"},
+}
+
+// skipCatalog matches catalogs of names (countries, languages, time zones,
+// keyboard layouts), which are lists rather than sentences.
+var skipCatalog = regexp.MustCompile(`iso_|iso-|country|currency|language|tzdata|timezone`)
+
+// markup matches printf verbs, placeholders, tags, entities, escapes, URLs,
+// command-line options and paths, which are removed from messages.
+var markup = regexp.MustCompile(`%[-+ #0]*\d*(?:\.\d+)?[a-zA-Z]+|\{[^}]*\}|\$\{?\w+\}?|<[^>]+>|&\w+;|_|\\n|https?://\S+|--?[a-z][-a-z]*|\S*[/\\]\S*`)
+
+var (
+	letters = regexp.MustCompile(`\p{L}+`)
+	spaces  = regexp.MustCompile(`\s+`)
+)
+
+func main() {
+	corpus := flag.String("corpus", "", "Directory of <lang>.txt training text")
+	locale := flag.String("locale", "", "Directory of gettext catalogs, <locale>/LC_MESSAGES/*.mo")
+	heldout := flag.String("heldout", "", "With -locale, directory to write the held-out messages to")
+	out := flag.String("out", "profiles", "Directory to write the profiles to")
+	flag.Parse()
+
+	var texts map[string]string
+	var err error
+	switch {
+	case *locale != "":
+		texts, err = catalogText(*locale, *heldout)
+	case *corpus != "":
+		texts, err = corpusText(*corpus)
+	default:
+		err = errors.New("-locale or -corpus is required")
+	}
+	if err != nil {
+		log.Fatalf("gen: %v", err)
+	}
+
+	langs := make([]string, 0, len(texts))
+	for lang := range texts {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	for _, lang := range langs {
+		grams := langid.Profile(texts[lang])
+		var b strings.Builder
+		fmt.Fprintf(&b, "# Generated by gen.go from %d bytes of text; do not edit.\n", len(texts[lang]))
+		for _, gram := range grams {
+			b.WriteString(gram)
+			b.WriteByte('\n')
+		}
+		dest := filepath.Join(*out, lang+".txt")
+		if err := os.WriteFile(dest, []byte(b.String()), 0o644); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("gen: wrote %s (%d n-grams)", dest, len(grams))
+	}
+}
+
+// corpusText reads <dir>/<lang>.txt files.
+func corpusText(dir string) (map[string]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.txt"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no .txt files in %s", dir)
+	}
+	texts := make(map[string]string, len(files))
+	for _, file := range files {
+		text, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		texts[strings.TrimSuffix(filepath.Base(file), ".txt")] = string(text)
+	}
+	return texts, nil
+}
+
+// catalogText collects the usable messages of the gettext catalogs under dir,
+// per language, leaving every tenth out (written to heldout when set).
+func catalogText(dir, heldout string) (map[string]string, error) {
+	messages := map[string]map[string]bool{"en": {}}
+	for lang, names := range locales {
+		messages[lang] = make(map[string]bool)
+		for _, name := range names {
+			files, err := filepath.Glob(filepath.Join(dir, name, "LC_MESSAGES", "*.mo"))
+			if err != nil {
+				return nil, err
+			}
+			for _, file := range files {
+				if skipCatalog.MatchString(filepath.Base(file)) {
+					continue
+				}
+				pairs, err := readCatalog(file)
+				if err != nil {
+					log.Printf("gen: skipping %s: %v", file, err)
+					continue
+				}
+				for _, pair := range pairs {
+					source, translation := clean(pair[0]), clean(pair[1])
+					if usable(translation) && translation != source {
+						messages[lang][translation] = true
+					}
+					if usable(source) {
+						messages["en"][source] = true
+					}
+				}
+			}
+		}
+		if len(messages[lang]) == 0 {
+			return nil, fmt.Errorf("no %s catalogs under %s", lang, dir)
+		}
+	}
+
+	texts := make(map[string]string, len(messages))
+	for lang, set := range messages {
+		sorted := make([]string, 0, len(set))
+		for message := range set {
+			sorted = append(sorted, message)
+		}
+		sort.Strings(sorted)
+		var train, held []string
+		for i, message := range sorted {
+			if i%10 == 9 {
+				held = append(held, message)
+			} else {
+				train = append(train, message)
+			}
+		}
+		texts[lang] = strings.Join(train, "\n")
+		if heldout != "" {
+			if err := os.WriteFile(filepath.Join(heldout, lang+".txt"), []byte(strings.Join(held, "\n")), 0o644); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return texts, nil
+}
+
+// clean removes markup from a message and collapses its whitespace.
+func clean(message string) string {
+	return strings.TrimSpace(spaces.ReplaceAllString(markup.ReplaceAllString(message, " "), " "))
+}
+
+// usable reports whether a cleaned message has enough words to be prose.
+func usable(message string) bool {
+	words := letters.FindAllString(message, -1)
+	chars := 0
+	for _, word := range words {
+		chars += utf8.RuneCountInString(word)
+	}
+	return len(words) >= 4 && chars >= 20
+}
+
+// readCatalog returns the (source, translation) pairs of a compiled gettext
+// catalog. Each plural form is paired with the singular source; messages
+// with a context have it stripped, and the header entry is skipped.
+func readCatalog(file string) ([][2]string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 20 {
+		return nil, errors.New("too short for a catalog")
+	}
+	var order binary.ByteOrder
+	switch binary.LittleEndian.Uint32(data) {
+	case 0x950412de:
+		order = binary.LittleEndian
+	case 0xde120495:
+		order = binary.BigEndian
+	default:
+		return nil, errors.New("not a gettext catalog")
+	}
+	count := int(order.Uint32(data[8:]))
+	sources, translations := int(order.Uint32(data[12:])), int(order.Uint32(data[16:]))
+
+	entry := func(table, i int) ([]byte, error) {
+		at := table + 8*i
+		if at < 0 || at+8 > len(data) {
+			return nil, errors.New("string table out of range")
+		}
+		length, offset := int(order.Uint32(data[at:])), int(order.Uint32(data[at+4:]))
+		if offset < 0 || length < 0 || offset+length > len(data) {
+			return nil, errors.New("string out of range")
+		}
+		return data[offset : offset+length], nil
+	}
+
+	var pairs [][2]string
+	for i := 0; i < count; i++ {
+		source, err := entry(sources, i)
+		if err != nil {
+			return nil, err
+		}
+		translation, err := entry(translations, i)
+		if err != nil {
+			return nil, err
+		}
+		if i := bytes.IndexByte(source, 4); i >= 0 {
+			source = source[i+1:] // Context
+		}
+		if i := bytes.IndexByte(source, 0); i >= 0 {
+			source = source[:i] // Plural source
+		}
+		if len(source) == 0 || !utf8.Valid(source) || !utf8.Valid(translation) {
+			continue
+		}
+		for _, form := range bytes.Split(translation, []byte{0}) {
+			if len(form) > 0 {
+				pairs = append(pairs, [2]string{string(source), string(form)})
+			}
+		}
+	}
+	return pairs, nil
+}
@@ -0,0 +1,236 @@
+// Package langid identifies the language of text from character n-gram profiles.
+//
+// The profiles in profiles/ list each language's most frequent n-grams, one per
+// line, most frequent first. They are generated from the translations in
+// Debian's gettext message catalogs (see CORPUS.md for the packages and
+// their licenses):
+//
+//	go run gen.go -locale /usr/share/locale
+package langid
+
+import (
+	"bufio"
+	"embed"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// profileSize is the number of top-ranked n-grams kept per language.
+const profileSize = 3000
+
+// maxGram is the longest n-gram counted.
+const maxGram = 4
+
+// minLetters is the amount of text needed for full confidence.
+const minLetters = 40
+
+// profiles holds the ranked n-grams of each language.
+//
+//go:embed profiles/*.txt
+var profiles embed.FS
+
+// Result is the outcome of language identification.
+type Result struct {
+	// Language is the ISO 639-1 code of the best match ("und" when nothing matched).
+	Language string
+
+	// Confidence is in [0, 1]: how clearly the best match beats the runner-up,
+	// scaled down for very short input.
+	Confidence float64
+}
+
+// Undetermined is returned as Result.Language when no language could be identified.
+const Undetermined = "und"
+
+// Identifier ranks text against character n-gram profiles using the
+// Cavnar-Trenkle out-of-place measure. It is safe for concurrent use.
+type Identifier struct {
+	languages []string
+	ranks     map[string]map[string]int
+}
+
+// New builds an Identifier from the profiles embedded in the binary.
+func New() (*Identifier, error) {
+	entries, err := profiles.ReadDir("profiles")
+	if err != nil {
+		return nil, err
+	}
+
+	id := &Identifier{ranks: make(map[string]map[string]int, len(entries))}
+	for _, entry := range entries {
+		text, err := profiles.ReadFile(path.Join("profiles", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		lang := strings.TrimSuffix(entry.Name(), path.Ext(entry.Name()))
+		id.languages = append(id.languages, lang)
+		id.ranks[lang] = parseProfile(string(text))
+	}
+	sort.Strings(id.languages)
+	return id, nil
+}
+
+// Profile returns the top-ranked n-grams of training text, most frequent
+// first, in the format of the embedded profiles.
+func Profile(text string) []string {
+	return ranked(ngrams(text))
+}
+
+// parseProfile reads a profile, one n-gram per line in rank order.
+// Blank lines and lines starting with '#' are skipped.
+func parseProfile(text string) map[string]int {
+	ranks := make(map[string]int, profileSize)
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		gram := strings.TrimSpace(scanner.Text())
+		if gram == "" || strings.HasPrefix(gram, "#") {
+			continue
+		}
+		if _, dup := ranks[gram]; !dup && len(ranks) < profileSize {
+			ranks[gram] = len(ranks)
+		}
+	}
+	return ranks
+}
+
+// Languages returns the languages the identifier knows, sorted.
+func (id *Identifier) Languages() []string {
+	return append([]string(nil), id.languages...)
+}
+
+// Knows reports whether the identifier can recognise lang ("fr-CA" counts as "fr").
+func (id *Identifier) Knows(lang string) bool {
+	_, ok := id.ranks[PrimarySubtag(lang)]
+	return ok
+}
+
+// PrimarySubtag returns the lowercased primary language subtag of a tag ("fr" for "fr-CA").
+func PrimarySubtag(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	return tag
+}
+
+// SameLanguage reports whether two tags share a primary language subtag ("fr" and "fr-CA").
+func SameLanguage(a, b string) bool {
+	return PrimarySubtag(a) == PrimarySubtag(b)
+}
+
+// Detect identifies the language of text.
+func (id *Identifier) Detect(text string) Result {
+	counts := ngrams(text)
+	if len(counts) == 0 {
+		return Result{Language: Undetermined}
+	}
+	doc := rank(counts)
+
+	best, second := "", ""
+	bestDist, secondDist := -1, -1
+	for _, lang := range id.languages {
+		d := distance(doc, id.ranks[lang])
+		switch {
+		case bestDist < 0 || d < bestDist:
+			second, secondDist = best, bestDist
+			best, bestDist = lang, d
+		case secondDist < 0 || d < secondDist:
+			second, secondDist = lang, d
+		}
+	}
+	if best == "" {
+		return Result{Language: Undetermined}
+	}
+
+	confidence := 1.0
+	if second != "" && secondDist > 0 {
+		// Relative margin over the runner-up; a 25% margin is a clear win.
+		confidence = float64(secondDist-bestDist) / float64(secondDist) * 4
+	}
+	if letters := countLetters(text); letters < minLetters {
+		confidence *= float64(letters) / minLetters
+	}
+	if confidence > 1 {
+		confidence = 1
+	}
+	return Result{Language: best, Confidence: confidence}
+}
+
+// ngrams counts the 1- to maxGram-character n-grams of each word, padded with '_'.
+func ngrams(text string) map[string]int {
+	counts := make(map[string]int)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for _, word := range words {
+		runes := []rune("_" + word + "_")
+		for n := 1; n <= maxGram; n++ {
+			for i := 0; i+n <= len(runes); i++ {
+				gram := string(runes[i : i+n])
+				if gram == "_" {
+					continue
+				}
+				counts[gram]++
+			}
+		}
+	}
+	return counts
+}
+
+// rank orders n-grams by frequency and returns their rank, keeping the top profileSize.
+func rank(counts map[string]int) map[string]int {
+	grams := ranked(counts)
+	ranks := make(map[string]int, len(grams))
+	for i, g := range grams {
+		ranks[g] = i
+	}
+	return ranks
+}
+
+// ranked returns the top profileSize n-grams, most frequent first.
+func ranked(counts map[string]int) []string {
+	grams := make([]string, 0, len(counts))
+	for g := range counts {
+		grams = append(grams, g)
+	}
+	sort.Slice(grams, func(i, j int) bool {
+		if counts[grams[i]] != counts[grams[j]] {
+			return counts[grams[i]] > counts[grams[j]]
+		}
+		return grams[i] < grams[j]
+	})
+	if len(grams) > profileSize {
+		grams = grams[:profileSize]
+	}
+	return grams
+}
+
+// distance is the out-of-place measure between a document and a language profile.
+func distance(doc, profile map[string]int) int {
+	total := 0
+	for gram, docRank := range doc {
+		profileRank, ok := profile[gram]
+		if !ok {
+			total += profileSize
+			continue
+		}
+		if d := docRank - profileRank; d < 0 {
+			total -= d
+		} else {
+			total += d
+		}
+	}
+	return total
+}
+
+func countLetters(text string) int {
+	n := 0
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,144 @@
+package langid
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	id, err := New()
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"de sentence", "Bitte aktualisieren Sie die Seite, bevor Sie den Dienst neu starten.", "de"},
+		{"en sentence", "Please update the page before you restart the service.", "en"},
+		{"es sentence", "Actualice la página antes de reiniciar el servicio, por favor.", "es"},
+		{"fr sentence", "Veuillez mettre à jour la page avant de redémarrer le service.", "fr"},
+		{"it sentence", "Aggiorna la pagina prima di riavviare il servizio, per favore.", "it"},
+		{"nl sentence", "Werk de pagina bij voordat je de dienst opnieuw start.", "nl"},
+		{"pt sentence", "Atualize a página antes de reiniciar o serviço, por favor.", "pt"},
+		{"fr paragraph", "Cette page explique comment déployer une nouvelle version de l'application. " +
+			"Avant de commencer, vérifiez que les tests passent et que la documentation est à jour. " +
+			"En cas de problème, contactez l'équipe responsable de la plateforme.", "fr"},
+		{"it paragraph", "Questa pagina spiega come distribuire una nuova versione dell'applicazione. " +
+			"Prima di iniziare, verifica che i test siano superati e che la documentazione sia aggiornata. " +
+			"In caso di problemi, contatta il gruppo responsabile della piattaforma.", "it"},
+		{"de with code", "Führen Sie `kubectl rollout restart deployment/api` aus, wenn die Konfiguration geändert wurde.", "de"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := id.Detect(tt.text)
+			if got.Language != tt.want {
+				t.Fatalf("Detect = %q (%.2f), want %q", got.Language, got.Confidence, tt.want)
+			}
+			if got.Confidence < 0.3 {
+				t.Errorf("Detect confidence = %.2f, want at least 0.3", got.Confidence)
+			}
+		})
+	}
+}
+
+func TestDetectUndetermined(t *testing.T) {
+	id, err := New()
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	for _, text := range []string{"", "   ", "1234 5678", "--> :: {}"} {
+		if got := id.Detect(text); got.Language != Undetermined || got.Confidence != 0 {
+			t.Errorf("Detect(%q) = %+v, want %q", text, got, Undetermined)
+		}
+	}
+}
+
+func TestDetectShortTextLowersConfidence(t *testing.T) {
+	id, err := New()
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	short := id.Detect("le service")
+	long := id.Detect("Veuillez mettre à jour la page avant de redémarrer le service.")
+	if short.Confidence >= long.Confidence {
+		t.Fatalf("confidence for 9 letters = %.2f, want below %.2f for a full sentence", short.Confidence, long.Confidence)
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	id, err := New()
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	want := []string{"de", "en", "es", "fr", "it", "nl", "pt"}
+	if got := id.Languages(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("Languages = %v, want %v", got, want)
+	}
+	for _, lang := range want {
+		if n := len(id.ranks[lang]); n != profileSize {
+			t.Errorf("profile %s has %d n-grams, want %d", lang, n, profileSize)
+		}
+	}
+}
+
+func TestParseProfile(t *testing.T) {
+	ranks := parseProfile("# header\ne\n\n_d\ne\nde_\n")
+	want := map[string]int{"e": 0, "_d": 1, "de_": 2}
+	if len(ranks) != len(want) {
+		t.Fatalf("parseProfile = %v, want %v", ranks, want)
+	}
+	for gram, r := range want {
+		if ranks[gram] != r {
+			t.Errorf("rank of %q = %d, want %d", gram, ranks[gram], r)
+		}
+	}
+}
+
+func TestProfileMatchesRank(t *testing.T) {
+	text := "the cat sat on the mat with the other cat"
+	grams := Profile(text)
+	ranks := rank(ngrams(text))
+	if len(grams) != len(ranks) {
+		t.Fatalf("Profile has %d n-grams, rank has %d", len(grams), len(ranks))
+	}
+	for i, gram := range grams {
+		if ranks[gram] != i {
+			t.Errorf("Profile[%d] = %q, rank %d", i, gram, ranks[gram])
+		}
+	}
+	if grams[0] != "t" {
+		t.Errorf("most frequent n-gram = %q, want %q", grams[0], "t")
+	}
+}
+
+func TestLanguageTags(t *testing.T) {
+	id, err := New()
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	tests := []struct {
+		a, b    string
+		primary string
+		same    bool
+		known   bool
+	}{
+		{"fr", "fr-CA", "fr", true, true},
+		{" EN_us", "en-GB", "en", true, true},
+		{"pt-BR", "es", "pt", false, true},
+		{"ja", "ja", "ja", true, false},
+	}
+	for _, tt := range tests {
+		if got := PrimarySubtag(tt.a); got != tt.primary {
+			t.Errorf("PrimarySubtag(%q) = %q, want %q", tt.a, got, tt.primary)
+		}
+		if got := SameLanguage(tt.a, tt.b); got != tt.same {
+			t.Errorf("SameLanguage(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.same)
+		}
+		if got := id.Knows(tt.a); got != tt.known {
+			t.Errorf("Knows(%q) = %v, want %v", tt.a, got, tt.known)
+		}
+	}
+}
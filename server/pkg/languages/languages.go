@@ -0,0 +1,158 @@
+package languages
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/language/display"
+)
+
+// DefaultPairs is the pair specification used when none is configured:
+// English to and from the languages the server can also auto-detect.
+const DefaultPairs = "en:de,es,fr,it,nl,pt;de:en;es:en;fr:en;it:en;nl:en;pt:en"
+
+// Pair is a supported source/target combination.
+type Pair struct {
+	Source language.Tag
+	Target language.Tag
+}
+
+// String formats the pair as "source->target".
+func (p Pair) String() string {
+	return p.Source.String() + "->" + p.Target.String()
+}
+
+// Info describes a language for discovery.
+type Info struct {
+	Tag  string
+	Name string
+}
+
+// Registry holds the supported language pairs and resolves requested tags
+// against them, falling back to parent tags (fr-CA -> fr) when needed.
+type Registry struct {
+	pairs   []Pair
+	targets map[language.Tag]map[language.Tag]bool
+}
+
+// ParseTag parses and canonicalizes a BCP 47 tag ("EN" -> "en", "fr_ca" -> "fr-CA").
+func ParseTag(tag string) (language.Tag, error) {
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return language.Und, fmt.Errorf("language tag is empty")
+	}
+	t, err := language.Parse(tag)
+	if err != nil {
+		return language.Und, fmt.Errorf("invalid BCP 47 language tag %q: %v", tag, err)
+	}
+	if t == language.Und {
+		return language.Und, fmt.Errorf("language tag %q is undetermined", tag)
+	}
+	return t, nil
+}
+
+// ParsePairs parses a pair specification of the form
+// "source:target,target;source:target", e.g. "en:fr,fr-CA,es;fr:en".
+func ParsePairs(spec string) ([]Pair, error) {
+	var pairs []Pair
+	for _, group := range strings.Split(spec, ";") {
+		group = strings.TrimSpace(group)
+		if group == "" {
+			continue
+		}
+		src, targets, ok := strings.Cut(group, ":")
+		if !ok {
+			return nil, fmt.Errorf("expected source:target[,target...], got %q", group)
+		}
+		source, err := ParseTag(src)
+		if err != nil {
+			return nil, err
+		}
+		for _, tgt := range strings.Split(targets, ",") {
+			target, err := ParseTag(tgt)
+			if err != nil {
+				return nil, err
+			}
+			pairs = append(pairs, Pair{Source: source, Target: target})
+		}
+	}
+	if len(pairs) == 0 {
+		return nil, fmt.Errorf("no language pairs in %q", spec)
+	}
+	return pairs, nil
+}
+
+// NewRegistry creates a registry supporting the given pairs.
+func NewRegistry(pairs []Pair) *Registry {
+	r := &Registry{targets: make(map[language.Tag]map[language.Tag]bool)}
+	for _, p := range pairs {
+		if r.targets[p.Source] == nil {
+			r.targets[p.Source] = make(map[language.Tag]bool)
+		}
+		if r.targets[p.Source][p.Target] {
+			continue
+		}
+		r.targets[p.Source][p.Target] = true
+		r.pairs = append(r.pairs, p)
+	}
+	sort.Slice(r.pairs, func(i, j int) bool {
+		return r.pairs[i].String() < r.pairs[j].String()
+	})
+	return r
+}
+
+// Default returns a registry for DefaultPairs.
+func Default() *Registry {
+	pairs, err := ParsePairs(DefaultPairs)
+	if err != nil {
+		panic(err)
+	}
+	return NewRegistry(pairs)
+}
+
+// Resolve maps a requested source and target onto a supported pair, walking
+// each tag's parents until a configured pair is found.
+func (r *Registry) Resolve(source, target language.Tag) (Pair, error) {
+	for src := source; ; src = src.Parent() {
+		if targets, ok := r.targets[src]; ok {
+			for tgt := target; ; tgt = tgt.Parent() {
+				if targets[tgt] {
+					return Pair{Source: src, Target: tgt}, nil
+				}
+				if tgt == language.Und {
+					break
+				}
+			}
+		}
+		if src == language.Und {
+			break
+		}
+	}
+	return Pair{}, fmt.Errorf("language pair %s->%s is not supported", source, target)
+}
+
+// Pairs returns the supported pairs, sorted.
+func (r *Registry) Pairs() []Pair {
+	return append([]Pair(nil), r.pairs...)
+}
+
+// Languages returns every language appearing in a supported pair, with its English name.
+func (r *Registry) Languages() []Info {
+	seen := make(map[language.Tag]bool)
+	var infos []Info
+	add := func(t language.Tag) {
+		if seen[t] {
+			return
+		}
+		seen[t] = true
+		infos = append(infos, Info{Tag: t.String(), Name: display.English.Tags().Name(t)})
+	}
+	for _, p := range r.pairs {
+		add(p.Source)
+		add(p.Target)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Tag < infos[j].Tag })
+	return infos
+}
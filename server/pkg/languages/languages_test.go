@@ -0,0 +1,140 @@
+package languages
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+func TestParseTag(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"en", "en", false},
+		{" EN ", "en", false},
+		{"fr_ca", "fr-CA", false},
+		{"pt-br", "pt-BR", false},
+		{"zh-Hant-TW", "zh-Hant-TW", false},
+		{"", "", true},
+		{"und", "", true},
+		{"not a tag", "", true},
+		{"e", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseTag(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTag(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got.String() != tt.want {
+			t.Errorf("ParseTag(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParsePairs(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    string
+		wantErr bool
+	}{
+		{"en:fr", "en->fr", false},
+		{"en:fr,fr-CA, es ; fr:en;", "en->fr en->fr-CA en->es fr->en", false},
+		{"EN:FR_ca", "en->fr-CA", false},
+		{"", "", true},
+		{" ; ", "", true},
+		{"en", "", true},
+		{"en:", "", true},
+		{"en:fr,,de", "", true},
+		{"xx-!!:fr", "", true},
+	}
+	for _, tt := range tests {
+		pairs, err := ParsePairs(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParsePairs(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		got := make([]string, len(pairs))
+		for i, p := range pairs {
+			got[i] = p.String()
+		}
+		if strings.Join(got, " ") != tt.want {
+			t.Errorf("ParsePairs(%q) = %v, want %s", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	pairs, err := ParsePairs("en:fr,fr-CA,es;fr:en")
+	if err != nil {
+		t.Fatalf("ParsePairs: %v", err)
+	}
+	r := NewRegistry(pairs)
+
+	tests := []struct {
+		source, target string
+		want           string
+		wantErr        bool
+	}{
+		{"en", "fr", "en->fr", false},
+		{"en", "fr-CA", "en->fr-CA", false},
+		{"en-GB", "fr-BE", "en->fr", false},
+		{"en-US", "es-MX", "en->es", false},
+		{"fr-CA", "en-US", "fr->en", false},
+		{"fr", "es", "", true},
+		{"de", "en", "", true},
+	}
+	for _, tt := range tests {
+		got, err := r.Resolve(language.MustParse(tt.source), language.MustParse(tt.target))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Resolve(%s, %s) error = %v, wantErr %v", tt.source, tt.target, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got.String() != tt.want {
+			t.Errorf("Resolve(%s, %s) = %s, want %s", tt.source, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	pairs, err := ParsePairs("fr:en;en:fr,es;en:fr")
+	if err != nil {
+		t.Fatalf("ParsePairs: %v", err)
+	}
+	r := NewRegistry(pairs)
+
+	var got []string
+	for _, p := range r.Pairs() {
+		got = append(got, p.String())
+	}
+	if want := "en->es en->fr fr->en"; strings.Join(got, " ") != want {
+		t.Errorf("Pairs = %v, want %s (deduplicated and sorted)", got, want)
+	}
+
+	want := []Info{{"en", "English"}, {"es", "Spanish"}, {"fr", "French"}}
+	infos := r.Languages()
+	if len(infos) != len(want) {
+		t.Fatalf("Languages = %v, want %v", infos, want)
+	}
+	for i := range want {
+		if infos[i] != want[i] {
+			t.Errorf("Languages[%d] = %v, want %v", i, infos[i], want[i])
+		}
+	}
+}
+
+func TestDefault(t *testing.T) {
+	r := Default()
+	if n := len(r.Pairs()); n != 12 {
+		t.Errorf("default registry has %d pairs, want 12", n)
+	}
+	if _, err := r.Resolve(language.MustParse("en"), language.MustParse("fr-CA")); err != nil {
+		t.Errorf("Resolve(en, fr-CA) on the default registry: %v", err)
+	}
+}
@@ -20,20 +20,54 @@ type TranslationServiceClient interface {
 	// RegisterClient registers a new client with the server.
 	// This should be called immediately after establishing a connection.
 	// Returns a client_id that should be used for subsequent heartbeats.
+	// Client versions refused by the server's version policy get
+	// FAILED_PRECONDITION; deprecated versions register with a warning in
+	// message and the nanabush-version-warning response header.
 	RegisterClient(ctx context.Context, in *RegisterClientRequest, opts ...grpc.CallOption) (*RegisterClientResponse, error)
 	// Heartbeat sends a keepalive and re-authentication signal from the client.
 	// Should be called periodically (recommended: every 30-60 seconds).
 	// If the socket is torn down, the client should re-register.
 	Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error)
+	// Session is a long-lived alternative to polling Heartbeat. The client opens
+	// it after RegisterClient and sends SessionHello; the client stays alive for
+	// as long as the stream is open and is marked inactive as soon as it closes.
+	// The server pushes directives when they change and job completions as
+	// they happen. Clients that do not use Session keep calling Heartbeat.
+	Session(ctx context.Context, opts ...grpc.CallOption) (TranslationService_SessionClient, error)
 	// CheckTitle performs a lightweight pre-flight check with title only.
 	// This validates that Nanabush is ready and can handle the request.
 	CheckTitle(ctx context.Context, in *TitleCheckRequest, opts ...grpc.CallOption) (*TitleCheckResponse, error)
 	// Translate performs full document translation.
 	// This is the main translation endpoint that processes complete documents.
 	Translate(ctx context.Context, in *TranslateRequest, opts ...grpc.CallOption) (*TranslateResponse, error)
+	// SubmitTranslate queues a translation and returns once it is accepted,
+	// without waiting for the result. callback_url is required: when the job
+	// finishes the server POSTs a signed JobCallback there (see JobCallback).
+	// Errors found up front are returned directly; later ones go to the callback.
+	SubmitTranslate(ctx context.Context, in *TranslateRequest, opts ...grpc.CallOption) (*SubmitTranslateResponse, error)
 	// TranslateStream supports streaming for large documents.
 	// Client sends chunks, server responds with translated chunks.
 	TranslateStream(ctx context.Context, opts ...grpc.CallOption) (TranslationService_TranslateStreamClient, error)
+	// TranslateCollection translates the pages of one collection together.
+	// Terms that recur across pages are extracted and translated first into a
+	// glossary, every page is translated with it, and the response reports how
+	// consistently each term was rendered.
+	TranslateCollection(ctx context.Context, in *TranslateCollectionRequest, opts ...grpc.CallOption) (*TranslateCollectionResponse, error)
+	// TranslateBatch translates many titles or documents in one call.
+	// Items are scheduled together so the backend can batch them; results are
+	// returned in request order and each item succeeds or fails independently.
+	TranslateBatch(ctx context.Context, in *TranslateBatchRequest, opts ...grpc.CallOption) (*TranslateBatchResponse, error)
+	// ListLanguages returns the supported language pairs.
+	// Requests for other pairs are rejected with INVALID_ARGUMENT.
+	ListLanguages(ctx context.Context, in *ListLanguagesRequest, opts ...grpc.CallOption) (*ListLanguagesResponse, error)
+	// GetCapabilities describes what this server supports so clients can
+	// adapt instead of hardcoding assumptions.
+	GetCapabilities(ctx context.Context, in *GetCapabilitiesRequest, opts ...grpc.CallOption) (*GetCapabilitiesResponse, error)
+	// SubmitFeedback records a human review of a completed job. The server pairs
+	// it with the job's source and model output, scrubs PII, and appends it to
+	// the retraining dataset. Only the client that submitted the job, or one
+	// registered in its namespace, may review it, and only once.
+	SubmitFeedback(ctx context.Context, in *SubmitFeedbackRequest, opts ...grpc.CallOption) (*SubmitFeedbackResponse, error)
 }
 
 type translationServiceClient struct {
@@ -62,6 +96,37 @@ func (c *translationServiceClient) Heartbeat(ctx context.Context, in *HeartbeatR
 	return out, nil
 }
 
+func (c *translationServiceClient) Session(ctx context.Context, opts ...grpc.CallOption) (TranslationService_SessionClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_TranslationService_serviceDesc.Streams[0], "/nanabush.v1.TranslationService/Session", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &translationServiceSessionClient{stream}
+	return x, nil
+}
+
+type TranslationService_SessionClient interface {
+	Send(*SessionRequest) error
+	Recv() (*SessionEvent, error)
+	grpc.ClientStream
+}
+
+type translationServiceSessionClient struct {
+	grpc.ClientStream
+}
+
+func (x *translationServiceSessionClient) Send(m *SessionRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *translationServiceSessionClient) Recv() (*SessionEvent, error) {
+	m := new(SessionEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *translationServiceClient) CheckTitle(ctx context.Context, in *TitleCheckRequest, opts ...grpc.CallOption) (*TitleCheckResponse, error) {
 	out := new(TitleCheckResponse)
 	err := c.cc.Invoke(ctx, "/nanabush.v1.TranslationService/CheckTitle", in, out, opts...)
@@ -80,8 +145,17 @@ func (c *translationServiceClient) Translate(ctx context.Context, in *TranslateR
 	return out, nil
 }
 
+func (c *translationServiceClient) SubmitTranslate(ctx context.Context, in *TranslateRequest, opts ...grpc.CallOption) (*SubmitTranslateResponse, error) {
+	out := new(SubmitTranslateResponse)
+	err := c.cc.Invoke(ctx, "/nanabush.v1.TranslationService/SubmitTranslate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *translationServiceClient) TranslateStream(ctx context.Context, opts ...grpc.CallOption) (TranslationService_TranslateStreamClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_TranslationService_serviceDesc.Streams[0], "/nanabush.v1.TranslationService/TranslateStream", opts...)
+	stream, err := c.cc.NewStream(ctx, &_TranslationService_serviceDesc.Streams[1], "/nanabush.v1.TranslationService/TranslateStream", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -111,6 +185,51 @@ func (x *translationServiceTranslateStreamClient) Recv() (*TranslateChunk, error
 	return m, nil
 }
 
+func (c *translationServiceClient) TranslateCollection(ctx context.Context, in *TranslateCollectionRequest, opts ...grpc.CallOption) (*TranslateCollectionResponse, error) {
+	out := new(TranslateCollectionResponse)
+	err := c.cc.Invoke(ctx, "/nanabush.v1.TranslationService/TranslateCollection", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *translationServiceClient) TranslateBatch(ctx context.Context, in *TranslateBatchRequest, opts ...grpc.CallOption) (*TranslateBatchResponse, error) {
+	out := new(TranslateBatchResponse)
+	err := c.cc.Invoke(ctx, "/nanabush.v1.TranslationService/TranslateBatch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *translationServiceClient) ListLanguages(ctx context.Context, in *ListLanguagesRequest, opts ...grpc.CallOption) (*ListLanguagesResponse, error) {
+	out := new(ListLanguagesResponse)
+	err := c.cc.Invoke(ctx, "/nanabush.v1.TranslationService/ListLanguages", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *translationServiceClient) GetCapabilities(ctx context.Context, in *GetCapabilitiesRequest, opts ...grpc.CallOption) (*GetCapabilitiesResponse, error) {
+	out := new(GetCapabilitiesResponse)
+	err := c.cc.Invoke(ctx, "/nanabush.v1.TranslationService/GetCapabilities", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *translationServiceClient) SubmitFeedback(ctx context.Context, in *SubmitFeedbackRequest, opts ...grpc.CallOption) (*SubmitFeedbackResponse, error) {
+	out := new(SubmitFeedbackResponse)
+	err := c.cc.Invoke(ctx, "/nanabush.v1.TranslationService/SubmitFeedback", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // TranslationServiceServer is the server API for TranslationService service.
 // All implementations must embed UnimplementedTranslationServiceServer
 // for forward compatibility
@@ -118,20 +237,54 @@ type TranslationServiceServer interface {
 	// RegisterClient registers a new client with the server.
 	// This should be called immediately after establishing a connection.
 	// Returns a client_id that should be used for subsequent heartbeats.
+	// Client versions refused by the server's version policy get
+	// FAILED_PRECONDITION; deprecated versions register with a warning in
+	// message and the nanabush-version-warning response header.
 	RegisterClient(context.Context, *RegisterClientRequest) (*RegisterClientResponse, error)
 	// Heartbeat sends a keepalive and re-authentication signal from the client.
 	// Should be called periodically (recommended: every 30-60 seconds).
 	// If the socket is torn down, the client should re-register.
 	Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error)
+	// Session is a long-lived alternative to polling Heartbeat. The client opens
+	// it after RegisterClient and sends SessionHello; the client stays alive for
+	// as long as the stream is open and is marked inactive as soon as it closes.
+	// The server pushes directives when they change and job completions as
+	// they happen. Clients that do not use Session keep calling Heartbeat.
+	Session(TranslationService_SessionServer) error
 	// CheckTitle performs a lightweight pre-flight check with title only.
 	// This validates that Nanabush is ready and can handle the request.
 	CheckTitle(context.Context, *TitleCheckRequest) (*TitleCheckResponse, error)
 	// Translate performs full document translation.
 	// This is the main translation endpoint that processes complete documents.
 	Translate(context.Context, *TranslateRequest) (*TranslateResponse, error)
+	// SubmitTranslate queues a translation and returns once it is accepted,
+	// without waiting for the result. callback_url is required: when the job
+	// finishes the server POSTs a signed JobCallback there (see JobCallback).
+	// Errors found up front are returned directly; later ones go to the callback.
+	SubmitTranslate(context.Context, *TranslateRequest) (*SubmitTranslateResponse, error)
 	// TranslateStream supports streaming for large documents.
 	// Client sends chunks, server responds with translated chunks.
 	TranslateStream(TranslationService_TranslateStreamServer) error
+	// TranslateCollection translates the pages of one collection together.
+	// Terms that recur across pages are extracted and translated first into a
+	// glossary, every page is translated with it, and the response reports how
+	// consistently each term was rendered.
+	TranslateCollection(context.Context, *TranslateCollectionRequest) (*TranslateCollectionResponse, error)
+	// TranslateBatch translates many titles or documents in one call.
+	// Items are scheduled together so the backend can batch them; results are
+	// returned in request order and each item succeeds or fails independently.
+	TranslateBatch(context.Context, *TranslateBatchRequest) (*TranslateBatchResponse, error)
+	// ListLanguages returns the supported language pairs.
+	// Requests for other pairs are rejected with INVALID_ARGUMENT.
+	ListLanguages(context.Context, *ListLanguagesRequest) (*ListLanguagesResponse, error)
+	// GetCapabilities describes what this server supports so clients can
+	// adapt instead of hardcoding assumptions.
+	GetCapabilities(context.Context, *GetCapabilitiesRequest) (*GetCapabilitiesResponse, error)
+	// SubmitFeedback records a human review of a completed job. The server pairs
+	// it with the job's source and model output, scrubs PII, and appends it to
+	// the retraining dataset. Only the client that submitted the job, or one
+	// registered in its namespace, may review it, and only once.
+	SubmitFeedback(context.Context, *SubmitFeedbackRequest) (*SubmitFeedbackResponse, error)
 	mustEmbedUnimplementedTranslationServiceServer()
 }
 
@@ -145,15 +298,36 @@ func (UnimplementedTranslationServiceServer) RegisterClient(context.Context, *Re
 func (UnimplementedTranslationServiceServer) Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Heartbeat not implemented")
 }
+func (UnimplementedTranslationServiceServer) Session(TranslationService_SessionServer) error {
+	return status.Errorf(codes.Unimplemented, "method Session not implemented")
+}
 func (UnimplementedTranslationServiceServer) CheckTitle(context.Context, *TitleCheckRequest) (*TitleCheckResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CheckTitle not implemented")
 }
 func (UnimplementedTranslationServiceServer) Translate(context.Context, *TranslateRequest) (*TranslateResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Translate not implemented")
 }
+func (UnimplementedTranslationServiceServer) SubmitTranslate(context.Context, *TranslateRequest) (*SubmitTranslateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitTranslate not implemented")
+}
 func (UnimplementedTranslationServiceServer) TranslateStream(TranslationService_TranslateStreamServer) error {
 	return status.Errorf(codes.Unimplemented, "method TranslateStream not implemented")
 }
+func (UnimplementedTranslationServiceServer) TranslateCollection(context.Context, *TranslateCollectionRequest) (*TranslateCollectionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TranslateCollection not implemented")
+}
+func (UnimplementedTranslationServiceServer) TranslateBatch(context.Context, *TranslateBatchRequest) (*TranslateBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TranslateBatch not implemented")
+}
+func (UnimplementedTranslationServiceServer) ListLanguages(context.Context, *ListLanguagesRequest) (*ListLanguagesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListLanguages not implemented")
+}
+func (UnimplementedTranslationServiceServer) GetCapabilities(context.Context, *GetCapabilitiesRequest) (*GetCapabilitiesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCapabilities not implemented")
+}
+func (UnimplementedTranslationServiceServer) SubmitFeedback(context.Context, *SubmitFeedbackRequest) (*SubmitFeedbackResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitFeedback not implemented")
+}
 func (UnimplementedTranslationServiceServer) mustEmbedUnimplementedTranslationServiceServer() {}
 
 // UnsafeTranslationServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -203,6 +377,32 @@ func _TranslationService_Heartbeat_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TranslationService_Session_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(TranslationServiceServer).Session(&translationServiceSessionServer{stream})
+}
+
+type TranslationService_SessionServer interface {
+	Send(*SessionEvent) error
+	Recv() (*SessionRequest, error)
+	grpc.ServerStream
+}
+
+type translationServiceSessionServer struct {
+	grpc.ServerStream
+}
+
+func (x *translationServiceSessionServer) Send(m *SessionEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *translationServiceSessionServer) Recv() (*SessionRequest, error) {
+	m := new(SessionRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func _TranslationService_CheckTitle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(TitleCheckRequest)
 	if err := dec(in); err != nil {
@@ -239,6 +439,24 @@ func _TranslationService_Translate_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TranslationService_SubmitTranslate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TranslateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TranslationServiceServer).SubmitTranslate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/nanabush.v1.TranslationService/SubmitTranslate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TranslationServiceServer).SubmitTranslate(ctx, req.(*TranslateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _TranslationService_TranslateStream_Handler(srv interface{}, stream grpc.ServerStream) error {
 	return srv.(TranslationServiceServer).TranslateStream(&translationServiceTranslateStreamServer{stream})
 }
@@ -265,6 +483,96 @@ func (x *translationServiceTranslateStreamServer) Recv() (*TranslateChunk, error
 	return m, nil
 }
 
+func _TranslationService_TranslateCollection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TranslateCollectionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TranslationServiceServer).TranslateCollection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/nanabush.v1.TranslationService/TranslateCollection",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TranslationServiceServer).TranslateCollection(ctx, req.(*TranslateCollectionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TranslationService_TranslateBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TranslateBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TranslationServiceServer).TranslateBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/nanabush.v1.TranslationService/TranslateBatch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TranslationServiceServer).TranslateBatch(ctx, req.(*TranslateBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TranslationService_ListLanguages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListLanguagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TranslationServiceServer).ListLanguages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/nanabush.v1.TranslationService/ListLanguages",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TranslationServiceServer).ListLanguages(ctx, req.(*ListLanguagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TranslationService_GetCapabilities_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCapabilitiesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TranslationServiceServer).GetCapabilities(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/nanabush.v1.TranslationService/GetCapabilities",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TranslationServiceServer).GetCapabilities(ctx, req.(*GetCapabilitiesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TranslationService_SubmitFeedback_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitFeedbackRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TranslationServiceServer).SubmitFeedback(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/nanabush.v1.TranslationService/SubmitFeedback",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TranslationServiceServer).SubmitFeedback(ctx, req.(*SubmitFeedbackRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _TranslationService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "nanabush.v1.TranslationService",
 	HandlerType: (*TranslationServiceServer)(nil),
@@ -285,8 +593,38 @@ var _TranslationService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Translate",
 			Handler:    _TranslationService_Translate_Handler,
 		},
+		{
+			MethodName: "SubmitTranslate",
+			Handler:    _TranslationService_SubmitTranslate_Handler,
+		},
+		{
+			MethodName: "TranslateCollection",
+			Handler:    _TranslationService_TranslateCollection_Handler,
+		},
+		{
+			MethodName: "TranslateBatch",
+			Handler:    _TranslationService_TranslateBatch_Handler,
+		},
+		{
+			MethodName: "ListLanguages",
+			Handler:    _TranslationService_ListLanguages_Handler,
+		},
+		{
+			MethodName: "GetCapabilities",
+			Handler:    _TranslationService_GetCapabilities_Handler,
+		},
+		{
+			MethodName: "SubmitFeedback",
+			Handler:    _TranslationService_SubmitFeedback_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Session",
+			Handler:       _TranslationService_Session_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 		{
 			StreamName:    "TranslateStream",
 			Handler:       _TranslationService_TranslateStream_Handler,
@@ -0,0 +1,258 @@
+package quality
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/dasmlab/nanabush/server/pkg/langid"
+)
+
+// Flags raised by the scorer.
+const (
+	FlagLengthRatio      = "length_ratio"
+	FlagUntranslated     = "untranslated_text"
+	FlagLanguageMismatch = "language_mismatch"
+	FlagLowConfidence    = "low_language_confidence"
+	FlagMarkupLost       = "markup_lost"
+)
+
+// DefaultReviewThreshold is the score below which a translation should be reviewed by a human.
+const DefaultReviewThreshold = 0.6
+
+// minLanguageChars is the output length below which language detection is too
+// unreliable to use (most titles).
+const minLanguageChars = 40
+
+// Component weights. Markup is dropped (and the rest rescaled) for plain text.
+const (
+	weightLength       = 0.2
+	weightUntranslated = 0.3
+	weightLanguage     = 0.3
+	weightMarkup       = 0.2
+)
+
+// Result is the quality estimate for one translation.
+type Result struct {
+	// Score is 0 (unusable) to 1 (no problems found)
+	Score float64
+	// Flags name the heuristics that found problems, sorted
+	Flags []string
+
+	// LengthRatio is output length over source length, in characters
+	LengthRatio float64
+	// UntranslatedRatio is the share of output words copied from the source
+	UntranslatedRatio float64
+	// LanguageConfidence is how sure the scorer is that the output is in the target language
+	LanguageConfidence float64
+	// MarkupPreserved is the fraction of source markdown structures kept (1 for plain text)
+	MarkupPreserved float64
+}
+
+var (
+	// codePattern, urlPattern and placeholderPattern match text that is expected
+	// to be copied unchanged and is excluded from the untranslated check.
+	codePattern        = regexp.MustCompile("(?s)```.*?```|`[^`\n]*`")
+	urlPattern         = regexp.MustCompile(`\]\([^)]*\)|https?://\S+`)
+	placeholderPattern = regexp.MustCompile(`\{\{[A-Z0-9_]+\}\}`)
+	wordPattern        = regexp.MustCompile(`[\p{L}][\p{L}'-]{3,}`)
+)
+
+// markupPatterns count markdown structures that should survive translation.
+var markupPatterns = map[string]*regexp.Regexp{
+	"heading":    regexp.MustCompile(`(?m)^#{1,6} `),
+	"list_item":  regexp.MustCompile(`(?m)^\s*(?:[-*+]|\d+\.) `),
+	"link":       regexp.MustCompile(`\]\([^)]*\)`),
+	"image":      regexp.MustCompile(`!\[[^\]]*\]\(`),
+	"code_fence": regexp.MustCompile("(?m)^```"),
+	"table_row":  regexp.MustCompile(`(?m)^\|.*\|\s*$`),
+	"html_tag":   regexp.MustCompile(`</?[a-zA-Z][^>]*>`),
+}
+
+// Scorer estimates translation quality without a reference translation, from
+// cheap heuristics: length ratio, text copied from the source, target-language
+// confidence and markdown preservation.
+type Scorer struct {
+	langID *langid.Identifier
+}
+
+// New creates a Scorer. langID may be nil to skip the language heuristic.
+func New(langID *langid.Identifier) *Scorer {
+	return &Scorer{langID: langID}
+}
+
+// Score rates output as a translation of source into targetLang. Set markdown
+// for documents so markup preservation is checked.
+func (s *Scorer) Score(source, output, targetLang string, markdown bool) Result {
+	result := Result{
+		LanguageConfidence: 1,
+		MarkupPreserved:    1,
+	}
+	var flags []string
+	total, weights := 0.0, 0.0
+
+	// Length ratio: translations between major European languages stay within ~0.7-1.5x
+	sourceLen := utf8.RuneCountInString(strings.TrimSpace(source))
+	outputLen := utf8.RuneCountInString(strings.TrimSpace(output))
+	if sourceLen > 0 {
+		result.LengthRatio = float64(outputLen) / float64(sourceLen)
+	}
+	lengthScore := lengthRatioScore(result.LengthRatio)
+	if lengthScore < 0.5 {
+		flags = append(flags, FlagLengthRatio)
+	}
+	total += weightLength * lengthScore
+	weights += weightLength
+
+	// Untranslated text: words copied verbatim from the source
+	result.UntranslatedRatio = untranslatedRatio(source, output)
+	untranslatedScore := clamp(1 - result.UntranslatedRatio/0.6)
+	if result.UntranslatedRatio > 0.3 {
+		flags = append(flags, FlagUntranslated)
+	}
+	total += weightUntranslated * untranslatedScore
+	weights += weightUntranslated
+
+	// Target language confidence
+	if s.langID != nil && outputLen >= minLanguageChars && s.langID.Knows(targetLang) {
+		detected := s.langID.Detect(stripUnchanged(output))
+		languageScore := detected.Confidence
+		if detected.Language != langid.Undetermined && !langid.SameLanguage(detected.Language, targetLang) {
+			languageScore = 0
+			flags = append(flags, FlagLanguageMismatch)
+		} else if detected.Confidence < 0.3 {
+			flags = append(flags, FlagLowConfidence)
+		}
+		// Short text detects poorly; do not let it dominate
+		languageScore = math.Max(languageScore, 1-float64(outputLen)/200)
+		result.LanguageConfidence = clamp(languageScore)
+		total += weightLanguage * result.LanguageConfidence
+		weights += weightLanguage
+	}
+
+	// Markup preservation
+	if markdown {
+		result.MarkupPreserved = MarkupPreserved(source, output)
+		if result.MarkupPreserved < 0.9 {
+			flags = append(flags, FlagMarkupLost)
+		}
+		total += weightMarkup * result.MarkupPreserved
+		weights += weightMarkup
+	}
+
+	if weights > 0 {
+		result.Score = math.Round(total/weights*1000) / 1000
+	}
+	sort.Strings(flags)
+	result.Flags = flags
+	return result
+}
+
+// lengthRatioScore is 1 inside the expected band and falls off linearly outside it.
+func lengthRatioScore(ratio float64) float64 {
+	switch {
+	case ratio <= 0:
+		return 0
+	case ratio < 0.7:
+		return clamp((ratio - 0.3) / 0.4)
+	case ratio > 1.5:
+		return clamp((3.0 - ratio) / 1.5)
+	default:
+		return 1
+	}
+}
+
+// untranslatedRatio is the share of output words (4+ letters) that also
+// appear in the source, ignoring code, URLs, placeholders and the source's
+// names. Product terms legitimately carry over too, so only high ratios matter.
+func untranslatedRatio(source, output string) float64 {
+	source = stripUnchanged(source)
+	sourceWords := make(map[string]bool)
+	for _, w := range wordPattern.FindAllString(source, -1) {
+		sourceWords[strings.ToLower(w)] = true
+	}
+	sourceNames := names(source)
+	outputWords := wordPattern.FindAllString(stripUnchanged(output), -1)
+	if len(outputWords) == 0 {
+		return 0
+	}
+	copied := 0
+	for _, w := range outputWords {
+		if sourceWords[strings.ToLower(w)] && !sourceNames[w] {
+			copied++
+		}
+	}
+	return float64(copied) / float64(len(outputWords))
+}
+
+// MarkupPreserved is the fraction of source markup structures present in the output.
+func MarkupPreserved(source, output string) float64 {
+	expected, kept := 0, 0
+	for _, pattern := range markupPatterns {
+		want := len(pattern.FindAllStringIndex(source, -1))
+		got := len(pattern.FindAllStringIndex(output, -1))
+		expected += want
+		if got < want {
+			kept += got
+		} else {
+			kept += want
+		}
+	}
+	if expected == 0 {
+		return 1
+	}
+	return float64(kept) / float64(expected)
+}
+
+// stripUnchanged removes text that is copied unchanged by design.
+func stripUnchanged(text string) string {
+	text = codePattern.ReplaceAllString(text, " ")
+	text = urlPattern.ReplaceAllString(text, " ")
+	return placeholderPattern.ReplaceAllString(text, " ")
+}
+
+// names returns the words of text that are capitalised mid-sentence, which
+// are taken to be names and not translated. A word capitalised only at the
+// start of a sentence is an ordinary word, and title-case lines (every word
+// capitalised) are ignored.
+func names(text string) map[string]bool {
+	names := make(map[string]bool)
+	for _, line := range strings.Split(text, "\n") {
+		var capitalised []string
+		lower := false
+		for _, loc := range wordPattern.FindAllStringIndex(line, -1) {
+			word := line[loc[0]:loc[1]]
+			r, _ := utf8.DecodeRuneInString(word)
+			switch {
+			case !unicode.IsUpper(r):
+				lower = true
+			case !startsSentence(line[:loc[0]]):
+				capitalised = append(capitalised, word)
+			}
+		}
+		if lower {
+			for _, word := range capitalised {
+				names[word] = true
+			}
+		}
+	}
+	return names
+}
+
+// startsSentence reports whether a word preceded by before on its line
+// starts a sentence, skipping markup and list numbering.
+func startsSentence(before string) bool {
+	before = strings.TrimRight(before, " \t*_[(\"'#>-0123456789")
+	if before == "" {
+		return true
+	}
+	r, _ := utf8.DecodeLastRuneInString(before)
+	return strings.ContainsRune(".!?:", r)
+}
+
+func clamp(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
@@ -0,0 +1,154 @@
+package quality
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/dasmlab/nanabush/server/pkg/langid"
+)
+
+const (
+	enDoc = "# Deploy the agent\n\nInstall the Nanabush agent with Helm, then restart the service so the new configuration is loaded.\n\n- Check the logs\n- Open the [dashboard](https://example.com/dash)\n"
+	frDoc = "# Déployer l'agent\n\nInstallez l'agent Nanabush avec Helm, puis redémarrez le service afin que la nouvelle configuration soit chargée.\n\n- Vérifiez les journaux\n- Ouvrez le [tableau de bord](https://example.com/dash)\n"
+)
+
+func TestScore(t *testing.T) {
+	id, err := langid.New()
+	if err != nil {
+		t.Fatalf("langid.New: %v", err)
+	}
+	s := New(id)
+
+	tests := []struct {
+		name      string
+		source    string
+		output    string
+		markdown  bool
+		wantFlags []string
+		minScore  float64
+		maxScore  float64
+	}{
+		{
+			name:     "good translation",
+			source:   enDoc,
+			output:   frDoc,
+			markdown: true,
+			minScore: 0.9,
+			maxScore: 1,
+		},
+		{
+			name:      "source copied",
+			source:    enDoc,
+			output:    enDoc,
+			markdown:  true,
+			wantFlags: []string{FlagLanguageMismatch, FlagUntranslated},
+			maxScore:  DefaultReviewThreshold,
+		},
+		{
+			name:      "markup dropped",
+			source:    enDoc,
+			output:    "Déployer l'agent. Installez l'agent Nanabush avec Helm, puis redémarrez le service afin que la nouvelle configuration soit chargée. Vérifiez les journaux. Ouvrez le tableau de bord.",
+			markdown:  true,
+			wantFlags: []string{FlagMarkupLost},
+			minScore:  0.6,
+			maxScore:  0.9,
+		},
+		{
+			name:      "truncated",
+			source:    enDoc,
+			output:    "# Déployer l'agent\n",
+			markdown:  true,
+			wantFlags: []string{FlagLengthRatio, FlagMarkupLost},
+			maxScore:  DefaultReviewThreshold,
+		},
+		{
+			name:     "plain title",
+			source:   "Getting started",
+			output:   "Premiers pas",
+			minScore: 1,
+			maxScore: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := s.Score(tt.source, tt.output, "fr", tt.markdown)
+			if strings.Join(got.Flags, ",") != strings.Join(tt.wantFlags, ",") {
+				t.Errorf("flags = %v, want %v (%+v)", got.Flags, tt.wantFlags, got)
+			}
+			if got.Score < tt.minScore || got.Score > tt.maxScore {
+				t.Errorf("score = %v, want %v to %v (%+v)", got.Score, tt.minScore, tt.maxScore, got)
+			}
+		})
+	}
+}
+
+func TestUntranslatedRatio(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		output string
+		want   float64
+	}{
+		{
+			name:   "names mid-sentence carry over",
+			source: "Install the Nanabush agent with Helm.",
+			output: "Installez l'agent Nanabush avec Helm.",
+			want:   0,
+		},
+		{
+			name:   "capitalised at the start of a sentence is not a name",
+			source: "Restart the service. Check the logs.",
+			output: "Restart the service. Check the logs.",
+			want:   1,
+		},
+		{
+			name:   "name case comes from the source",
+			source: "Open the settings page.",
+			output: "Ouvrez la page Settings.",
+			want:   2.0 / 3,
+		},
+		{
+			name:   "title-case lines have no names",
+			source: "Getting Started With Kubernetes",
+			output: "Getting Started With Kubernetes",
+			want:   1,
+		},
+		{
+			name:   "code, links and placeholders are ignored",
+			source: "Run `helm upgrade` as {{PII_EMAIL_1}}, see [docs](https://example.com/install).",
+			output: "Lancez `helm upgrade` en tant que {{PII_EMAIL_1}}, voir [la doc](https://example.com/install).",
+			want:   0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := untranslatedRatio(tt.source, tt.output); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("untranslatedRatio = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkupPreserved(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		output string
+		want   float64
+	}{
+		{"plain text", "Hello world", "Bonjour le monde", 1},
+		{"all kept", enDoc, frDoc, 1},
+		{"heading lost", "# Title\n\nText", "Titre\n\nTexte", 0},
+		{"one of two list items lost", "- one\n- two\n", "- un\ndeux\n", 0.5},
+		{"extra markup is not a bonus", "# Title\n- one\n", "# Titre\n## Sous-titre\n", 0.5},
+		{"code fence and table", "```\ncode\n```\n| a | b |\n", "```\ncode\n```\nun | deux\n", 2.0 / 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MarkupPreserved(tt.source, tt.output); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("MarkupPreserved = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,303 @@
+package sanitize
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultInternalDomains are hostname suffixes treated as internal.
+var DefaultInternalDomains = []string{"svc", "svc.cluster.local", "cluster.local", "internal", "local", "corp", "lan"}
+
+// Rule detects one kind of sensitive value.
+type Rule struct {
+	// Name identifies the rule in placeholders and audit records (e.g. "email").
+	Name string `json:"name"`
+
+	// Pattern is a Go regular expression matching the sensitive value.
+	Pattern string `json:"pattern"`
+
+	// Group selects a submatch to mask instead of the whole match, so a rule
+	// can match "password: hunter2" but only mask "hunter2". Zero masks the whole match.
+	Group int `json:"group,omitempty"`
+}
+
+// Config configures a Sanitizer.
+type Config struct {
+	// Rules are applied in order after the built-in rules (unless ReplaceDefaults is set).
+	Rules []Rule `json:"rules"`
+
+	// ReplaceDefaults drops the built-in rules and uses only Rules.
+	ReplaceDefaults bool `json:"replace_defaults"`
+
+	// InternalDomains are hostname suffixes masked by the internal_hostname rule.
+	// Empty means DefaultInternalDomains.
+	InternalDomains []string `json:"internal_domains"`
+
+	// HashKey keys the HMAC recorded for each masked value. Empty means a
+	// random key, so hashes only match within one process. It is never read
+	// from the rules file.
+	HashKey []byte `json:"-"`
+}
+
+// LoadConfig reads a JSON rules file.
+func LoadConfig(path string) (Config, error) {
+	var cfg Config
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read sanitizer rules: %w", err)
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse sanitizer rules %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// DefaultRules returns the built-in detection rules. Order matters: more
+// specific rules (JWTs, keys) run before broader ones (hostnames).
+func DefaultRules(internalDomains []string) []Rule {
+	if len(internalDomains) == 0 {
+		internalDomains = DefaultInternalDomains
+	}
+	suffixes := make([]string, len(internalDomains))
+	for i, d := range internalDomains {
+		suffixes[i] = regexp.QuoteMeta(strings.Trim(d, "."))
+	}
+	// Longest suffix first so "svc.cluster.local" wins over "local"
+	sort.Slice(suffixes, func(i, j int) bool { return len(suffixes[i]) > len(suffixes[j]) })
+
+	return []Rule{
+		{Name: "jwt", Pattern: `\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`},
+		{Name: "api_key", Pattern: `\b(?:sk-[A-Za-z0-9_-]{20,}|AKIA[0-9A-Z]{16}|gh[pousr]_[A-Za-z0-9]{36,}|xox[abprs]-[A-Za-z0-9-]{10,}|AIza[0-9A-Za-z_-]{35}|glpat-[A-Za-z0-9_-]{20,})`},
+		{Name: "secret", Pattern: `(?i)\b(?:api[_-]?key|secret|token|passw(?:or)?d)\s*[:=]\s*["']?([A-Za-z0-9_\-/+=.]{8,})`, Group: 1},
+		{Name: "email", Pattern: `\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`},
+		{Name: "ipv4", Pattern: `\b(?:(?:25[0-5]|2[0-4]\d|1?\d?\d)\.){3}(?:25[0-5]|2[0-4]\d|1?\d?\d)\b`},
+		{Name: "ipv6", Pattern: `\b(?:[0-9A-Fa-f]{1,4}:){7}[0-9A-Fa-f]{1,4}\b|\b(?:[0-9A-Fa-f]{1,4}:){1,6}:[0-9A-Fa-f]{1,4}(?::[0-9A-Fa-f]{1,4})*\b`},
+		{Name: "phone", Pattern: `(?:\+\d{1,3}[\s.-]?)?\(?\b\d{3}\)?[\s.-]\d{3}[\s.-]\d{4}\b`},
+		{Name: "internal_hostname", Pattern: `(?i)\b[a-z0-9](?:[a-z0-9-]*[a-z0-9])?(?:\.[a-z0-9](?:[a-z0-9-]*[a-z0-9])?)*\.(?:` + strings.Join(suffixes, "|") + `)\b`},
+	}
+}
+
+type compiledRule struct {
+	Rule
+	re *regexp.Regexp
+}
+
+// Sanitizer detects sensitive values and replaces them with placeholders.
+// It is safe for concurrent use; per-document state lives in a Mask.
+type Sanitizer struct {
+	rules   []compiledRule
+	hashKey []byte
+}
+
+// New compiles the configured rules.
+func New(cfg Config) (*Sanitizer, error) {
+	var rules []Rule
+	if !cfg.ReplaceDefaults {
+		rules = DefaultRules(cfg.InternalDomains)
+	}
+	rules = append(rules, cfg.Rules...)
+
+	s := &Sanitizer{hashKey: cfg.HashKey}
+	if len(s.hashKey) == 0 {
+		s.hashKey = make([]byte, 32)
+		if _, err := rand.Read(s.hashKey); err != nil {
+			return nil, fmt.Errorf("failed to generate sanitizer hash key: %w", err)
+		}
+	}
+	for _, r := range rules {
+		if r.Name == "" {
+			return nil, fmt.Errorf("sanitizer rule with pattern %q has no name", r.Pattern)
+		}
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("sanitizer rule %q: %w", r.Name, err)
+		}
+		if r.Group < 0 || r.Group > re.NumSubexp() {
+			return nil, fmt.Errorf("sanitizer rule %q: group %d out of range", r.Name, r.Group)
+		}
+		s.rules = append(s.rules, compiledRule{Rule: r, re: re})
+	}
+	return s, nil
+}
+
+// RuleNames returns the names of the active rules in order.
+func (s *Sanitizer) RuleNames() []string {
+	names := make([]string, len(s.rules))
+	for i, r := range s.rules {
+		names[i] = r.Name
+	}
+	return names
+}
+
+// NewMask starts masking a document. Values are replaced with stable
+// placeholders: the same value always maps to the same placeholder within a Mask.
+func (s *Sanitizer) NewMask() *Mask {
+	return &Mask{
+		sanitizer:   s,
+		byValue:     make(map[string]string),
+		byToken:     make(map[string]string),
+		counters:    make(map[string]int),
+		findingsIdx: make(map[string]*Finding),
+		restored:    make(map[string]bool),
+	}
+}
+
+// placeholderPattern matches placeholders, tolerating whitespace a model may add inside the braces.
+var placeholderPattern = regexp.MustCompile(`\{\{\s*(PII_[A-Z0-9_]+)\s*\}\}`)
+
+// Finding summarizes one masked value. The value itself is never recorded,
+// only an HMAC-SHA256 of it keyed with Config.HashKey: a plain hash of a
+// phone number or IPv4 address could be reversed by trying every value.
+type Finding struct {
+	Rule        string `json:"rule"`
+	Placeholder string `json:"placeholder"`
+	ValueHMAC   string `json:"value_hmac"`
+	Occurrences int    `json:"occurrences"`
+}
+
+// Mask holds the placeholder mapping for one document.
+type Mask struct {
+	sanitizer *Sanitizer
+
+	mu          sync.Mutex
+	byValue     map[string]string // original value -> placeholder token
+	byToken     map[string]string // placeholder token -> original value
+	counters    map[string]int    // rule -> next placeholder number
+	findings    []*Finding
+	findingsIdx map[string]*Finding
+	restored    map[string]bool // placeholder tokens seen by Restore
+}
+
+// Scrub replaces sensitive values in text with placeholders.
+// A nil Mask returns text unchanged.
+func (m *Mask) Scrub(text string) string {
+	if m == nil {
+		return text
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, rule := range m.sanitizer.rules {
+		text = m.applyLocked(rule, text)
+	}
+	return text
+}
+
+// applyLocked masks every match of one rule.
+func (m *Mask) applyLocked(rule compiledRule, text string) string {
+	matches := rule.re.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return text
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, loc := range matches {
+		start, end := loc[2*rule.Group], loc[2*rule.Group+1]
+		if start < 0 || start < last {
+			continue
+		}
+		value := text[start:end]
+		if placeholderPattern.MatchString(value) {
+			continue
+		}
+		b.WriteString(text[last:start])
+		b.WriteString("{{" + m.tokenLocked(rule.Name, value) + "}}")
+		last = end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// tokenLocked returns the stable placeholder token for value.
+func (m *Mask) tokenLocked(rule, value string) string {
+	if token, ok := m.byValue[value]; ok {
+		m.findingsIdx[token].Occurrences++
+		return token
+	}
+
+	m.counters[rule]++
+	token := fmt.Sprintf("PII_%s_%d", strings.ToUpper(rule), m.counters[rule])
+	m.byValue[value] = token
+	m.byToken[token] = value
+
+	mac := hmac.New(sha256.New, m.sanitizer.hashKey)
+	mac.Write([]byte(value))
+	finding := &Finding{
+		Rule:        rule,
+		Placeholder: token,
+		ValueHMAC:   hex.EncodeToString(mac.Sum(nil)),
+		Occurrences: 1,
+	}
+	m.findingsIdx[token] = finding
+	m.findings = append(m.findings, finding)
+	return token
+}
+
+// Restore replaces placeholders in text with the original values.
+// A nil Mask returns text unchanged.
+func (m *Mask) Restore(text string) string {
+	if m == nil {
+		return text
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return placeholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		token := placeholderPattern.FindStringSubmatch(match)[1]
+		value, ok := m.byToken[token]
+		if !ok {
+			return match
+		}
+		m.restored[token] = true
+		return value
+	})
+}
+
+// Missing returns the placeholders that were masked but never seen by Restore,
+// i.e. values the model dropped from its output.
+func (m *Mask) Missing() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var missing []string
+	for _, f := range m.findings {
+		if !m.restored[f.Placeholder] {
+			missing = append(missing, f.Placeholder)
+		}
+	}
+	return missing
+}
+
+// Findings returns what was masked, in first-seen order.
+func (m *Mask) Findings() []Finding {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	findings := make([]Finding, len(m.findings))
+	for i, f := range m.findings {
+		findings[i] = *f
+	}
+	return findings
+}
+
+// Counts returns the number of distinct masked values per rule.
+func (m *Mask) Counts() map[string]int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	counts := make(map[string]int, len(m.counters))
+	for rule, n := range m.counters {
+		counts[rule] = n
+	}
+	return counts
+}
@@ -0,0 +1,345 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Priority is the scheduling class of a translation job.
+type Priority int
+
+const (
+	// PriorityBulk is used for batch jobs (collection syncs, retranslation sweeps).
+	PriorityBulk Priority = iota
+	// PriorityNormal is the default class when a request does not set one.
+	PriorityNormal
+	// PriorityInteractive is used for user-facing work (title checks, single-page edits).
+	PriorityInteractive
+)
+
+// numPriorities is the number of priority classes.
+const numPriorities = 3
+
+// String returns the metadata name of the priority class.
+func (p Priority) String() string {
+	switch p {
+	case PriorityBulk:
+		return "bulk"
+	case PriorityNormal:
+		return "normal"
+	case PriorityInteractive:
+		return "interactive"
+	default:
+		return fmt.Sprintf("priority(%d)", int(p))
+	}
+}
+
+// ParsePriority parses a priority class name. An empty string yields PriorityNormal.
+func ParsePriority(s string) (Priority, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "normal":
+		return PriorityNormal, nil
+	case "interactive":
+		return PriorityInteractive, nil
+	case "bulk", "batch":
+		return PriorityBulk, nil
+	default:
+		return PriorityNormal, fmt.Errorf("unknown priority class %q (want interactive, normal or bulk)", s)
+	}
+}
+
+// Config controls scheduler behaviour.
+type Config struct {
+	// MaxConcurrent is the number of jobs allowed to run against the backend at once.
+	MaxConcurrent int
+
+	// NamespaceWeights assigns fair-share weights to namespaces.
+	// Namespaces not listed get DefaultNamespaceWeight.
+	NamespaceWeights map[string]int
+
+	// DefaultNamespaceWeight is the weight of namespaces not in NamespaceWeights.
+	DefaultNamespaceWeight int
+
+	// StarvationTimeout is how long a job may wait before it is promoted
+	// ahead of all priority classes. Zero disables starvation protection.
+	StarvationTimeout time.Duration
+}
+
+// DefaultConfig returns the scheduler defaults used by the server.
+func DefaultConfig() Config {
+	return Config{
+		MaxConcurrent:          4,
+		DefaultNamespaceWeight: 1,
+		StarvationTimeout:      2 * time.Minute,
+	}
+}
+
+// Stats is a snapshot of scheduler state.
+type Stats struct {
+	MaxConcurrent   int
+	Running         int
+	QueueDepth      int
+	DepthByPriority map[Priority]int
+	AvgWait         time.Duration
+	AvgService      time.Duration
+}
+
+// ticket is a queued job waiting for a slot.
+type ticket struct {
+	namespace  string
+	priority   Priority
+	finishTag  float64
+	enqueuedAt time.Time
+	ready      chan struct{}
+	dispatched bool
+}
+
+// Scheduler gates access to the translation backend. Jobs are ordered by
+// priority class; within a class, namespaces share capacity by weighted fair
+// queuing so a single namespace cannot monopolise the backend. Jobs that
+// wait longer than StarvationTimeout are served before everything else.
+type Scheduler struct {
+	cfg Config
+
+	mu      sync.Mutex
+	running int
+	queues  [numPriorities][]*ticket
+	// virtualTime is the finish tag of the last dispatched ticket per class.
+	virtualTime [numPriorities]float64
+	// lastFinish is the finish tag of the last enqueued ticket per class and namespace.
+	lastFinish [numPriorities]map[string]float64
+
+	// Exponentially weighted moving averages, in seconds.
+	avgWait    float64
+	avgService float64
+}
+
+// ewmaAlpha is the smoothing factor for wait and service time averages.
+const ewmaAlpha = 0.2
+
+// New creates a scheduler. Zero-valued config fields fall back to DefaultConfig.
+func New(cfg Config) *Scheduler {
+	s := &Scheduler{cfg: withDefaults(cfg)}
+	for i := range s.lastFinish {
+		s.lastFinish[i] = make(map[string]float64)
+	}
+	return s
+}
+
+// Reconfigure replaces the configuration of a running scheduler. A higher
+// MaxConcurrent dispatches waiting jobs at once; a lower one lets running
+// jobs finish. New weights apply to jobs queued from now on.
+func (s *Scheduler) Reconfigure(cfg Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cfg = withDefaults(cfg)
+	s.dispatchLocked()
+}
+
+func withDefaults(cfg Config) Config {
+	defaults := DefaultConfig()
+	if cfg.MaxConcurrent <= 0 {
+		cfg.MaxConcurrent = defaults.MaxConcurrent
+	}
+	if cfg.DefaultNamespaceWeight <= 0 {
+		cfg.DefaultNamespaceWeight = defaults.DefaultNamespaceWeight
+	}
+	return cfg
+}
+
+// Acquire blocks until the job may run or ctx is done. cost is the relative
+// size of the job (e.g. characters to translate); values below 1 count as 1.
+// The returned release function must be called when the job finishes.
+func (s *Scheduler) Acquire(ctx context.Context, namespace string, priority Priority, cost int) (func(), error) {
+	if priority < 0 || priority >= numPriorities {
+		priority = PriorityNormal
+	}
+	if cost < 1 {
+		cost = 1
+	}
+
+	s.mu.Lock()
+	t := &ticket{
+		namespace:  namespace,
+		priority:   priority,
+		enqueuedAt: time.Now(),
+		ready:      make(chan struct{}),
+	}
+	start := s.virtualTime[priority]
+	if last := s.lastFinish[priority][namespace]; last > start {
+		start = last
+	}
+	t.finishTag = start + float64(cost)/float64(s.weight(namespace))
+	s.lastFinish[priority][namespace] = t.finishTag
+	s.queues[priority] = append(s.queues[priority], t)
+	s.dispatchLocked()
+	s.mu.Unlock()
+
+	select {
+	case <-t.ready:
+	case <-ctx.Done():
+		s.mu.Lock()
+		if t.dispatched {
+			// Dispatched concurrently with cancellation: hand the slot back.
+			s.running--
+			s.dispatchLocked()
+		} else {
+			s.removeLocked(t)
+		}
+		s.mu.Unlock()
+		return nil, ctx.Err()
+	}
+
+	startedAt := time.Now()
+	var once sync.Once
+	return func() {
+		once.Do(func() { s.release(startedAt) })
+	}, nil
+}
+
+// QueueAhead returns the number of queued jobs a new job of the given
+// priority would have to wait behind.
+func (s *Scheduler) QueueAhead(priority Priority) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.aheadLocked(priority)
+}
+
+// EstimateWait estimates how long a new job of the given priority would wait
+// for a slot, based on the jobs queued ahead of it and the average service time.
+func (s *Scheduler) EstimateWait(priority Priority) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ahead := s.aheadLocked(priority)
+	if s.running < s.cfg.MaxConcurrent && ahead == 0 {
+		return 0
+	}
+	// Jobs ahead drain cfg.MaxConcurrent at a time.
+	rounds := float64(ahead+1) / float64(s.cfg.MaxConcurrent)
+	return time.Duration(rounds * s.avgService * float64(time.Second))
+}
+
+// Stats returns a snapshot of queue depth, running jobs and average wait time.
+func (s *Scheduler) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := Stats{
+		MaxConcurrent:   s.cfg.MaxConcurrent,
+		Running:         s.running,
+		DepthByPriority: make(map[Priority]int, numPriorities),
+		AvgWait:         time.Duration(s.avgWait * float64(time.Second)),
+		AvgService:      time.Duration(s.avgService * float64(time.Second)),
+	}
+	for p := range s.queues {
+		st.DepthByPriority[Priority(p)] = len(s.queues[p])
+		st.QueueDepth += len(s.queues[p])
+	}
+	return st
+}
+
+// aheadLocked counts queued jobs at or above the given priority.
+func (s *Scheduler) aheadLocked(priority Priority) int {
+	ahead := 0
+	for p := Priority(numPriorities - 1); p >= priority && p >= 0; p-- {
+		ahead += len(s.queues[p])
+	}
+	return ahead
+}
+
+// release frees a slot and dispatches waiting jobs.
+func (s *Scheduler) release(startedAt time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.running--
+	s.avgService = ewma(s.avgService, time.Since(startedAt).Seconds())
+	s.dispatchLocked()
+}
+
+// dispatchLocked hands free slots to the next eligible tickets.
+func (s *Scheduler) dispatchLocked() {
+	for s.running < s.cfg.MaxConcurrent {
+		t := s.nextLocked()
+		if t == nil {
+			return
+		}
+		s.removeLocked(t)
+		t.dispatched = true
+		s.running++
+		if t.finishTag > s.virtualTime[t.priority] {
+			s.virtualTime[t.priority] = t.finishTag
+		}
+		s.avgWait = ewma(s.avgWait, time.Since(t.enqueuedAt).Seconds())
+		close(t.ready)
+	}
+}
+
+// nextLocked picks the next ticket: the oldest starved ticket if any,
+// otherwise the smallest finish tag in the highest non-empty priority class.
+func (s *Scheduler) nextLocked() *ticket {
+	if s.cfg.StarvationTimeout > 0 {
+		var oldest *ticket
+		for p := range s.queues {
+			for _, t := range s.queues[p] {
+				if time.Since(t.enqueuedAt) < s.cfg.StarvationTimeout {
+					continue
+				}
+				if oldest == nil || t.enqueuedAt.Before(oldest.enqueuedAt) {
+					oldest = t
+				}
+			}
+		}
+		if oldest != nil {
+			return oldest
+		}
+	}
+
+	for p := numPriorities - 1; p >= 0; p-- {
+		var best *ticket
+		for _, t := range s.queues[p] {
+			if best == nil || t.finishTag < best.finishTag {
+				best = t
+			}
+		}
+		if best != nil {
+			return best
+		}
+	}
+	return nil
+}
+
+// removeLocked removes t from its priority queue.
+func (s *Scheduler) removeLocked(t *ticket) {
+	q := s.queues[t.priority]
+	for i, candidate := range q {
+		if candidate == t {
+			s.queues[t.priority] = append(q[:i], q[i+1:]...)
+			break
+		}
+	}
+	if len(s.queues[t.priority]) == 0 {
+		// Idle class: forget per-namespace history so it does not grow unbounded.
+		s.lastFinish[t.priority] = make(map[string]float64)
+	}
+}
+
+// weight returns the fair-share weight of a namespace.
+func (s *Scheduler) weight(namespace string) int {
+	if w, ok := s.cfg.NamespaceWeights[namespace]; ok && w > 0 {
+		return w
+	}
+	return s.cfg.DefaultNamespaceWeight
+}
+
+func ewma(avg, sample float64) float64 {
+	if avg == 0 {
+		return sample
+	}
+	return ewmaAlpha*sample + (1-ewmaAlpha)*avg
+}
@@ -0,0 +1,209 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParsePriority(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Priority
+		wantErr bool
+	}{
+		{"", PriorityNormal, false},
+		{"normal", PriorityNormal, false},
+		{" Interactive ", PriorityInteractive, false},
+		{"bulk", PriorityBulk, false},
+		{"batch", PriorityBulk, false},
+		{"urgent", PriorityNormal, true},
+	}
+	for _, tt := range tests {
+		got, err := ParsePriority(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParsePriority(%q) = %v, %v; want %v, err=%v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// job is a queued Acquire call in a dispatch-order test.
+type job struct {
+	name      string
+	namespace string
+	priority  Priority
+	cost      int
+}
+
+// dispatchOrder holds the only slot of a one-slot scheduler, queues jobs in
+// order (optionally pausing after each), then releases the slot and returns
+// the order in which the jobs were dispatched.
+func dispatchOrder(t *testing.T, s *Scheduler, pause time.Duration, jobs []job) []string {
+	t.Helper()
+	ctx := context.Background()
+
+	hold, err := s.Acquire(ctx, "holder", PriorityInteractive, 1)
+	if err != nil {
+		t.Fatalf("Acquire holder: %v", err)
+	}
+
+	var mu sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+	for i, j := range jobs {
+		wg.Add(1)
+		go func(j job) {
+			defer wg.Done()
+			release, err := s.Acquire(ctx, j.namespace, j.priority, j.cost)
+			if err != nil {
+				t.Errorf("Acquire %s: %v", j.name, err)
+				return
+			}
+			mu.Lock()
+			order = append(order, j.name)
+			mu.Unlock()
+			release()
+		}(j)
+		waitForQueue(t, s, i+1)
+		time.Sleep(pause)
+	}
+
+	hold()
+	wg.Wait()
+	return order
+}
+
+// waitForQueue waits until n jobs are queued.
+func waitForQueue(t *testing.T, s *Scheduler, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for s.Stats().QueueDepth != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("queue depth is %d, want %d", s.Stats().QueueDepth, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestDispatchOrder(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   Config
+		pause time.Duration
+		jobs  []job
+		want  []string
+	}{
+		{
+			name: "higher priority classes first",
+			cfg:  Config{MaxConcurrent: 1},
+			jobs: []job{
+				{"bulk", "a", PriorityBulk, 1},
+				{"normal", "a", PriorityNormal, 1},
+				{"interactive", "a", PriorityInteractive, 1},
+			},
+			want: []string{"interactive", "normal", "bulk"},
+		},
+		{
+			name: "namespaces share a class by weight",
+			cfg:  Config{MaxConcurrent: 1, NamespaceWeights: map[string]int{"heavy": 3}},
+			jobs: []job{
+				{"light-1", "light", PriorityNormal, 3},
+				{"light-2", "light", PriorityNormal, 3},
+				{"heavy-1", "heavy", PriorityNormal, 3},
+				{"heavy-2", "heavy", PriorityNormal, 3},
+				{"heavy-3", "heavy", PriorityNormal, 3},
+			},
+			// Finish tags: heavy 1, 2, 3; light 3, 6
+			want: []string{"heavy-1", "heavy-2", "light-1", "heavy-3", "light-2"},
+		},
+		{
+			name: "cheaper jobs finish first within a namespace share",
+			cfg:  Config{MaxConcurrent: 1},
+			jobs: []job{
+				{"big", "a", PriorityNormal, 100},
+				{"small", "b", PriorityNormal, 1},
+			},
+			want: []string{"small", "big"},
+		},
+		{
+			name:  "starved jobs are promoted",
+			cfg:   Config{MaxConcurrent: 1, StarvationTimeout: 30 * time.Millisecond},
+			pause: 40 * time.Millisecond,
+			jobs: []job{
+				{"old-bulk", "a", PriorityBulk, 1},
+				{"interactive", "a", PriorityInteractive, 1},
+			},
+			want: []string{"old-bulk", "interactive"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dispatchOrder(t, New(tt.cfg), tt.pause, tt.jobs)
+			if len(got) != len(tt.want) {
+				t.Fatalf("dispatched %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("dispatched %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestAcquireCancelled(t *testing.T) {
+	s := New(Config{MaxConcurrent: 1})
+	hold, err := s.Acquire(context.Background(), "a", PriorityNormal, 1)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	defer hold()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := s.Acquire(ctx, "a", PriorityNormal, 1); err != context.DeadlineExceeded {
+		t.Fatalf("Acquire with expired context = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if st := s.Stats(); st.QueueDepth != 0 || st.Running != 1 {
+		t.Fatalf("after cancellation: queue depth %d, running %d; want 0, 1", st.QueueDepth, st.Running)
+	}
+}
+
+func TestReconfigureDispatchesWaitingJobs(t *testing.T) {
+	s := New(Config{MaxConcurrent: 1})
+	hold, _ := s.Acquire(context.Background(), "a", PriorityNormal, 1)
+	defer hold()
+
+	done := make(chan struct{})
+	go func() {
+		release, err := s.Acquire(context.Background(), "a", PriorityNormal, 1)
+		if err == nil {
+			release()
+		}
+		close(done)
+	}()
+	waitForQueue(t, s, 1)
+
+	s.Reconfigure(Config{MaxConcurrent: 2})
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("waiting job was not dispatched after MaxConcurrent was raised")
+	}
+}
+
+func TestEstimateWait(t *testing.T) {
+	s := New(Config{MaxConcurrent: 1})
+	if got := s.EstimateWait(PriorityNormal); got != 0 {
+		t.Fatalf("EstimateWait on an idle scheduler = %v, want 0", got)
+	}
+
+	s.mu.Lock()
+	s.avgService = 2
+	s.running = 1
+	s.mu.Unlock()
+	if got, want := s.EstimateWait(PriorityNormal), 2*time.Second; got != want {
+		t.Fatalf("EstimateWait with the slot busy = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,392 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/dasmlab/nanabush/server/pkg/audit"
+	"github.com/dasmlab/nanabush/server/pkg/jobs"
+	"github.com/dasmlab/nanabush/server/pkg/proto/v1"
+	"github.com/dasmlab/nanabush/server/pkg/webhook"
+)
+
+// TranslationServiceName is the health check name of the translation service.
+const TranslationServiceName = "nanabush.v1.TranslationService"
+
+// AdminService implements the AdminService gRPC service for operators.
+// Every call must present the admin token or an allowed mTLS client certificate.
+type AdminService struct {
+	nanabushv1.UnimplementedAdminServiceServer
+
+	// Translation is the service being managed
+	Translation *TranslationService
+
+	// Health has the translation service set to NOT_SERVING when the server
+	// drains (nil to skip). The overall "" status is left alone so liveness
+	// probes do not restart a draining server.
+	Health *health.Server
+
+	// Token authorizes callers sending "authorization: Bearer <token>" (empty disables)
+	Token string
+
+	// AllowedCommonNames authorizes callers whose verified mTLS client
+	// certificate has one of these common names
+	AllowedCommonNames []string
+
+	// Logger for admin operations
+	Logger *log.Logger
+
+	// Guards Token and AllowedCommonNames once serving (see SetCredentials)
+	credentialsMutex sync.RWMutex
+}
+
+// NewAdminService creates an AdminService managing translation. Callers must
+// set Token or AllowedCommonNames, otherwise every call is denied.
+func NewAdminService(translation *TranslationService, healthServer *health.Server, logger *log.Logger) *AdminService {
+	if logger == nil {
+		logger = translation.Logger
+	}
+	return &AdminService{
+		Translation: translation,
+		Health:      healthServer,
+		Logger:      logger,
+	}
+}
+
+// SetCredentials replaces the token and allowed common names, e.g. to rotate
+// the token without a restart.
+func (a *AdminService) SetCredentials(token string, allowedCommonNames []string) {
+	a.credentialsMutex.Lock()
+	defer a.credentialsMutex.Unlock()
+	a.Token = token
+	a.AllowedCommonNames = allowedCommonNames
+}
+
+// authorize checks the caller's credentials and returns an identity for logs.
+func (a *AdminService) authorize(ctx context.Context) (string, error) {
+	a.credentialsMutex.RLock()
+	defer a.credentialsMutex.RUnlock()
+
+	if a.Token != "" {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			for _, value := range md.Get("authorization") {
+				token, ok := strings.CutPrefix(value, "Bearer ")
+				if ok && subtle.ConstantTimeCompare([]byte(token), []byte(a.Token)) == 1 {
+					return "token", nil
+				}
+			}
+		}
+	}
+
+	if cn := peerCommonName(ctx); cn != "" {
+		for _, allowed := range a.AllowedCommonNames {
+			if cn == allowed {
+				return "cn=" + cn, nil
+			}
+		}
+		return "", status.Error(codes.PermissionDenied, fmt.Sprintf("client certificate %q is not allowed to use the admin API", cn))
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get("authorization")) > 0 {
+		return "", status.Error(codes.PermissionDenied, "invalid admin token")
+	}
+	return "", status.Error(codes.Unauthenticated, "admin API requires a bearer token or an allowed client certificate")
+}
+
+// peerCommonName returns the common name of the caller's verified client certificate.
+func peerCommonName(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return ""
+	}
+	return tlsInfo.State.VerifiedChains[0][0].Subject.CommonName
+}
+
+// auditAdmin records an admin action in the audit log.
+func (a *AdminService) auditAdmin(rpc, actor, clientID, jobID, reason string) {
+	a.Translation.writeAudit(audit.Record{
+		RPC:      rpc,
+		ClientID: clientID,
+		JobID:    jobID,
+		Outcome:  audit.OutcomeSuccess,
+		Actor:    actor,
+		Reason:   reason,
+	})
+}
+
+// ListClients returns registered clients, optionally filtered.
+func (a *AdminService) ListClients(ctx context.Context, req *nanabushv1.ListClientsRequest) (*nanabushv1.ListClientsResponse, error) {
+	if _, err := a.authorize(ctx); err != nil {
+		return nil, err
+	}
+	a.Logger.Printf("ListClients request: namespace=%q, version=%q, name=%q", req.Namespace, req.ClientVersion, req.ClientName)
+
+	clients := a.Translation.GetRegisteredClients()
+	sort.Slice(clients, func(i, j int) bool {
+		return clients[i].RegisteredAt.Before(clients[j].RegisteredAt)
+	})
+
+	resp := &nanabushv1.ListClientsResponse{}
+	for _, client := range clients {
+		if req.Namespace != "" && client.Namespace != req.Namespace {
+			continue
+		}
+		if req.ClientVersion != "" && client.ClientVersion != req.ClientVersion {
+			continue
+		}
+		if req.ClientName != "" && client.ClientName != req.ClientName {
+			continue
+		}
+		resp.Clients = append(resp.Clients, clientInfoProto(client))
+	}
+	return resp, nil
+}
+
+// GetClientMetrics returns aggregate client and job counts.
+func (a *AdminService) GetClientMetrics(ctx context.Context, req *nanabushv1.GetClientMetricsRequest) (*nanabushv1.GetClientMetricsResponse, error) {
+	if _, err := a.authorize(ctx); err != nil {
+		return nil, err
+	}
+
+	metrics := a.Translation.GetClientMetrics()
+	resp := &nanabushv1.GetClientMetricsResponse{
+		TotalClients:       int32(metrics.TotalClients),
+		ClientsByNamespace: make(map[string]int32, len(metrics.ClientsByNamespace)),
+		ClientsByVersion:   make(map[string]int32, len(metrics.ClientsByVersion)),
+		Draining:           a.Translation.Draining(),
+		ActiveSessions:     int32(metrics.ActiveSessions),
+		DeprecatedClients:  int32(metrics.DeprecatedClients),
+		InterruptedJobs:    int32(a.Translation.InterruptedJobs()),
+	}
+	if since := a.Translation.DrainingSince(); !since.IsZero() {
+		resp.DrainingSince = timestamppb.New(since)
+	}
+	if deadline := a.Translation.DrainDeadline(); !deadline.IsZero() {
+		resp.DrainDeadline = timestamppb.New(deadline)
+	}
+	for ns, count := range metrics.ClientsByNamespace {
+		resp.ClientsByNamespace[ns] = int32(count)
+	}
+	for version, count := range metrics.ClientsByVersion {
+		resp.ClientsByVersion[version] = int32(count)
+	}
+	if metrics.TotalClients > 0 {
+		resp.OldestHeartbeat = timestamppb.New(metrics.OldestHeartbeat)
+		resp.NewestHeartbeat = timestamppb.New(metrics.NewestHeartbeat)
+	}
+	if a.Translation.Running != nil {
+		resp.RunningJobs = int32(a.Translation.Running.Len())
+	}
+	if a.Translation.Jobs != nil {
+		resp.CompletedJobs = int32(a.Translation.Jobs.Len())
+	}
+	if a.Translation.Webhooks != nil {
+		stats := a.Translation.Webhooks.Stats()
+		resp.CallbacksPending = int32(stats.Pending)
+		resp.CallbacksDelivered = stats.Delivered
+		resp.CallbacksDeadLettered = stats.DeadLettered
+	}
+	return resp, nil
+}
+
+// EvictClient removes a client registration.
+func (a *AdminService) EvictClient(ctx context.Context, req *nanabushv1.EvictClientRequest) (*nanabushv1.EvictClientResponse, error) {
+	actor, err := a.authorize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	a.Logger.Printf("EvictClient request: client_id=%q, reason=%q, actor=%s", req.ClientId, req.Reason, actor)
+
+	if req.ClientId == "" {
+		return nil, status.Error(codes.InvalidArgument, "client_id is required")
+	}
+	client, ok := a.Translation.EvictClient(req.ClientId)
+	if !ok {
+		return nil, status.Error(codes.NotFound, fmt.Sprintf("client %q is not registered", req.ClientId))
+	}
+	a.auditAdmin("EvictClient", actor, req.ClientId, "", req.Reason)
+
+	return &nanabushv1.EvictClientResponse{Client: clientInfoProto(client)}, nil
+}
+
+// ListJobs returns running jobs and recently completed jobs.
+func (a *AdminService) ListJobs(ctx context.Context, req *nanabushv1.ListJobsRequest) (*nanabushv1.ListJobsResponse, error) {
+	if _, err := a.authorize(ctx); err != nil {
+		return nil, err
+	}
+	a.Logger.Printf("ListJobs request: namespace=%q, client_id=%q, state=%v", req.Namespace, req.ClientId, req.State)
+
+	matches := func(namespace, clientID string) bool {
+		return (req.Namespace == "" || namespace == req.Namespace) &&
+			(req.ClientId == "" || clientID == req.ClientId)
+	}
+
+	resp := &nanabushv1.ListJobsResponse{}
+	listRunning := req.State == nanabushv1.JobState_JOB_STATE_UNSPECIFIED || req.State == nanabushv1.JobState_JOB_STATE_RUNNING
+	if listRunning && a.Translation.Running != nil {
+		for _, job := range a.Translation.Running.List() {
+			if matches(job.Namespace, job.ClientID) {
+				resp.Jobs = append(resp.Jobs, runningJobProto(job))
+			}
+		}
+	}
+	if req.State != nanabushv1.JobState_JOB_STATE_RUNNING && a.Translation.Jobs != nil {
+		for _, job := range a.Translation.Jobs.List() {
+			info := completedJobProto(job)
+			if matches(job.Namespace, job.ClientID) && (listRunning || info.State == req.State) {
+				resp.Jobs = append(resp.Jobs, info)
+			}
+		}
+	}
+	return resp, nil
+}
+
+// CancelJob cancels a running job.
+func (a *AdminService) CancelJob(ctx context.Context, req *nanabushv1.CancelJobRequest) (*nanabushv1.CancelJobResponse, error) {
+	actor, err := a.authorize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	a.Logger.Printf("CancelJob request: job_id=%q, reason=%q, actor=%s", req.JobId, req.Reason, actor)
+
+	if req.JobId == "" {
+		return nil, status.Error(codes.InvalidArgument, "job_id is required")
+	}
+	if a.Translation.Running == nil {
+		return nil, status.Error(codes.Unimplemented, "job tracking is disabled")
+	}
+	job, ok := a.Translation.Running.Cancel(req.JobId)
+	if !ok {
+		return nil, status.Error(codes.NotFound, fmt.Sprintf("job %q is not running", req.JobId))
+	}
+	a.auditAdmin("CancelJob", actor, job.ClientID, req.JobId, req.Reason)
+
+	return &nanabushv1.CancelJobResponse{Job: runningJobProto(job)}, nil
+}
+
+// DrainServer stops the server accepting new work and reports progress.
+func (a *AdminService) DrainServer(ctx context.Context, req *nanabushv1.DrainServerRequest) (*nanabushv1.DrainServerResponse, error) {
+	actor, err := a.authorize(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	since, started := a.Translation.Drain()
+	if started {
+		a.Logger.Printf("DrainServer: draining started, reason=%q, actor=%s", req.Reason, actor)
+		if a.Health != nil {
+			a.Health.SetServingStatus(TranslationServiceName, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+		}
+		a.auditAdmin("DrainServer", actor, "", "", req.Reason)
+	}
+
+	resp := &nanabushv1.DrainServerResponse{
+		Draining:      true,
+		DrainingSince: timestamppb.New(since),
+	}
+	if a.Translation.Running != nil {
+		resp.RunningJobs = int32(a.Translation.Running.Len())
+	}
+	a.Logger.Printf("DrainServer: draining since %v, running_jobs=%d", since.Format("15:04:05"), resp.RunningJobs)
+	return resp, nil
+}
+
+// ListDeadLetters returns job callbacks that could not be delivered.
+func (a *AdminService) ListDeadLetters(ctx context.Context, req *nanabushv1.ListDeadLettersRequest) (*nanabushv1.ListDeadLettersResponse, error) {
+	if _, err := a.authorize(ctx); err != nil {
+		return nil, err
+	}
+	a.Logger.Printf("ListDeadLetters request: job_id=%q, client_id=%q", req.JobId, req.ClientId)
+
+	resp := &nanabushv1.ListDeadLettersResponse{}
+	if a.Translation.Webhooks == nil {
+		return resp, nil
+	}
+	for _, letter := range a.Translation.Webhooks.DeadLetters() {
+		if (req.JobId == "" || letter.JobID == req.JobId) && (req.ClientId == "" || letter.ClientID == req.ClientId) {
+			resp.DeadLetters = append(resp.DeadLetters, deadLetterProto(letter))
+		}
+	}
+	return resp, nil
+}
+
+func clientInfoProto(client *ClientInfo) *nanabushv1.ClientInfo {
+	info := &nanabushv1.ClientInfo{
+		ClientId:          client.ClientID,
+		ClientName:        client.ClientName,
+		ClientVersion:     client.ClientVersion,
+		Namespace:         client.Namespace,
+		Metadata:          client.Metadata,
+		RegisteredAt:      timestamppb.New(client.RegisteredAt),
+		LastHeartbeat:     timestamppb.New(client.LastHeartbeat),
+		HeartbeatMetadata: client.HeartbeatMetadata,
+		SessionActive:     client.SessionActive,
+		VersionStatus:     client.VersionStatus.String(),
+	}
+	if !client.DisconnectedAt.IsZero() {
+		info.DisconnectedAt = timestamppb.New(client.DisconnectedAt)
+	}
+	return info
+}
+
+func runningJobProto(job jobs.RunningJob) *nanabushv1.JobInfo {
+	return &nanabushv1.JobInfo{
+		JobId:          job.JobID,
+		State:          nanabushv1.JobState_JOB_STATE_RUNNING,
+		Namespace:      job.Namespace,
+		ClientId:       job.ClientID,
+		Primitive:      job.Primitive,
+		SourceLanguage: job.SourceLanguage,
+		TargetLanguage: job.TargetLanguage,
+		StartedAt:      timestamppb.New(job.StartedAt),
+	}
+}
+
+func completedJobProto(job jobs.Job) *nanabushv1.JobInfo {
+	state := nanabushv1.JobState_JOB_STATE_COMPLETED
+	if job.Interrupted {
+		state = nanabushv1.JobState_JOB_STATE_INTERRUPTED
+	}
+	return &nanabushv1.JobInfo{
+		JobId:          job.JobID,
+		State:          state,
+		Namespace:      job.Namespace,
+		ClientId:       job.ClientID,
+		Primitive:      job.Primitive,
+		SourceLanguage: job.SourceLanguage,
+		TargetLanguage: job.TargetLanguage,
+		Model:          job.Model,
+		CompletedAt:    timestamppb.New(job.CompletedAt),
+	}
+}
+
+func deadLetterProto(letter webhook.DeadLetter) *nanabushv1.DeadLetter {
+	return &nanabushv1.DeadLetter{
+		DeliveryId:     letter.DeliveryID,
+		JobId:          letter.JobID,
+		ClientId:       letter.ClientID,
+		CallbackUrl:    letter.URL,
+		Attempts:       int32(letter.Attempts),
+		LastStatusCode: int32(letter.LastStatusCode),
+		LastError:      letter.LastError,
+		FailedAt:       timestamppb.New(letter.FailedAt),
+	}
+}
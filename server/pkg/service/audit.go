@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/dasmlab/nanabush/server/pkg/audit"
+	"github.com/dasmlab/nanabush/server/pkg/proto/v1"
+	"github.com/dasmlab/nanabush/server/pkg/sanitize"
+)
+
+// ClientIDMetadataKey is the gRPC metadata key clients use to send the
+// client_id returned by RegisterClient, so calls can be attributed in the audit log.
+const ClientIDMetadataKey = "nanabush-client-id"
+
+// clientFromContext returns the calling client's ID and registered name.
+// The name is empty when the client is not registered.
+func (s *TranslationService) clientFromContext(ctx context.Context) (string, string) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", ""
+	}
+	values := md.Get(ClientIDMetadataKey)
+	if len(values) == 0 || values[0] == "" {
+		return "", ""
+	}
+	clientID := values[0]
+
+	s.clientsMutex.RLock()
+	defer s.clientsMutex.RUnlock()
+	if client, ok := s.clients[clientID]; ok {
+		return clientID, client.ClientName
+	}
+	return clientID, ""
+}
+
+// newAuditRecord starts an audit record for an RPC with the caller filled in.
+func (s *TranslationService) newAuditRecord(ctx context.Context, rpc string) audit.Record {
+	clientID, clientName := s.clientFromContext(ctx)
+	return audit.Record{
+		RPC:        rpc,
+		ClientID:   clientID,
+		ClientName: clientName,
+		Model:      s.backendName(),
+	}
+}
+
+// writeAudit appends a record to the audit log, if one is configured.
+func (s *TranslationService) writeAudit(record audit.Record) {
+	if s.Audit == nil {
+		return
+	}
+	if err := s.Audit.Append(record); err != nil {
+		s.Logger.Printf("ERROR: audit log write failed: job_id=%q, err=%v", record.JobID, err)
+	}
+}
+
+// auditTranslate records the outcome of a Translate call.
+func (s *TranslationService) auditTranslate(ctx context.Context, req *nanabushv1.TranslateRequest, resp *nanabushv1.TranslateResponse, err error, masked map[string]int) {
+	if s.Audit == nil {
+		return
+	}
+
+	record := s.newAuditRecord(ctx, "Translate")
+	record.Namespace = req.Namespace
+	record.JobID = req.JobId
+	record.PageID = req.PageId
+	record.SourceWikiURI = req.SourceWikiUri
+	record.SourceLanguage = req.SourceLanguage
+	record.TargetLanguage = req.TargetLanguage
+	record.SourceSHA256 = audit.HashContent(requestText(req))
+	if len(masked) > 0 {
+		record.Masked = masked
+	}
+
+	switch {
+	case err != nil:
+		record.Outcome = audit.OutcomeRejected
+		record.Error = status.Convert(err).Message()
+	case resp.TranslationSkipped:
+		record.Outcome = audit.OutcomeSkipped
+	case resp.ErrorCode == nanabushv1.ErrorCode_ERROR_CODE_OUTPUT_REJECTED:
+		record.Outcome = audit.OutcomeRejected
+		record.Error = resp.ErrorMessage
+	case !resp.Success:
+		record.Outcome = audit.OutcomeFailed
+		record.Error = resp.ErrorMessage
+	default:
+		record.Outcome = audit.OutcomeSuccess
+	}
+	if resp != nil {
+		if resp.ResolvedSourceLanguage != "" {
+			record.SourceLanguage = resp.ResolvedSourceLanguage
+		}
+		if resp.ResolvedTargetLanguage != "" {
+			record.TargetLanguage = resp.ResolvedTargetLanguage
+		}
+		record.OutputSHA256 = audit.HashContent(responseText(resp))
+	}
+
+	s.writeAudit(record)
+}
+
+// responseText returns the translated content of a response, laid out like requestText.
+func responseText(resp *nanabushv1.TranslateResponse) string {
+	if resp.TranslatedMarkdown != "" {
+		return resp.TranslatedTitle + "\n\n" + resp.TranslatedMarkdown
+	}
+	return resp.TranslatedTitle
+}
+
+// addMaskCounts adds a job's masked value counts to counts.
+func addMaskCounts(counts map[string]int, mask *sanitize.Mask) {
+	if mask == nil || counts == nil {
+		return
+	}
+	for rule, n := range mask.Counts() {
+		counts[rule] += n
+	}
+}
+
+// auditedStream hashes the content passing through a TranslateStream call.
+type auditedStream struct {
+	nanabushv1.TranslationService_TranslateStreamServer
+
+	jobID       string
+	source      hash.Hash
+	output      hash.Hash
+	sourceBytes int
+	outputBytes int
+}
+
+func newAuditedStream(stream nanabushv1.TranslationService_TranslateStreamServer) *auditedStream {
+	return &auditedStream{
+		TranslationService_TranslateStreamServer: stream,
+		source:                                   sha256.New(),
+		output:                                   sha256.New(),
+	}
+}
+
+// Recv hashes received chunk content.
+func (a *auditedStream) Recv() (*nanabushv1.TranslateChunk, error) {
+	chunk, err := a.TranslationService_TranslateStreamServer.Recv()
+	if err == nil {
+		if a.jobID == "" {
+			a.jobID = chunk.JobId
+		}
+		a.source.Write([]byte(chunk.Content))
+		a.sourceBytes += len(chunk.Content)
+	}
+	return chunk, err
+}
+
+// Send hashes sent chunk content.
+func (a *auditedStream) Send(chunk *nanabushv1.TranslateChunk) error {
+	err := a.TranslationService_TranslateStreamServer.Send(chunk)
+	if err == nil {
+		a.output.Write([]byte(chunk.Content))
+		a.outputBytes += len(chunk.Content)
+	}
+	return err
+}
+
+// auditStream records the outcome of a TranslateStream call.
+func (s *TranslationService) auditStream(stream *auditedStream, err error) {
+	record := s.newAuditRecord(stream.Context(), "TranslateStream")
+	record.JobID = stream.jobID
+	if stream.sourceBytes > 0 {
+		record.SourceSHA256 = hex.EncodeToString(stream.source.Sum(nil))
+	}
+	if stream.outputBytes > 0 {
+		record.OutputSHA256 = hex.EncodeToString(stream.output.Sum(nil))
+	}
+	if err != nil {
+		record.Outcome = audit.OutcomeFailed
+		record.Error = status.Convert(err).Message()
+	} else {
+		record.Outcome = audit.OutcomeSuccess
+	}
+	s.writeAudit(record)
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/dasmlab/nanabush/server/pkg/proto/v1"
+)
+
+// MaxBatchSize is the maximum number of items accepted by TranslateBatch.
+const MaxBatchSize = 256
+
+// TranslateBatch translates many titles or documents in one call.
+// Items are submitted to the scheduler concurrently so the backend can batch
+// them; results keep request order and each item succeeds or fails on its own.
+func (s *TranslationService) TranslateBatch(ctx context.Context, req *nanabushv1.TranslateBatchRequest) (*nanabushv1.TranslateBatchResponse, error) {
+	s.Logger.Printf("TranslateBatch request: batch_id=%q, namespace=%q, items=%d", req.BatchId, req.Namespace, len(req.Requests))
+
+	if err := s.checkDraining(); err != nil {
+		return nil, err
+	}
+
+	// Validate request
+	if len(req.Requests) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "requests must not be empty")
+	}
+	if len(req.Requests) > MaxBatchSize {
+		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("batch has %d items, maximum is %d", len(req.Requests), MaxBatchSize))
+	}
+
+	results := make([]*nanabushv1.TranslateBatchResult, len(req.Requests))
+	seenJobIDs := make(map[string]int, len(req.Requests))
+
+	var wg sync.WaitGroup
+	for i, item := range req.Requests {
+		result := &nanabushv1.TranslateBatchResult{
+			Index: int32(i),
+			JobId: item.GetJobId(),
+		}
+		results[i] = result
+
+		if item == nil {
+			result.ErrorMessage = "request is empty"
+			result.ErrorCode = int32(codes.InvalidArgument)
+			continue
+		}
+		if first, dup := seenJobIDs[item.JobId]; dup && item.JobId != "" {
+			result.ErrorMessage = fmt.Sprintf("duplicate job_id %q (first used by item %d)", item.JobId, first)
+			result.ErrorCode = int32(codes.InvalidArgument)
+			continue
+		}
+		seenJobIDs[item.JobId] = i
+
+		// Items inherit the batch namespace unless they set their own
+		if item.Namespace == "" && req.Namespace != "" {
+			item = proto.Clone(item).(*nanabushv1.TranslateRequest)
+			item.Namespace = req.Namespace
+		}
+
+		wg.Add(1)
+		go func(item *nanabushv1.TranslateRequest, result *nanabushv1.TranslateBatchResult) {
+			defer wg.Done()
+			s.translateBatchItem(ctx, item, result)
+		}(item, result)
+	}
+	wg.Wait()
+
+	resp := &nanabushv1.TranslateBatchResponse{
+		BatchId:     req.BatchId,
+		Results:     results,
+		CompletedAt: timestamppb.Now(),
+	}
+	for _, result := range results {
+		if result.Success {
+			resp.Succeeded++
+		} else {
+			resp.Failed++
+		}
+	}
+
+	s.Logger.Printf("TranslateBatch response: batch_id=%q, succeeded=%d, failed=%d", req.BatchId, resp.Succeeded, resp.Failed)
+
+	return resp, nil
+}
+
+// translateBatchItem runs one batch item through Translate and records the outcome.
+func (s *TranslationService) translateBatchItem(ctx context.Context, item *nanabushv1.TranslateRequest, result *nanabushv1.TranslateBatchResult) {
+	resp, err := s.Translate(ctx, item)
+	if err != nil {
+		st := status.Convert(err)
+		result.ErrorMessage = st.Message()
+		result.ErrorCode = int32(st.Code())
+		return
+	}
+	result.Response = resp
+	result.Success = resp.Success
+	if !resp.Success {
+		result.ErrorMessage = resp.ErrorMessage
+	}
+}
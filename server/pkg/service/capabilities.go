@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+
+	"github.com/dasmlab/nanabush/server/pkg/proto/v1"
+	"github.com/dasmlab/nanabush/server/pkg/version"
+)
+
+// Feature names reported by GetCapabilities.
+const (
+	FeatureGlossary           = "glossary"
+	FeatureTranslationMemory  = "translation_memory"
+	FeaturePIIScrubbing       = "pii_scrubbing"
+	FeatureAutoDetect         = "auto_detect"
+	FeatureBatch              = "batch"
+	FeaturePriorityScheduling = "priority_scheduling"
+	FeatureFeedback           = "feedback"
+	FeatureQualityScoring     = "quality_scoring"
+)
+
+// DefaultMaxDocumentChars is the default limit on markdown accepted by Translate.
+const DefaultMaxDocumentChars = 1 << 20
+
+// ModelInfo describes a model served by a backend.
+type ModelInfo struct {
+	Name          string
+	ContextWindow int
+}
+
+// ModelLister is implemented by backends that can report their loaded models.
+type ModelLister interface {
+	// Models returns the models currently loaded by the backend
+	Models(ctx context.Context) ([]ModelInfo, error)
+}
+
+// GetCapabilities describes what this server supports.
+func (s *TranslationService) GetCapabilities(ctx context.Context, req *nanabushv1.GetCapabilitiesRequest) (*nanabushv1.GetCapabilitiesResponse, error) {
+	s.Logger.Println("GetCapabilities request")
+
+	resp := &nanabushv1.GetCapabilitiesResponse{
+		ServerVersion: version.Version,
+		Primitives: []nanabushv1.PrimitiveType{
+			nanabushv1.PrimitiveType_PRIMITIVE_TITLE,
+			nanabushv1.PrimitiveType_PRIMITIVE_DOC_TRANSLATE,
+		},
+		MaxDocumentChars:    int32(s.Settings().MaxDocumentChars),
+		MaxBatchSize:        MaxBatchSize,
+		MaxCollectionSize:   MaxCollectionSize,
+		StreamingSupported:  false, // TranslateStream does not call the backend yet
+		AutoDetectSupported: s.LanguageID != nil,
+		Features:            s.features(),
+	}
+
+	for _, pair := range s.Languages.Pairs() {
+		resp.LanguagePairs = append(resp.LanguagePairs, &nanabushv1.LanguagePair{
+			SourceLanguage: pair.Source.String(),
+			TargetLanguage: pair.Target.String(),
+		})
+	}
+
+	if lister, ok := s.Backend.(ModelLister); ok {
+		models, err := lister.Models(ctx)
+		if err != nil {
+			// Capabilities are still useful without the model list
+			s.Logger.Printf("GetCapabilities: listing backend models failed: %v", err)
+		}
+		for _, model := range models {
+			resp.Models = append(resp.Models, &nanabushv1.ModelInfo{
+				Name:                model.Name,
+				ContextWindowTokens: int32(model.ContextWindow),
+				Backend:             s.backendName(),
+			})
+		}
+	}
+
+	return resp, nil
+}
+
+// features reports which optional features are enabled.
+func (s *TranslationService) features() map[string]bool {
+	return map[string]bool{
+		FeatureGlossary:           s.glossarySupported(),
+		FeatureTranslationMemory:  false,
+		FeaturePIIScrubbing:       s.Sanitizer != nil,
+		FeatureAutoDetect:         s.LanguageID != nil,
+		FeatureBatch:              true,
+		FeaturePriorityScheduling: s.Scheduler != nil,
+		FeatureFeedback:           s.Feedback != nil && s.Sanitizer != nil,
+		FeatureQualityScoring:     s.Quality != nil,
+	}
+}
@@ -0,0 +1,330 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/dasmlab/nanabush/server/pkg/glossary"
+	"github.com/dasmlab/nanabush/server/pkg/langid"
+	"github.com/dasmlab/nanabush/server/pkg/proto/v1"
+)
+
+// MaxCollectionSize is the maximum number of documents accepted by TranslateCollection.
+const MaxCollectionSize = MaxBatchSize
+
+// CollectionMetadataKey is the DocumentContent.metadata key naming a page's collection.
+const CollectionMetadataKey = "collection"
+
+// collectionDetectionChars is how much collection text is used to detect its language.
+const collectionDetectionChars = 16 << 10
+
+// termLinePattern matches one numbered line of a translated term list.
+var termLinePattern = regexp.MustCompile(`(?m)^\s*(\d+)[.)]\s+(.+?)\s*$`)
+
+// TranslateCollection translates the pages of one collection together.
+// Terms that recur across pages are extracted and translated first, every
+// page is translated with the resulting glossary, and the response reports
+// how consistently each term was rendered. Pages succeed or fail on their
+// own, as in TranslateBatch.
+func (s *TranslationService) TranslateCollection(ctx context.Context, req *nanabushv1.TranslateCollectionRequest) (*nanabushv1.TranslateCollectionResponse, error) {
+	s.Logger.Printf("TranslateCollection request: collection_id=%q, namespace=%q, documents=%d", req.CollectionId, req.Namespace, len(req.Documents))
+
+	if err := s.checkDraining(); err != nil {
+		return nil, err
+	}
+	if err := checkCollection(req); err != nil {
+		return nil, err
+	}
+	if _, err := priorityFromContext(ctx); err != nil {
+		return nil, err
+	}
+
+	// Resolve the languages once so every page and the glossary agree
+	sourceLang, targetLang, err := s.collectionLanguages(req)
+	if err != nil {
+		s.Logger.Printf("TranslateCollection rejected: collection_id=%q, %v", req.CollectionId, err)
+		return nil, err
+	}
+
+	maxTerms := int(req.MaxGlossaryTerms)
+	if maxTerms == 0 {
+		maxTerms = glossary.DefaultMaxTerms
+	}
+	pairs, provided, glossaryErr := s.collectionGlossary(ctx, req, sourceLang, targetLang, maxTerms)
+
+	results := make([]*nanabushv1.TranslateBatchResult, len(req.Documents))
+	var wg sync.WaitGroup
+	for i, doc := range req.Documents {
+		item := &nanabushv1.TranslateRequest{
+			JobId:          collectionJobID(req.CollectionId, i),
+			Namespace:      req.Namespace,
+			Primitive:      nanabushv1.PrimitiveType_PRIMITIVE_DOC_TRANSLATE,
+			Source:         &nanabushv1.TranslateRequest_Doc{Doc: doc},
+			SourceLanguage: sourceLang,
+			TargetLanguage: targetLang,
+			PageSlug:       doc.Slug,
+			Glossary:       glossaryTerms(glossary.Relevant(pairs, documentText(doc))),
+		}
+		result := &nanabushv1.TranslateBatchResult{
+			Index: int32(i),
+			JobId: item.JobId,
+		}
+		results[i] = result
+
+		wg.Add(1)
+		go func(item *nanabushv1.TranslateRequest, result *nanabushv1.TranslateBatchResult) {
+			defer wg.Done()
+			s.translateBatchItem(ctx, item, result)
+		}(item, result)
+	}
+	wg.Wait()
+
+	resp := &nanabushv1.TranslateCollectionResponse{
+		CollectionId: req.CollectionId,
+		Glossary:     glossaryTerms(pairs),
+		Results:      results,
+		CompletedAt:  timestamppb.Now(),
+	}
+	if glossaryErr != nil {
+		resp.GlossaryError = status.Convert(glossaryErr).Message()
+	}
+	var pages []glossary.Page
+	for i, result := range results {
+		if !result.Success {
+			resp.Failed++
+			continue
+		}
+		resp.Succeeded++
+		pages = append(pages, glossary.Page{
+			Index:       i,
+			Source:      documentText(req.Documents[i]),
+			Translation: result.Response.TranslatedTitle + "\n\n" + result.Response.TranslatedMarkdown,
+		})
+	}
+	resp.Report = consistencyReport(glossary.Check(pairs, pages), provided)
+
+	s.Logger.Printf("TranslateCollection response: collection_id=%q, succeeded=%d, failed=%d, terms=%d, consistency=%.3f",
+		req.CollectionId, resp.Succeeded, resp.Failed, len(pairs), resp.Report.Score)
+
+	return resp, nil
+}
+
+// checkCollection validates a TranslateCollection request.
+func checkCollection(req *nanabushv1.TranslateCollectionRequest) error {
+	if req.CollectionId == "" {
+		return status.Error(codes.InvalidArgument, "collection_id is required")
+	}
+	if req.TargetLanguage == "" {
+		return status.Error(codes.InvalidArgument, "target_language is required")
+	}
+	if req.SourceLanguage == "" {
+		return status.Error(codes.InvalidArgument, "source_language is required")
+	}
+	if len(req.Documents) == 0 {
+		return status.Error(codes.InvalidArgument, "documents must not be empty")
+	}
+	if len(req.Documents) > MaxCollectionSize {
+		return status.Error(codes.InvalidArgument, fmt.Sprintf("collection has %d documents, maximum is %d", len(req.Documents), MaxCollectionSize))
+	}
+	for i, doc := range req.Documents {
+		if doc.GetTitle() == "" && doc.GetMarkdown() == "" {
+			return status.Error(codes.InvalidArgument, fmt.Sprintf("documents[%d] is empty", i))
+		}
+		if collection, ok := doc.Metadata[CollectionMetadataKey]; ok && collection != req.CollectionId {
+			return status.Error(codes.InvalidArgument, fmt.Sprintf("documents[%d] belongs to collection %q, not %q", i, collection, req.CollectionId))
+		}
+	}
+	if err := checkGlossary("glossary", req.Glossary); err != nil {
+		return err
+	}
+	if req.MaxGlossaryTerms < 0 {
+		return status.Error(codes.InvalidArgument, "max_glossary_terms must not be negative")
+	}
+	if int(req.MaxGlossaryTerms)+len(req.Glossary) > MaxGlossaryTerms {
+		return status.Error(codes.InvalidArgument, fmt.Sprintf("max_glossary_terms plus glossary terms must not exceed %d", MaxGlossaryTerms))
+	}
+	return nil
+}
+
+// collectionLanguages resolves the collection's language pair. An "auto"
+// source is detected once from the collection's text; it stays "auto" when
+// the collection is already in the target language so each page is skipped.
+func (s *TranslationService) collectionLanguages(req *nanabushv1.TranslateCollectionRequest) (string, string, error) {
+	targetLang, err := parseLanguageTag("target_language", req.TargetLanguage)
+	if err != nil {
+		return "", "", err
+	}
+
+	sourceLang := req.SourceLanguage
+	if isAutoLanguage(sourceLang) {
+		var sample strings.Builder
+		for _, doc := range req.Documents {
+			if sample.Len() >= collectionDetectionChars {
+				break
+			}
+			sample.WriteString(documentText(doc))
+			sample.WriteString("\n\n")
+		}
+		result, err := s.detectLanguage(sample.String())
+		if err != nil {
+			return "", "", err
+		}
+		s.Logger.Printf("Detected collection language: collection_id=%q, language=%q, confidence=%.2f", req.CollectionId, result.Language, result.Confidence)
+		if result.Confidence >= skipConfidence && langid.SameLanguage(result.Language, targetLang) {
+			return sourceLang, targetLang, nil
+		}
+		sourceLang = result.Language
+	}
+
+	pair, err := s.resolveLanguagePair(sourceLang, targetLang)
+	if err != nil {
+		return "", "", err
+	}
+	return pair.Source.String(), pair.Target.String(), nil
+}
+
+// collectionGlossary builds the glossary for a collection: the request's
+// terms, then up to maxTerms recurring terms extracted from the documents and
+// translated in one backend call. It also returns the number of request terms,
+// which come first, and why translating the extracted terms failed. A failure
+// leaves only the request's terms, since the pages can still be translated
+// without them.
+func (s *TranslationService) collectionGlossary(ctx context.Context, req *nanabushv1.TranslateCollectionRequest, sourceLang, targetLang string, maxTerms int) ([]glossary.Pair, int, error) {
+	pairs := make([]glossary.Pair, 0, len(req.Glossary)+maxTerms)
+	seen := make(map[string]bool, len(req.Glossary))
+	for _, term := range req.Glossary {
+		source := strings.TrimSpace(term.Source)
+		pairs = append(pairs, glossary.Pair{Source: source, Target: strings.TrimSpace(term.Target)})
+		seen[strings.ToLower(source)] = true
+	}
+	provided := len(pairs)
+
+	// Nothing to translate when the collection is already in the target language
+	if isAutoLanguage(sourceLang) {
+		return pairs, provided, nil
+	}
+
+	texts := make([]string, len(req.Documents))
+	for i, doc := range req.Documents {
+		texts[i] = documentText(doc)
+	}
+	var sources []string
+	for _, term := range glossary.Extract(texts, sourceLang, maxTerms) {
+		// Terms holding values the sanitizer masks are not sent to the backend
+		if seen[strings.ToLower(term.Source)] || utf8.RuneCountInString(term.Source) > MaxGlossaryTermChars || s.masks(term.Source) {
+			continue
+		}
+		sources = append(sources, term.Source)
+	}
+	if len(sources) == 0 {
+		return pairs, provided, nil
+	}
+
+	targets, err := s.translateTerms(ctx, req, sources, sourceLang, targetLang)
+	if err != nil {
+		s.Logger.Printf("TranslateCollection glossary failed: collection_id=%q, err=%v", req.CollectionId, err)
+		return pairs, provided, err
+	}
+	for i, source := range sources {
+		target := targets[i]
+		if target == "" || utf8.RuneCountInString(target) > MaxGlossaryTermChars || s.masks(target) {
+			continue
+		}
+		pairs = append(pairs, glossary.Pair{Source: source, Target: target})
+	}
+	s.Logger.Printf("TranslateCollection glossary: collection_id=%q, provided=%d, extracted=%d", req.CollectionId, provided, len(pairs)-provided)
+	return pairs, provided, nil
+}
+
+// translateTerms translates terms as one job, sent as a numbered list so
+// the model sees them together. The job runs through Translate like the
+// pages, so it is scheduled, tracked, sanitized, guarded and audited the
+// same way. Terms missing from the reply come back empty.
+func (s *TranslationService) translateTerms(ctx context.Context, req *nanabushv1.TranslateCollectionRequest, terms []string, sourceLang, targetLang string) ([]string, error) {
+	var list strings.Builder
+	for i, term := range terms {
+		fmt.Fprintf(&list, "%d. %s\n", i+1, term)
+	}
+
+	resp, err := s.Translate(ctx, &nanabushv1.TranslateRequest{
+		JobId:          glossaryJobID(req.CollectionId),
+		Namespace:      req.Namespace,
+		Primitive:      nanabushv1.PrimitiveType_PRIMITIVE_DOC_TRANSLATE,
+		Source:         &nanabushv1.TranslateRequest_Doc{Doc: &nanabushv1.DocumentContent{Markdown: list.String()}},
+		SourceLanguage: sourceLang,
+		TargetLanguage: targetLang,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, errors.New(resp.ErrorMessage)
+	}
+
+	targets := make([]string, len(terms))
+	for _, match := range termLinePattern.FindAllStringSubmatch(resp.TranslatedMarkdown, -1) {
+		n, err := strconv.Atoi(match[1])
+		if err != nil || n < 1 || n > len(terms) || targets[n-1] != "" {
+			continue
+		}
+		targets[n-1] = strings.Trim(match[2], " *_\"'")
+	}
+	return targets, nil
+}
+
+// consistencyReport converts a glossary report; the first provided terms
+// came from the request.
+func consistencyReport(report glossary.Report, provided int) *nanabushv1.ConsistencyReport {
+	out := &nanabushv1.ConsistencyReport{
+		Score:             report.Score,
+		InconsistentTerms: int32(report.InconsistentTerms),
+	}
+	for i, usage := range report.Terms {
+		term := &nanabushv1.TermConsistency{
+			Source:              usage.Source,
+			Target:              usage.Target,
+			Documents:           int32(usage.Documents),
+			ConsistentDocuments: int32(usage.Consistent),
+			Provided:            i < provided,
+		}
+		for _, index := range usage.Inconsistent {
+			term.InconsistentDocuments = append(term.InconsistentDocuments, int32(index))
+		}
+		out.Terms = append(out.Terms, term)
+	}
+	return out
+}
+
+// glossaryTerms converts glossary pairs to their proto form.
+func glossaryTerms(pairs []glossary.Pair) []*nanabushv1.GlossaryTerm {
+	terms := make([]*nanabushv1.GlossaryTerm, 0, len(pairs))
+	for _, pair := range pairs {
+		terms = append(terms, &nanabushv1.GlossaryTerm{Source: pair.Source, Target: pair.Target})
+	}
+	return terms
+}
+
+// collectionJobID is the job ID of one page of a collection.
+func collectionJobID(collectionID string, index int) string {
+	return fmt.Sprintf("%s/%d", collectionID, index)
+}
+
+// glossaryJobID is the job ID of the glossary translation of a collection.
+func glossaryJobID(collectionID string) string {
+	return collectionID + "/glossary"
+}
+
+// documentText returns the text of a document used for term extraction.
+func documentText(doc *nanabushv1.DocumentContent) string {
+	return doc.Title + "\n\n" + doc.Markdown
+}
@@ -0,0 +1,206 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/dasmlab/nanabush/server/pkg/backend/replay"
+	"github.com/dasmlab/nanabush/server/pkg/proto/v1"
+	"github.com/dasmlab/nanabush/server/pkg/service"
+)
+
+var quiet = log.New(io.Discard, "", 0)
+
+// phraseBackend translates by replacing phrases, longest listed first, and
+// fails any content starting with failPrefix.
+type phraseBackend struct {
+	phrases    [][2]string
+	failPrefix string
+	calls      atomic.Int32 // Pages are translated concurrently
+}
+
+func (b *phraseBackend) translate(text string) (string, error) {
+	b.calls.Add(1)
+	if b.failPrefix != "" && strings.HasPrefix(text, b.failPrefix) {
+		return "", errors.New("model overloaded")
+	}
+	for _, phrase := range b.phrases {
+		text = strings.ReplaceAll(text, phrase[0], phrase[1])
+	}
+	return text, nil
+}
+
+func (b *phraseBackend) TranslateTitle(ctx context.Context, title, sourceLang, targetLang string) (string, error) {
+	return b.translate(title)
+}
+
+func (b *phraseBackend) TranslateDocument(ctx context.Context, doc *nanabushv1.DocumentContent, sourceLang, targetLang string) (*nanabushv1.DocumentContent, error) {
+	title, err := b.translate(doc.Title)
+	if err != nil {
+		return nil, err
+	}
+	markdown, err := b.translate(doc.Markdown)
+	if err != nil {
+		return nil, err
+	}
+	return &nanabushv1.DocumentContent{Title: title, Markdown: markdown}, nil
+}
+
+func (b *phraseBackend) CheckHealth(ctx context.Context) error {
+	return nil
+}
+
+// The third page renders "Release Manager" its own way, so it is inconsistent.
+var collectionPhrases = [][2]string{
+	{"Only the Release Manager signs off.", "Seul le gestionnaire de version approuve."},
+	{"Release Manager", "responsable des versions"},
+	{"Deploying with", "Déployer avec"},
+	{"Rollbacks", "Retours arrière"},
+	{"Approvals", "Approbations"},
+	{"The", "Le"},
+	{"approves each", "approuve chaque"},
+	{"change", "changement"},
+	{"Ask the", "Demandez au"},
+	{"before reverting a", "avant d'annuler un"},
+}
+
+func collectionRequest() *nanabushv1.TranslateCollectionRequest {
+	return &nanabushv1.TranslateCollectionRequest{
+		CollectionId:   "runbooks",
+		SourceLanguage: "en",
+		TargetLanguage: "fr",
+		Documents: []*nanabushv1.DocumentContent{
+			{Title: "Deploying with GitOps", Markdown: "The Release Manager approves each GitOps change."},
+			{Title: "Rollbacks", Markdown: "Ask the Release Manager before reverting a GitOps commit."},
+			{Title: "Approvals", Markdown: "Only the Release Manager signs off."},
+		},
+	}
+}
+
+func newCollectionService(backend service.TranslatorBackend) *service.TranslationService {
+	s := service.NewTranslationService(backend, quiet)
+	s.Guard = nil // The phrase backend's output is not real French
+	return s
+}
+
+// TestTranslateCollectionReplay records a collection through the replay
+// backend, then translates it again from the fixtures alone.
+func TestTranslateCollectionReplay(t *testing.T) {
+	const model = "test-model"
+	dir := t.TempDir()
+	backend := &phraseBackend{phrases: collectionPhrases}
+	recorder, err := replay.NewRecorder(dir, model, backend, quiet)
+	if err != nil {
+		t.Fatalf("NewRecorder: %v", err)
+	}
+	recording, err := replay.NewReplayer(dir, model)
+	if err != nil {
+		t.Fatalf("NewReplayer: %v", err)
+	}
+	recording.Fallback = recorder
+
+	recorded, err := newCollectionService(recording).TranslateCollection(context.Background(), collectionRequest())
+	if err != nil {
+		t.Fatalf("TranslateCollection (recording): %v", err)
+	}
+
+	replaying, err := replay.NewReplayer(dir, model)
+	if err != nil {
+		t.Fatalf("NewReplayer: %v", err)
+	}
+	calls := backend.calls.Load()
+	replayed, err := newCollectionService(replaying).TranslateCollection(context.Background(), collectionRequest())
+	if err != nil {
+		t.Fatalf("TranslateCollection (replaying): %v", err)
+	}
+	if n := backend.calls.Load() - calls; n != 0 {
+		t.Errorf("backend called %d times while replaying, want 0", n)
+	}
+
+	for name, resp := range map[string]*nanabushv1.TranslateCollectionResponse{"recorded": recorded, "replayed": replayed} {
+		if resp.Succeeded != 3 || resp.Failed != 0 || resp.GlossaryError != "" {
+			t.Fatalf("%s: succeeded=%d, failed=%d, glossary_error=%q; want 3 pages and no error", name, resp.Succeeded, resp.Failed, resp.GlossaryError)
+		}
+		wantGlossary := []*nanabushv1.GlossaryTerm{
+			{Source: "Release Manager", Target: "responsable des versions"},
+			{Source: "GitOps", Target: "GitOps"},
+		}
+		if !equalTerms(resp.Glossary, wantGlossary) {
+			t.Errorf("%s: glossary %v, want %v", name, resp.Glossary, wantGlossary)
+		}
+		wantReport := &nanabushv1.ConsistencyReport{
+			Score: 0.8,
+			Terms: []*nanabushv1.TermConsistency{
+				{Source: "Release Manager", Target: "responsable des versions", Documents: 3, ConsistentDocuments: 2, InconsistentDocuments: []int32{2}},
+				{Source: "GitOps", Target: "GitOps", Documents: 2, ConsistentDocuments: 2},
+			},
+			InconsistentTerms: 1,
+		}
+		if !proto.Equal(resp.Report, wantReport) {
+			t.Errorf("%s: report %v, want %v", name, resp.Report, wantReport)
+		}
+		for i, result := range resp.Results {
+			if want := fmt.Sprintf("runbooks/%d", i); result.JobId != want {
+				t.Errorf("%s: results[%d].job_id = %q, want %q", name, i, result.JobId, want)
+			}
+		}
+	}
+	for i := range recorded.Results {
+		got, want := replayed.Results[i].Response.TranslatedMarkdown, recorded.Results[i].Response.TranslatedMarkdown
+		if got != want {
+			t.Errorf("page %d replayed as %q, recorded as %q", i, got, want)
+		}
+	}
+}
+
+func TestTranslateCollectionGlossaryError(t *testing.T) {
+	tests := []struct {
+		name      string
+		documents int // Pages of collectionRequest used
+		wantError string
+	}{
+		{"term translation fails", 3, "model overloaded"},
+		{"no recurring terms", 1, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &phraseBackend{phrases: collectionPhrases, failPrefix: "1. "}
+			req := collectionRequest()
+			req.Documents = req.Documents[:tt.documents]
+
+			resp, err := newCollectionService(backend).TranslateCollection(context.Background(), req)
+			if err != nil {
+				t.Fatalf("TranslateCollection: %v", err)
+			}
+			if int(resp.Succeeded) != tt.documents {
+				t.Errorf("succeeded=%d, want %d: the pages are translated without the glossary", resp.Succeeded, tt.documents)
+			}
+			if !strings.Contains(resp.GlossaryError, tt.wantError) || (tt.wantError == "") != (resp.GlossaryError == "") {
+				t.Errorf("glossary_error = %q, want %q", resp.GlossaryError, tt.wantError)
+			}
+			if len(resp.Glossary) != 0 {
+				t.Errorf("glossary %v, want none", resp.Glossary)
+			}
+		})
+	}
+}
+
+func equalTerms(a, b []*nanabushv1.GlossaryTerm) bool {
+	var x, y [][2]string
+	for _, term := range a {
+		x = append(x, [2]string{term.Source, term.Target})
+	}
+	for _, term := range b {
+		y = append(y, [2]string{term.Source, term.Target})
+	}
+	return reflect.DeepEqual(x, y)
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/dasmlab/nanabush/server/pkg/langid"
+	"github.com/dasmlab/nanabush/server/pkg/proto/v1"
+)
+
+// AutoSourceLanguage asks the server to detect the source language.
+const AutoSourceLanguage = "auto"
+
+const (
+	// minDetectionConfidence is the confidence required to accept a detected source
+	// language: a 7.5% margin over the runner-up for text of 40 letters or more.
+	// 95% of held-out messages clear it, and 0.3% of those are misidentified
+	// (see pkg/langid/CORPUS.md).
+	minDetectionConfidence = 0.3
+
+	// skipConfidence is the confidence required before a translation is skipped
+	// because the content is already in the target language.
+	skipConfidence = 0.5
+)
+
+var (
+	// codeBlockPattern matches fenced and inline code, which would skew detection.
+	codeBlockPattern = regexp.MustCompile("(?s)```.*?```|`[^`\n]*`")
+	// urlPattern matches URLs and markdown link targets.
+	urlPattern = regexp.MustCompile(`\]\([^)]*\)|https?://\S+`)
+)
+
+// isAutoLanguage reports whether lang requests source language detection.
+func isAutoLanguage(lang string) bool {
+	return strings.EqualFold(strings.TrimSpace(lang), AutoSourceLanguage)
+}
+
+// detectLanguage identifies the language of text, returning codes.InvalidArgument
+// when it cannot be determined with enough confidence.
+func (s *TranslationService) detectLanguage(text string) (langid.Result, error) {
+	if s.LanguageID == nil {
+		return langid.Result{}, status.Error(codes.Unimplemented, "source language detection is not available")
+	}
+
+	text = codeBlockPattern.ReplaceAllString(text, " ")
+	text = urlPattern.ReplaceAllString(text, " ")
+	result := s.LanguageID.Detect(text)
+	if result.Language == langid.Undetermined || result.Confidence < minDetectionConfidence {
+		return result, status.Error(codes.InvalidArgument,
+			fmt.Sprintf("unable to detect source language (best guess %q, confidence %.2f); set source_language explicitly",
+				result.Language, result.Confidence))
+	}
+	return result, nil
+}
+
+// requestText returns the text of a translation request used for detection.
+func requestText(req *nanabushv1.TranslateRequest) string {
+	if doc := req.GetDoc(); doc != nil {
+		return doc.Title + "\n\n" + doc.Markdown
+	}
+	return req.GetTitle()
+}
+
+// skippedResponse returns the request content unchanged because it is already
+// in the target language.
+func skippedResponse(req *nanabushv1.TranslateRequest, detection langid.Result) *nanabushv1.TranslateResponse {
+	resp := &nanabushv1.TranslateResponse{
+		JobId:                  req.JobId,
+		Success:                true,
+		CompletedAt:            timestamppb.Now(),
+		DetectedSourceLanguage: detection.Language,
+		DetectionConfidence:    detection.Confidence,
+		TranslationSkipped:     true,
+	}
+	if doc := req.GetDoc(); doc != nil {
+		resp.TranslatedTitle = doc.Title
+		resp.TranslatedMarkdown = doc.Markdown
+	} else {
+		resp.TranslatedTitle = req.GetTitle()
+	}
+	return resp
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/dasmlab/nanabush/server/pkg/proto/v1"
+	"github.com/dasmlab/nanabush/server/pkg/scheduler"
+)
+
+// QueueDepthMetadataKey is the HeartbeatRequest metadata key clients use to
+// report how many jobs they have waiting to send. Backend slots are shared
+// between clients in proportion to it.
+const QueueDepthMetadataKey = "queue_depth"
+
+// Load thresholds, as (running + queued jobs) / backend slots.
+const (
+	elevatedLoad = 1.0
+	highLoad     = 3.0
+)
+
+// maxIntervalScale caps how far the heartbeat interval is stretched under load.
+const maxIntervalScale = 2
+
+// withDirectivesLocked fills in the server directives of a heartbeat
+// response: drain notice, minimum client version, backpressure and an
+// interval stretched under load. clientID may be empty for unknown clients.
+// s.clientsMutex must be held.
+func (s *TranslationService) withDirectivesLocked(resp *nanabushv1.HeartbeatResponse, clientID string) *nanabushv1.HeartbeatResponse {
+	settings := s.Settings()
+	resp.Drain = s.Draining()
+	resp.MinClientVersion = settings.VersionPolicy.MinimumString()
+	if s.Scheduler == nil {
+		return resp
+	}
+
+	stats := s.Scheduler.Stats()
+	load := float64(stats.Running+stats.QueueDepth) / float64(stats.MaxConcurrent)
+	backpressure := &nanabushv1.Backpressure{
+		Level:      nanabushv1.BackpressureLevel_BACKPRESSURE_LEVEL_NONE,
+		QueueDepth: int32(stats.QueueDepth),
+	}
+	switch {
+	case load >= highLoad:
+		backpressure.Level = nanabushv1.BackpressureLevel_BACKPRESSURE_LEVEL_HIGH
+	case load >= elevatedLoad && stats.QueueDepth > 0:
+		backpressure.Level = nanabushv1.BackpressureLevel_BACKPRESSURE_LEVEL_ELEVATED
+	}
+	if backpressure.Level != nanabushv1.BackpressureLevel_BACKPRESSURE_LEVEL_NONE {
+		backpressure.MaxConcurrentJobs = s.concurrencyShareLocked(clientID, stats.MaxConcurrent)
+		if wait := s.Scheduler.EstimateWait(scheduler.PriorityBulk); wait > 0 {
+			backpressure.RetryAfterSeconds = ceilSeconds(wait)
+		}
+	}
+	resp.Backpressure = backpressure
+
+	// Fewer heartbeats while the server is busy, growing with load up to
+	// maxIntervalScale. Not while draining, so clients hear about it promptly.
+	if load > elevatedLoad && !resp.Drain {
+		scale := math.Min(load, maxIntervalScale)
+		resp.HeartbeatIntervalSeconds = int32(float64(settings.heartbeatSeconds()) * scale)
+	}
+	return resp
+}
+
+// concurrencyShareLocked divides backend slots between registered clients in
+// proportion to the queue depth they report; clients that report nothing
+// count as one queued job and inactive clients are ignored. Every client gets at least one slot.
+// s.clientsMutex must be held.
+func (s *TranslationService) concurrencyShareLocked(clientID string, slots int) int32 {
+	mine := 1
+	total := 0
+	for id, client := range s.clients {
+		if !client.DisconnectedAt.IsZero() && id != clientID {
+			continue // Inactive clients do not take a share
+		}
+		depth := reportedQueueDepth(client)
+		total += depth
+		if id == clientID {
+			mine = depth
+		}
+	}
+	if _, known := s.clients[clientID]; !known {
+		total += mine
+	}
+	share := int32(math.Ceil(float64(slots) * float64(mine) / float64(total)))
+	if share < 1 {
+		share = 1
+	}
+	return share
+}
+
+// reportedQueueDepth returns the client's last reported queue depth, at least 1.
+func reportedQueueDepth(client *ClientInfo) int {
+	depth, err := strconv.Atoi(client.HeartbeatMetadata[QueueDepthMetadataKey])
+	if err != nil || depth < 1 {
+		return 1
+	}
+	return depth
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/dasmlab/nanabush/server/pkg/jobs"
+	"github.com/dasmlab/nanabush/server/pkg/proto/v1"
+)
+
+// Drain stops the service accepting new work. Running jobs are unaffected.
+// It returns when draining started and whether this call started it.
+func (s *TranslationService) Drain() (time.Time, bool) {
+	s.drainMutex.Lock()
+	if !s.drainingSince.IsZero() {
+		s.drainMutex.Unlock()
+		return s.drainingSince, false
+	}
+	s.drainingSince = time.Now()
+	since := s.drainingSince
+	s.drainMutex.Unlock()
+
+	// Tell session clients right away rather than at their next check
+	s.pokeSessions()
+	return since, true
+}
+
+// DrainingSince returns when draining started, or the zero time if the
+// service is not draining.
+func (s *TranslationService) DrainingSince() time.Time {
+	s.drainMutex.Lock()
+	defer s.drainMutex.Unlock()
+	return s.drainingSince
+}
+
+// Draining reports whether the service has stopped accepting new work.
+func (s *TranslationService) Draining() bool {
+	return !s.DrainingSince().IsZero()
+}
+
+// checkDraining rejects new work while draining, so clients retry on another replica.
+func (s *TranslationService) checkDraining() error {
+	if s.Draining() {
+		return status.Error(codes.Unavailable, "server is draining, retry on another replica")
+	}
+	return nil
+}
+
+// trackJob registers a Translate call as running until the returned function
+// is called. The returned context is cancelled if an administrator cancels the job.
+func (s *TranslationService) trackJob(ctx context.Context, req *nanabushv1.TranslateRequest) (context.Context, func()) {
+	if s.Running == nil || req.GetJobId() == "" {
+		return ctx, func() {}
+	}
+	clientID, _ := s.clientFromContext(ctx)
+	return s.Running.Start(ctx, jobs.RunningJob{
+		JobID:          req.JobId,
+		Namespace:      req.Namespace,
+		ClientID:       clientID,
+		Primitive:      req.Primitive,
+		SourceLanguage: req.SourceLanguage,
+		TargetLanguage: req.TargetLanguage,
+	})
+}
+
+// cancelledJobError is returned to callers whose job was cancelled by an administrator.
+func cancelledJobError(jobID string) error {
+	return status.Error(codes.Canceled, fmt.Sprintf("job %q was cancelled by an administrator", jobID))
+}
+
+// interruptedJobError is returned to callers whose job was interrupted by a shutdown.
+func interruptedJobError(jobID string) error {
+	return status.Error(codes.Unavailable, fmt.Sprintf("server shut down before job %q finished, retry on another replica", jobID))
+}
+
+// drainProgressInterval is how often WaitForJobs logs the jobs it is waiting for.
+const drainProgressInterval = 5 * time.Second
+
+// DrainDeadline returns when WaitForJobs stops waiting for running jobs, or
+// the zero time if it has not been called.
+func (s *TranslationService) DrainDeadline() time.Time {
+	s.drainMutex.Lock()
+	defer s.drainMutex.Unlock()
+	return s.drainDeadline
+}
+
+// InterruptedJobs returns how many jobs InterruptJobs has interrupted.
+func (s *TranslationService) InterruptedJobs() int {
+	s.drainMutex.Lock()
+	defer s.drainMutex.Unlock()
+	return s.interruptedJobs
+}
+
+// WaitForJobs waits for running jobs to finish, until ctx is done, logging
+// progress as they do. The service should be draining so no new jobs start.
+// It reports whether every job finished.
+func (s *TranslationService) WaitForJobs(ctx context.Context) bool {
+	if s.Running == nil {
+		return true
+	}
+
+	deadline, hasDeadline := ctx.Deadline()
+	if hasDeadline {
+		s.drainMutex.Lock()
+		s.drainDeadline = deadline
+		s.drainMutex.Unlock()
+	}
+
+	start := time.Now()
+	running := s.Running.Len()
+	if running == 0 {
+		s.Logger.Println("Drain: no running jobs")
+		return true
+	}
+	if hasDeadline {
+		s.Logger.Printf("Drain: waiting for %d running jobs, deadline in %v", running, time.Until(deadline).Round(time.Second))
+	} else {
+		s.Logger.Printf("Drain: waiting for %d running jobs", running)
+	}
+
+	ticker := time.NewTicker(drainProgressInterval)
+	defer ticker.Stop()
+
+	done := make(chan error, 1)
+	go func() { done <- s.Running.Wait(ctx) }()
+
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				s.Logger.Printf("Drain: deadline reached after %v, %d of %d jobs still running",
+					time.Since(start).Round(time.Second), s.Running.Len(), running)
+				return false
+			}
+			s.Logger.Printf("Drain: all %d jobs finished after %v", running, time.Since(start).Round(time.Second))
+			return true
+		case <-ticker.C:
+			remaining := s.Running.List()
+			ids := make([]string, 0, len(remaining))
+			for _, job := range remaining {
+				ids = append(ids, job.JobID)
+			}
+			if hasDeadline {
+				s.Logger.Printf("Drain: %d of %d jobs still running, %v left: %v",
+					len(remaining), running, time.Until(deadline).Round(time.Second), ids)
+			} else {
+				s.Logger.Printf("Drain: %d of %d jobs still running: %v", len(remaining), running, ids)
+			}
+		}
+	}
+}
+
+// InterruptJobs cancels the jobs still running when the drain deadline
+// passes. Their callers get Unavailable so they resubmit elsewhere (through
+// the callback for SubmitTranslate jobs), and each job is recorded in the job
+// store as interrupted. It returns the jobs.
+func (s *TranslationService) InterruptJobs() []jobs.RunningJob {
+	if s.Running == nil {
+		return nil
+	}
+
+	interrupted := s.Running.Interrupt()
+	now := time.Now()
+	for _, job := range interrupted {
+		s.Logger.Printf("Drain: interrupted job_id=%q, namespace=%q, client_id=%q, running for %v",
+			job.JobID, job.Namespace, job.ClientID, now.Sub(job.StartedAt).Round(time.Second))
+		if s.Jobs != nil {
+			s.Jobs.Put(jobs.Job{
+				JobID:          job.JobID,
+				Namespace:      job.Namespace,
+				ClientID:       job.ClientID,
+				Primitive:      job.Primitive,
+				SourceLanguage: job.SourceLanguage,
+				TargetLanguage: job.TargetLanguage,
+				Interrupted:    true,
+				CompletedAt:    now,
+			})
+		}
+	}
+
+	s.drainMutex.Lock()
+	s.interruptedJobs += len(interrupted)
+	s.drainMutex.Unlock()
+	return interrupted
+}
+
+// CloseSessions ends every open Session so clients reconnect elsewhere.
+func (s *TranslationService) CloseSessions() {
+	s.clientsMutex.RLock()
+	defer s.clientsMutex.RUnlock()
+
+	for _, sess := range s.sessions {
+		sess.stop(status.Error(codes.Unavailable, "server shutting down"))
+	}
+}
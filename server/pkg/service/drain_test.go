@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+
+	"github.com/dasmlab/nanabush/server/pkg/proto/v1"
+	"github.com/dasmlab/nanabush/server/pkg/webhook"
+)
+
+// blockingBackend translates nothing: each call waits until its context is done.
+type blockingBackend struct {
+	started chan struct{}
+}
+
+func (b *blockingBackend) TranslateTitle(ctx context.Context, title, sourceLang, targetLang string) (string, error) {
+	b.started <- struct{}{}
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func (b *blockingBackend) TranslateDocument(ctx context.Context, doc *nanabushv1.DocumentContent, sourceLang, targetLang string) (*nanabushv1.DocumentContent, error) {
+	b.started <- struct{}{}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (b *blockingBackend) CheckHealth(ctx context.Context) error {
+	return nil
+}
+
+func TestInterruptedSubmitTranslate(t *testing.T) {
+	const secret = "test-secret"
+	callbacks := make(chan *nanabushv1.JobCallback, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		callback, err := webhook.Receive(secret, r)
+		if err != nil {
+			t.Errorf("Receive: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		callbacks <- callback
+	}))
+	defer receiver.Close()
+
+	dispatcher, err := webhook.New(webhook.Config{Secret: secret, AllowedHosts: []string{"127.0.0.1"}}, discard)
+	if err != nil {
+		t.Fatalf("webhook.New: %v", err)
+	}
+	backend := &blockingBackend{started: make(chan struct{}, 1)}
+	s := NewTranslationService(backend, discard)
+	s.Webhooks = dispatcher
+
+	_, err = s.SubmitTranslate(context.Background(), &nanabushv1.TranslateRequest{
+		JobId:          "job-1",
+		Primitive:      nanabushv1.PrimitiveType_PRIMITIVE_TITLE,
+		Source:         &nanabushv1.TranslateRequest_Title{Title: "Release notes"},
+		SourceLanguage: "en",
+		TargetLanguage: "fr",
+		CallbackUrl:    receiver.URL,
+	})
+	if err != nil {
+		t.Fatalf("SubmitTranslate: %v", err)
+	}
+	select {
+	case <-backend.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("job never reached the backend")
+	}
+
+	s.Drain()
+	if interrupted := s.InterruptJobs(); len(interrupted) != 1 || interrupted[0].JobID != "job-1" {
+		t.Fatalf("InterruptJobs = %+v, want job-1", interrupted)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.Running.Wait(ctx); err != nil {
+		t.Fatalf("Running.Wait: %v", err)
+	}
+
+	// Once the job stops running, its callback is already queued
+	if stats := dispatcher.Stats(); stats.Pending+int(stats.Delivered) != 1 {
+		t.Errorf("callback stats after the job finished = %+v, want one queued or delivered", stats)
+	}
+	if pending := dispatcher.Close(ctx); pending > 1 {
+		t.Errorf("Close: %d callbacks pending, want at most 1", pending)
+	}
+	select {
+	case callback := <-callbacks:
+		if callback.JobId != "job-1" || codes.Code(callback.StatusCode) != codes.Unavailable {
+			t.Errorf("callback job_id=%q, status_code=%v; want job-1 with %v", callback.JobId, codes.Code(callback.StatusCode), codes.Unavailable)
+		}
+	default:
+		t.Error("no callback delivered for the interrupted job")
+	}
+
+	job, ok := s.Jobs.Get("job-1")
+	if !ok || !job.Interrupted {
+		t.Errorf("job history has %+v, %v; want job-1 interrupted", job, ok)
+	}
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/dasmlab/nanabush/server/pkg/feedback"
+	"github.com/dasmlab/nanabush/server/pkg/jobs"
+	"github.com/dasmlab/nanabush/server/pkg/proto/v1"
+)
+
+// recordJob keeps a completed translation in the job history. Source and
+// output content are only kept when feedback capture needs them.
+func (s *TranslationService) recordJob(ctx context.Context, req *nanabushv1.TranslateRequest, resp *nanabushv1.TranslateResponse) {
+	if s.Jobs == nil || resp == nil || !resp.Success || resp.TranslationSkipped {
+		return
+	}
+
+	clientID, _ := s.clientFromContext(ctx)
+	job := jobs.Job{
+		JobID:          req.JobId,
+		Namespace:      req.Namespace,
+		ClientID:       clientID,
+		Primitive:      req.Primitive,
+		SourceLanguage: resp.ResolvedSourceLanguage,
+		TargetLanguage: resp.ResolvedTargetLanguage,
+		Model:          s.backendName(),
+		CompletedAt:    time.Now(),
+	}
+	if s.Feedback != nil {
+		if doc := req.GetDoc(); doc != nil {
+			job.SourceTitle = doc.Title
+			job.SourceMarkdown = doc.Markdown
+		} else {
+			job.SourceTitle = req.GetTitle()
+		}
+		job.OutputTitle = resp.TranslatedTitle
+		job.OutputMarkdown = resp.TranslatedMarkdown
+	}
+	s.Jobs.Put(job)
+}
+
+// ownsJob reports whether the caller submitted job, or is registered in the
+// job's namespace (a client that re-registered has a new ID).
+func (s *TranslationService) ownsJob(ctx context.Context, job jobs.Job) bool {
+	clientID, _ := s.clientFromContext(ctx)
+	if clientID == "" {
+		return false
+	}
+	if clientID == job.ClientID {
+		return true
+	}
+
+	s.clientsMutex.RLock()
+	defer s.clientsMutex.RUnlock()
+	client, ok := s.clients[clientID]
+	return ok && job.Namespace != "" && client.Namespace == job.Namespace
+}
+
+// SubmitFeedback records a human review of a completed job in the retraining
+// dataset. Only the job's client may review it, once.
+func (s *TranslationService) SubmitFeedback(ctx context.Context, req *nanabushv1.SubmitFeedbackRequest) (*nanabushv1.SubmitFeedbackResponse, error) {
+	s.Logger.Printf("SubmitFeedback request: job_id=%q, status=%v, reviewer=%q", req.JobId, req.Status, req.Reviewer)
+
+	if s.Feedback == nil {
+		return nil, status.Error(codes.Unimplemented, "feedback capture is not enabled on this server")
+	}
+	// Never write an unscrubbed dataset
+	if s.Sanitizer == nil {
+		return nil, status.Error(codes.FailedPrecondition, "feedback capture requires PII scrubbing to be enabled")
+	}
+	if req.JobId == "" {
+		return nil, status.Error(codes.InvalidArgument, "job_id is required")
+	}
+
+	var verdict string
+	switch req.Status {
+	case nanabushv1.FeedbackStatus_FEEDBACK_STATUS_APPROVED:
+		verdict = feedback.StatusApproved
+	case nanabushv1.FeedbackStatus_FEEDBACK_STATUS_REJECTED:
+		verdict = feedback.StatusRejected
+	default:
+		return nil, status.Error(codes.InvalidArgument, "status must be FEEDBACK_STATUS_APPROVED or FEEDBACK_STATUS_REJECTED")
+	}
+
+	var job jobs.Job
+	ok := false
+	if s.Jobs != nil {
+		job, ok = s.Jobs.Get(req.JobId)
+	}
+	if !ok {
+		return nil, status.Error(codes.NotFound, fmt.Sprintf("job %q not found or no longer retained", req.JobId))
+	}
+	if !s.ownsJob(ctx, job) {
+		return nil, status.Error(codes.PermissionDenied, fmt.Sprintf("job %q was submitted by another client", req.JobId))
+	}
+	if job.Interrupted {
+		return nil, status.Error(codes.FailedPrecondition, fmt.Sprintf("job %q was interrupted by a shutdown and has no output to review", req.JobId))
+	}
+	if job.SourceTitle == "" && job.SourceMarkdown == "" {
+		// Content is never written to the job history file, so jobs restored
+		// after a restart (or recorded while feedback was off) have none
+		return nil, status.Error(codes.FailedPrecondition, fmt.Sprintf("job %q has no content to review; content is not kept across restarts", req.JobId))
+	}
+
+	// One mask for the whole record so a value gets the same placeholder in
+	// source, output and correction, keeping the pair aligned for training
+	mask := s.Sanitizer.NewMask()
+	record := feedback.Record{
+		JobID:             job.JobID,
+		Status:            verdict,
+		Reviewer:          req.Reviewer,
+		Namespace:         job.Namespace,
+		SourceLanguage:    job.SourceLanguage,
+		TargetLanguage:    job.TargetLanguage,
+		Model:             job.Model,
+		TranslatedAt:      job.CompletedAt.UTC(),
+		SourceTitle:       mask.Scrub(job.SourceTitle),
+		SourceMarkdown:    mask.Scrub(job.SourceMarkdown),
+		OutputTitle:       mask.Scrub(job.OutputTitle),
+		OutputMarkdown:    mask.Scrub(job.OutputMarkdown),
+		CorrectedTitle:    mask.Scrub(req.CorrectedTitle),
+		CorrectedMarkdown: mask.Scrub(req.CorrectedMarkdown),
+		Comment:           mask.Scrub(req.Comment),
+	}
+	if counts := mask.Counts(); len(counts) > 0 {
+		record.Masked = counts
+	}
+
+	record, err := s.Feedback.Append(record)
+	if errors.Is(err, feedback.ErrDuplicate) {
+		return nil, status.Error(codes.AlreadyExists, err.Error())
+	}
+	if err != nil {
+		s.Logger.Printf("SubmitFeedback write failed: job_id=%q, err=%v", req.JobId, err)
+		return nil, status.Error(codes.Internal, "failed to record feedback")
+	}
+
+	s.Logger.Printf("SubmitFeedback recorded: job_id=%q, feedback_id=%q, status=%s, dataset=%s",
+		req.JobId, record.ID, verdict, s.Feedback.Version())
+
+	return &nanabushv1.SubmitFeedbackResponse{
+		FeedbackId:     record.ID,
+		DatasetVersion: s.Feedback.Version(),
+		RecordedAt:     timestamppb.New(record.ReviewedAt),
+	}, nil
+}
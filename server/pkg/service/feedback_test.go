@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"io"
+	"log"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/dasmlab/nanabush/server/pkg/feedback"
+	"github.com/dasmlab/nanabush/server/pkg/proto/v1"
+)
+
+var discard = log.New(io.Discard, "", 0)
+
+// asClient returns a context carrying clientID as the caller.
+func asClient(clientID string) context.Context {
+	if clientID == "" {
+		return context.Background()
+	}
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(ClientIDMetadataKey, clientID))
+}
+
+// registerClient registers a client in namespace and returns its ID.
+func registerClient(t *testing.T, s *TranslationService, name, namespace string) string {
+	t.Helper()
+	resp, err := s.RegisterClient(context.Background(), &nanabushv1.RegisterClientRequest{ClientName: name, ClientVersion: "1.0.0", Namespace: namespace})
+	if err != nil {
+		t.Fatalf("RegisterClient: %v", err)
+	}
+	return resp.ClientId
+}
+
+func TestSubmitFeedback(t *testing.T) {
+	s := NewTranslationService(nil, discard)
+	dataset, err := feedback.Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("feedback.Open: %v", err)
+	}
+	s.Feedback = dataset
+
+	owner := registerClient(t, s, "glooscap", "wiki")
+	sameNamespace := registerClient(t, s, "glooscap-2", "wiki")
+	other := registerClient(t, s, "other", "elsewhere")
+
+	_, err = s.Translate(asClient(owner), &nanabushv1.TranslateRequest{
+		JobId:          "job-1",
+		Namespace:      "wiki",
+		Primitive:      nanabushv1.PrimitiveType_PRIMITIVE_DOC_TRANSLATE,
+		SourceLanguage: "en",
+		TargetLanguage: "fr",
+		Source: &nanabushv1.TranslateRequest_Doc{Doc: &nanabushv1.DocumentContent{
+			Title:    "Getting started",
+			Markdown: "Install the agent and restart the service.",
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+
+	approve := func(jobID string) *nanabushv1.SubmitFeedbackRequest {
+		return &nanabushv1.SubmitFeedbackRequest{JobId: jobID, Status: nanabushv1.FeedbackStatus_FEEDBACK_STATUS_APPROVED, Reviewer: "jdoe"}
+	}
+	tests := []struct {
+		name     string
+		caller   string
+		req      *nanabushv1.SubmitFeedbackRequest
+		wantCode codes.Code
+	}{
+		{"no status", owner, &nanabushv1.SubmitFeedbackRequest{JobId: "job-1"}, codes.InvalidArgument},
+		{"unknown job", owner, approve("job-2"), codes.NotFound},
+		{"unregistered caller", "", approve("job-1"), codes.PermissionDenied},
+		{"client in another namespace", other, approve("job-1"), codes.PermissionDenied},
+		{"client in the job's namespace", sameNamespace, approve("job-1"), codes.OK},
+		{"second review", owner, approve("job-1"), codes.AlreadyExists},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := s.SubmitFeedback(asClient(tt.caller), tt.req)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("SubmitFeedback: %v, want code %v", err, tt.wantCode)
+			}
+			if err == nil && (resp.FeedbackId == "" || resp.DatasetVersion != feedback.SchemaVersion) {
+				t.Errorf("response %+v, want a feedback ID in dataset %s", resp, feedback.SchemaVersion)
+			}
+		})
+	}
+	if got := dataset.Manifest().Records; got != 1 {
+		t.Errorf("dataset has %d records, want 1", got)
+	}
+}
+
+func TestSubmitFeedbackDisabled(t *testing.T) {
+	s := NewTranslationService(nil, discard)
+	_, err := s.SubmitFeedback(context.Background(), &nanabushv1.SubmitFeedbackRequest{JobId: "job-1"})
+	if status.Code(err) != codes.Unimplemented {
+		t.Errorf("SubmitFeedback without a dataset: %v, want Unimplemented", err)
+	}
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/dasmlab/nanabush/server/pkg/proto/v1"
+)
+
+// MaxGlossaryTerms is the maximum number of glossary terms in one request.
+const MaxGlossaryTerms = 200
+
+// MaxGlossaryTermChars is the longest glossary source or target accepted.
+const MaxGlossaryTermChars = 200
+
+// GlossaryTranslator is implemented by backends that can follow a glossary.
+// Backends without it ignore TranslateRequest.glossary.
+type GlossaryTranslator interface {
+	// TranslateTitleWithGlossary translates a title using the given term renderings
+	TranslateTitleWithGlossary(ctx context.Context, title, sourceLang, targetLang string, glossary []*nanabushv1.GlossaryTerm) (string, error)
+
+	// TranslateDocumentWithGlossary translates a document using the given term renderings
+	TranslateDocumentWithGlossary(ctx context.Context, doc *nanabushv1.DocumentContent, sourceLang, targetLang string, glossary []*nanabushv1.GlossaryTerm) (*nanabushv1.DocumentContent, error)
+}
+
+// checkGlossary validates the terms of a request glossary.
+func checkGlossary(field string, glossary []*nanabushv1.GlossaryTerm) error {
+	if len(glossary) > MaxGlossaryTerms {
+		return status.Error(codes.InvalidArgument, fmt.Sprintf("%s has %d terms, maximum is %d", field, len(glossary), MaxGlossaryTerms))
+	}
+	seen := make(map[string]int, len(glossary))
+	for i, term := range glossary {
+		source := strings.TrimSpace(term.GetSource())
+		if source == "" || strings.TrimSpace(term.GetTarget()) == "" {
+			return status.Error(codes.InvalidArgument, fmt.Sprintf("%s[%d]: source and target are required", field, i))
+		}
+		if utf8.RuneCountInString(term.Source) > MaxGlossaryTermChars || utf8.RuneCountInString(term.Target) > MaxGlossaryTermChars {
+			return status.Error(codes.InvalidArgument, fmt.Sprintf("%s[%d]: terms are limited to %d characters", field, i, MaxGlossaryTermChars))
+		}
+		if strings.ContainsAny(term.Source+term.Target, "\r\n") {
+			return status.Error(codes.InvalidArgument, fmt.Sprintf("%s[%d]: terms must be a single line", field, i))
+		}
+		key := strings.ToLower(source)
+		if first, dup := seen[key]; dup {
+			return status.Error(codes.InvalidArgument, fmt.Sprintf("%s[%d]: duplicate source %q (first used by term %d)", field, i, source, first))
+		}
+		seen[key] = i
+	}
+	return nil
+}
+
+// backendGlossary returns the glossary terms that are safe to send to the
+// backend. Terms containing values the sanitizer masks are left out rather
+// than sent in the clear.
+func (s *TranslationService) backendGlossary(glossary []*nanabushv1.GlossaryTerm) []*nanabushv1.GlossaryTerm {
+	if len(glossary) == 0 {
+		return nil
+	}
+	if !s.glossarySupported() {
+		return nil
+	}
+	terms := make([]*nanabushv1.GlossaryTerm, 0, len(glossary))
+	for _, term := range glossary {
+		if s.masks(term.Source) || s.masks(term.Target) {
+			continue
+		}
+		terms = append(terms, term)
+	}
+	return terms
+}
+
+// masks reports whether the sanitizer would alter text.
+func (s *TranslationService) masks(text string) bool {
+	if s.Sanitizer == nil {
+		return false
+	}
+	return s.Sanitizer.NewMask().Scrub(text) != text
+}
+
+// backendTitle translates a scrubbed title, following the glossary when there is one.
+func (s *TranslationService) backendTitle(ctx context.Context, title, sourceLang, targetLang string, glossary []*nanabushv1.GlossaryTerm) (string, error) {
+	if translator, ok := s.Backend.(GlossaryTranslator); ok && len(glossary) > 0 {
+		return translator.TranslateTitleWithGlossary(ctx, title, sourceLang, targetLang, glossary)
+	}
+	return s.Backend.TranslateTitle(ctx, title, sourceLang, targetLang)
+}
+
+// backendDocument translates a scrubbed document, following the glossary when there is one.
+func (s *TranslationService) backendDocument(ctx context.Context, doc *nanabushv1.DocumentContent, sourceLang, targetLang string, glossary []*nanabushv1.GlossaryTerm) (*nanabushv1.DocumentContent, error) {
+	if translator, ok := s.Backend.(GlossaryTranslator); ok && len(glossary) > 0 {
+		return translator.TranslateDocumentWithGlossary(ctx, doc, sourceLang, targetLang, glossary)
+	}
+	return s.Backend.TranslateDocument(ctx, doc, sourceLang, targetLang)
+}
+
+// glossarySupported reports whether the backend can follow a glossary.
+func (s *TranslationService) glossarySupported() bool {
+	_, ok := s.Backend.(GlossaryTranslator)
+	return ok
+}
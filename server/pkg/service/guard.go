@@ -0,0 +1,42 @@
+package service
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/dasmlab/nanabush/server/pkg/guard"
+	"github.com/dasmlab/nanabush/server/pkg/proto/v1"
+)
+
+// checkOutput runs the output guard on one translated field.
+func (s *TranslationService) checkOutput(source, output, sourceLang, targetLang string) *guard.Violation {
+	if s.Guard == nil {
+		return nil
+	}
+	return s.Guard.Check(source, output, sourceLang, targetLang)
+}
+
+// checkDocumentOutput runs the output guard on a translated document's title and markdown.
+func (s *TranslationService) checkDocumentOutput(source, output *nanabushv1.DocumentContent, sourceLang, targetLang string) *guard.Violation {
+	if output == nil {
+		return &guard.Violation{Check: guard.CheckEmptyOutput, Reason: "backend returned no document"}
+	}
+	if violation := s.checkOutput(source.Title, output.Title, sourceLang, targetLang); violation != nil {
+		return violation
+	}
+	return s.checkOutput(source.Markdown, output.Markdown, sourceLang, targetLang)
+}
+
+// rejectedResponse fails a job whose output did not pass the guard.
+// The output is withheld so it cannot be published.
+func (s *TranslationService) rejectedResponse(jobID string, violation *guard.Violation) *nanabushv1.TranslateResponse {
+	s.Logger.Printf("Translate output rejected: job_id=%q, check=%s, reason=%s", jobID, violation.Check, violation.Reason)
+	return &nanabushv1.TranslateResponse{
+		JobId:        jobID,
+		Success:      false,
+		ErrorMessage: fmt.Sprintf("Translation rejected: %v", violation),
+		ErrorCode:    nanabushv1.ErrorCode_ERROR_CODE_OUTPUT_REJECTED,
+		CompletedAt:  timestamppb.Now(),
+	}
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// HealthWatcher keeps the gRPC health statuses in line with the backend:
+// the overall "" status follows backend health, and the
+// nanabush.v1.TranslationService status also goes NOT_SERVING while draining.
+// A status only changes after several checks in a row agree, so a single
+// slow health check does not take the server out of rotation.
+type HealthWatcher struct {
+	// Translation is the service whose backend is checked
+	Translation *TranslationService
+
+	// Health receives the statuses
+	Health *health.Server
+
+	// Interval between backend health checks
+	Interval time.Duration
+
+	// Timeout of each backend health check
+	Timeout time.Duration
+
+	// FailureThreshold is how many failed checks in a row mark the backend unhealthy
+	FailureThreshold int
+
+	// SuccessThreshold is how many successful checks in a row mark it healthy again
+	SuccessThreshold int
+
+	// Logger for status changes
+	Logger *log.Logger
+
+	mu        sync.Mutex
+	checked   bool      // Whether a check has completed
+	healthy   bool      // Backend health after hysteresis
+	streak    int       // Consecutive checks disagreeing with healthy
+	lastErr   error     // Result of the latest check
+	lastCheck time.Time // When the latest check completed
+}
+
+// NewHealthWatcher creates a watcher with default timings. Both statuses are
+// NOT_SERVING until the first check completes.
+func NewHealthWatcher(translation *TranslationService, healthServer *health.Server, logger *log.Logger) *HealthWatcher {
+	if logger == nil {
+		logger = translation.Logger
+	}
+	healthServer.SetServingStatus("", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	healthServer.SetServingStatus(TranslationServiceName, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	return &HealthWatcher{
+		Translation:      translation,
+		Health:           healthServer,
+		Interval:         10 * time.Second,
+		Timeout:          5 * time.Second,
+		FailureThreshold: 3,
+		SuccessThreshold: 2,
+		Logger:           logger,
+	}
+}
+
+// Run checks the backend every Interval until ctx is done. The first check
+// sets the statuses directly; later ones go through the thresholds.
+func (w *HealthWatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		w.check(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// check runs one backend health check and updates the statuses.
+func (w *HealthWatcher) check(ctx context.Context) {
+	var err error
+	if backend := w.Translation.Backend; backend != nil {
+		checkCtx, cancel := context.WithTimeout(ctx, w.Timeout)
+		err = backend.CheckHealth(checkCtx)
+		cancel()
+	}
+	if ctx.Err() != nil {
+		return // Stopping; a cancelled check says nothing about the backend
+	}
+	ok := err == nil
+
+	w.mu.Lock()
+	w.lastErr, w.lastCheck = err, time.Now()
+	switch {
+	case !w.checked:
+		w.checked, w.healthy = true, ok
+		if ok {
+			w.Logger.Println("Backend healthy")
+		} else {
+			w.Logger.Printf("Backend unhealthy: %v", err)
+		}
+	case ok == w.healthy:
+		w.streak = 0
+	default:
+		w.streak++
+		threshold := w.SuccessThreshold
+		if w.healthy {
+			threshold = w.FailureThreshold
+		}
+		switch {
+		case w.streak < threshold && !ok:
+			w.Logger.Printf("Backend health check failed (%d of %d before unhealthy): %v", w.streak, threshold, err)
+		case w.streak >= threshold && ok:
+			w.healthy, w.streak = true, 0
+			w.Logger.Printf("Backend healthy again after %d successful checks", threshold)
+		case w.streak >= threshold:
+			w.healthy, w.streak = false, 0
+			w.Logger.Printf("Backend unhealthy after %d failed checks: %v", threshold, err)
+		}
+	}
+	w.mu.Unlock()
+
+	w.update()
+}
+
+// update sets the health statuses from the backend state and drain.
+func (w *HealthWatcher) update() {
+	healthy := w.BackendHealthy()
+	w.Health.SetServingStatus("", servingStatus(healthy))
+	w.Health.SetServingStatus(TranslationServiceName, servingStatus(healthy && !w.Translation.Draining()))
+}
+
+// BackendHealthy reports whether the backend is healthy, after hysteresis.
+// It is false until the first check completes.
+func (w *HealthWatcher) BackendHealthy() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.checked && w.healthy
+}
+
+// Handler serves Kubernetes probes over HTTP:
+//   - /healthz (liveness) answers 200 while the process is up, whatever the
+//     backend state, so a backend outage does not restart the pod
+//   - /readyz (readiness) answers 200 when the nanabush.v1.TranslationService
+//     status is SERVING and 503 with the reason otherwise
+func (w *HealthWatcher) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(rw http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(rw, "ok")
+	})
+	mux.HandleFunc("/readyz", func(rw http.ResponseWriter, r *http.Request) {
+		resp, err := w.Health.Check(r.Context(), &grpc_health_v1.HealthCheckRequest{Service: TranslationServiceName})
+		if err == nil && resp.Status == grpc_health_v1.HealthCheckResponse_SERVING {
+			fmt.Fprintln(rw, "ok")
+			return
+		}
+		rw.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintln(rw, w.notReadyReason())
+	})
+	return mux
+}
+
+// notReadyReason explains a NOT_SERVING readiness status.
+func (w *HealthWatcher) notReadyReason() string {
+	if w.Translation.Draining() {
+		return "draining"
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	switch {
+	case !w.checked:
+		return "backend not checked yet"
+	case !w.healthy && w.lastErr != nil:
+		return fmt.Sprintf("backend unhealthy: %v", w.lastErr)
+	case !w.healthy:
+		return fmt.Sprintf("backend unhealthy, recovering (checked %v ago)", time.Since(w.lastCheck).Round(time.Second))
+	}
+	return "shutting down"
+}
+
+func servingStatus(serving bool) grpc_health_v1.HealthCheckResponse_ServingStatus {
+	if serving {
+		return grpc_health_v1.HealthCheckResponse_SERVING
+	}
+	return grpc_health_v1.HealthCheckResponse_NOT_SERVING
+}
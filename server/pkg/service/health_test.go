@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/dasmlab/nanabush/server/pkg/proto/v1"
+)
+
+// healthBackend only answers health checks, with err.
+type healthBackend struct {
+	err error
+}
+
+func (b *healthBackend) TranslateTitle(ctx context.Context, title, sourceLang, targetLang string) (string, error) {
+	return "", errors.New("not implemented")
+}
+
+func (b *healthBackend) TranslateDocument(ctx context.Context, doc *nanabushv1.DocumentContent, sourceLang, targetLang string) (*nanabushv1.DocumentContent, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (b *healthBackend) CheckHealth(ctx context.Context) error {
+	return b.err
+}
+
+func TestHealthWatcher(t *testing.T) {
+	const (
+		serving    = grpc_health_v1.HealthCheckResponse_SERVING
+		notServing = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	)
+	down := errors.New("model server unreachable")
+
+	backend := &healthBackend{}
+	s := NewTranslationService(backend, discard)
+	healthServer := health.NewServer()
+	w := NewHealthWatcher(s, healthServer, discard)
+	handler := w.Handler()
+
+	// Thresholds of 3 failures and 2 successes
+	steps := []struct {
+		name        string
+		drain       bool
+		err         error
+		want        grpc_health_v1.HealthCheckResponse_ServingStatus // Overall status
+		wantService grpc_health_v1.HealthCheckResponse_ServingStatus // TranslationService status
+		wantReady   string                                           // /readyz body, 503 unless "ok"
+	}{
+		{name: "first check succeeds", want: serving, wantService: serving, wantReady: "ok"},
+		{name: "one failure", err: down, want: serving, wantService: serving, wantReady: "ok"},
+		{name: "success resets the failures", want: serving, wantService: serving, wantReady: "ok"},
+		{name: "first of three failures", err: down, want: serving, wantService: serving, wantReady: "ok"},
+		{name: "second of three failures", err: down, want: serving, wantService: serving, wantReady: "ok"},
+		{name: "third failure", err: down, want: notServing, wantService: notServing, wantReady: "backend unhealthy: model server unreachable"},
+		{name: "first of two successes", want: notServing, wantService: notServing, wantReady: "backend unhealthy, recovering"},
+		{name: "failure resets the successes", err: down, want: notServing, wantService: notServing, wantReady: "backend unhealthy: model server unreachable"},
+		{name: "first success again", want: notServing, wantService: notServing, wantReady: "backend unhealthy, recovering"},
+		{name: "second success", want: serving, wantService: serving, wantReady: "ok"},
+		{name: "draining", drain: true, want: serving, wantService: notServing, wantReady: "draining"},
+		{name: "failure while draining", drain: true, err: down, want: serving, wantService: notServing, wantReady: "draining"},
+	}
+
+	if got := readyz(t, handler); !strings.HasPrefix(got, "503 backend not checked yet") {
+		t.Errorf("/readyz before the first check = %q, want 503 backend not checked yet", got)
+	}
+
+	for i, step := range steps {
+		if step.drain {
+			s.Drain()
+		}
+		backend.err = step.err
+		w.check(context.Background())
+
+		if got := healthStatus(t, healthServer, ""); got != step.want {
+			t.Errorf("step %d (%s): overall status %v, want %v", i, step.name, got, step.want)
+		}
+		if got := healthStatus(t, healthServer, TranslationServiceName); got != step.wantService {
+			t.Errorf("step %d (%s): %s status %v, want %v", i, step.name, TranslationServiceName, got, step.wantService)
+		}
+		want := "503 " + step.wantReady
+		if step.wantReady == "ok" {
+			want = "200 ok"
+		}
+		if got := readyz(t, handler); !strings.HasPrefix(got, want) {
+			t.Errorf("step %d (%s): /readyz = %q, want %q", i, step.name, got, want)
+		}
+	}
+
+	// A check cut short by shutdown says nothing about the backend
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	backend.err = down
+	for i := 0; i < w.FailureThreshold; i++ {
+		w.check(ctx)
+	}
+	if got := healthStatus(t, healthServer, ""); got != serving {
+		t.Errorf("overall status after cancelled checks %v, want %v", got, serving)
+	}
+
+	// Liveness does not depend on the backend
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("/healthz = %d, want 200", rec.Code)
+	}
+}
+
+// readyz returns the /readyz status code and body as "<code> <body>".
+func readyz(t *testing.T, handler http.Handler) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	return strings.TrimSpace(fmt.Sprintf("%d %s", rec.Code, rec.Body.String()))
+}
+
+func healthStatus(t *testing.T, healthServer *health.Server, service string) grpc_health_v1.HealthCheckResponse_ServingStatus {
+	t.Helper()
+	resp, err := healthServer.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: service})
+	if err != nil {
+		t.Fatalf("Check(%q): %v", service, err)
+	}
+	return resp.Status
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/dasmlab/nanabush/server/pkg/languages"
+	"github.com/dasmlab/nanabush/server/pkg/proto/v1"
+)
+
+// ListLanguages returns the supported language pairs.
+func (s *TranslationService) ListLanguages(ctx context.Context, req *nanabushv1.ListLanguagesRequest) (*nanabushv1.ListLanguagesResponse, error) {
+	s.Logger.Printf("ListLanguages request: source=%q", req.SourceLanguage)
+
+	var source *languages.Pair
+	if req.SourceLanguage != "" {
+		tag, err := languages.ParseTag(req.SourceLanguage)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("source_language: %v", err))
+		}
+		source = &languages.Pair{Source: tag}
+	}
+
+	resp := &nanabushv1.ListLanguagesResponse{
+		AutoDetectSupported: s.LanguageID != nil,
+	}
+	for _, pair := range s.Languages.Pairs() {
+		if source != nil && pair.Source != source.Source {
+			continue
+		}
+		resp.Pairs = append(resp.Pairs, &nanabushv1.LanguagePair{
+			SourceLanguage: pair.Source.String(),
+			TargetLanguage: pair.Target.String(),
+		})
+	}
+	for _, info := range s.Languages.Languages() {
+		resp.Languages = append(resp.Languages, &nanabushv1.LanguageInfo{
+			Tag:  info.Tag,
+			Name: info.Name,
+		})
+	}
+	if s.LanguageID != nil {
+		resp.AutoDetectLanguages = s.LanguageID.Languages()
+	}
+
+	return resp, nil
+}
+
+// parseLanguageTag canonicalizes a BCP 47 tag from a request field,
+// returning codes.InvalidArgument when it does not parse.
+func parseLanguageTag(field, tag string) (string, error) {
+	t, err := languages.ParseTag(tag)
+	if err != nil {
+		return "", status.Error(codes.InvalidArgument, fmt.Sprintf("%s: %v", field, err))
+	}
+	return t.String(), nil
+}
+
+// resolveLanguagePair maps the requested languages onto a supported pair,
+// applying fallbacks (fr-CA -> fr). Unsupported pairs yield codes.InvalidArgument.
+func (s *TranslationService) resolveLanguagePair(sourceLang, targetLang string) (languages.Pair, error) {
+	source, err := languages.ParseTag(sourceLang)
+	if err != nil {
+		return languages.Pair{}, status.Error(codes.InvalidArgument, fmt.Sprintf("source_language: %v", err))
+	}
+	target, err := languages.ParseTag(targetLang)
+	if err != nil {
+		return languages.Pair{}, status.Error(codes.InvalidArgument, fmt.Sprintf("target_language: %v", err))
+	}
+	pair, err := s.Languages.Resolve(source, target)
+	if err != nil {
+		return languages.Pair{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return pair, nil
+}
@@ -0,0 +1,22 @@
+package service
+
+import (
+	"github.com/dasmlab/nanabush/server/pkg/proto/v1"
+)
+
+// scoreResponse fills in the quality estimate for a successful translation.
+func (s *TranslationService) scoreResponse(req *nanabushv1.TranslateRequest, resp *nanabushv1.TranslateResponse) {
+	if s.Quality == nil || !resp.Success || resp.TranslationSkipped {
+		return
+	}
+
+	result := s.Quality.Score(requestText(req), responseText(resp), resp.ResolvedTargetLanguage, req.GetDoc() != nil)
+	resp.QualityScore = result.Score
+	resp.QualityFlags = result.Flags
+	resp.NeedsReview = result.Score < s.Settings().ReviewThreshold
+
+	if resp.NeedsReview {
+		s.Logger.Printf("Translate quality below review threshold: job_id=%q, score=%.3f, flags=%v, length_ratio=%.2f, untranslated=%.2f, language=%.2f, markup=%.2f",
+			req.JobId, result.Score, result.Flags, result.LengthRatio, result.UntranslatedRatio, result.LanguageConfidence, result.MarkupPreserved)
+	}
+}
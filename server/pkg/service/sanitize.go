@@ -0,0 +1,72 @@
+package service
+
+import (
+	"encoding/json"
+
+	"github.com/dasmlab/nanabush/server/pkg/proto/v1"
+	"github.com/dasmlab/nanabush/server/pkg/sanitize"
+)
+
+// SanitizerAudit records what was masked for one job. Only hashes of the
+// masked values are kept.
+type SanitizerAudit struct {
+	JobID    string             `json:"job_id"`
+	Findings []sanitize.Finding `json:"findings"`
+	Missing  []string           `json:"missing,omitempty"`
+}
+
+// newMask starts masking a job's content, or returns nil when scrubbing is disabled.
+func (s *TranslationService) newMask() *sanitize.Mask {
+	if s.Sanitizer == nil {
+		return nil
+	}
+	return s.Sanitizer.NewMask()
+}
+
+// auditMask logs what was masked for a job.
+func (s *TranslationService) auditMask(jobID string, mask *sanitize.Mask) {
+	if mask == nil {
+		return
+	}
+	findings := mask.Findings()
+	if len(findings) == 0 {
+		return
+	}
+
+	record := SanitizerAudit{
+		JobID:    jobID,
+		Findings: findings,
+		Missing:  mask.Missing(),
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		s.Logger.Printf("Sanitizer audit marshal failed: job_id=%q, err=%v", jobID, err)
+		return
+	}
+	s.Logger.Printf("Sanitizer audit: %s", data)
+	if len(record.Missing) > 0 {
+		s.Logger.Printf("WARNING: backend output dropped %d masked values for job_id=%q: %v", len(record.Missing), jobID, record.Missing)
+	}
+}
+
+// scrubDocument returns a copy of doc with sensitive values masked.
+func scrubDocument(mask *sanitize.Mask, doc *nanabushv1.DocumentContent) *nanabushv1.DocumentContent {
+	if mask == nil {
+		return doc
+	}
+	return &nanabushv1.DocumentContent{
+		Title:    mask.Scrub(doc.Title),
+		Markdown: mask.Scrub(doc.Markdown),
+		Slug:     doc.Slug,
+		Metadata: doc.Metadata,
+	}
+}
+
+// restoreDocument puts masked values back into a translated document in place.
+func restoreDocument(mask *sanitize.Mask, doc *nanabushv1.DocumentContent) {
+	if mask == nil || doc == nil {
+		return
+	}
+	doc.Title = mask.Restore(doc.Title)
+	doc.Markdown = mask.Restore(doc.Markdown)
+}
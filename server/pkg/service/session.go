@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/dasmlab/nanabush/server/pkg/proto/v1"
+)
+
+// sessionCheckInterval is how often an open session re-evaluates its
+// directives (changes such as a drain are also pushed immediately).
+const sessionCheckInterval = 5 * time.Second
+
+// sessionEventBuffer is how many job completions may wait for a slow
+// session before further completions are dropped.
+const sessionEventBuffer = 64
+
+// session is an open Session stream of a registered client.
+type session struct {
+	clientID string
+	events   chan *nanabushv1.SessionEvent
+	wake     chan struct{} // Re-check directives now
+
+	stopOnce sync.Once
+	done     chan struct{}
+	err      error // Returned to the client once done is closed
+}
+
+func newSession(clientID string) *session {
+	return &session{
+		clientID: clientID,
+		events:   make(chan *nanabushv1.SessionEvent, sessionEventBuffer),
+		wake:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+}
+
+// stop ends the session with err.
+func (sess *session) stop(err error) {
+	sess.stopOnce.Do(func() {
+		sess.err = err
+		close(sess.done)
+	})
+}
+
+// poke asks the session to re-check its directives.
+func (sess *session) poke() {
+	select {
+	case sess.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Session keeps a registered client connected. Liveness is the stream itself:
+// the client is marked inactive as soon as it closes. Directives are pushed
+// when they change and job completions as they happen.
+func (s *TranslationService) Session(stream nanabushv1.TranslationService_SessionServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return status.Error(codes.Canceled, fmt.Sprintf("session closed before hello: %v", err))
+	}
+	hello := first.GetHello()
+	if hello == nil {
+		return status.Error(codes.InvalidArgument, "first session message must be hello")
+	}
+	s.Logger.Printf("Session request: client_id=%q, client_name=%q", hello.ClientId, hello.ClientName)
+	if hello.ClientId == "" {
+		return status.Error(codes.InvalidArgument, "client_id is required")
+	}
+	if hello.ClientName == "" {
+		return status.Error(codes.InvalidArgument, "client_name is required")
+	}
+
+	sess, err := s.openSession(hello)
+	if err != nil {
+		return err
+	}
+	defer s.closeSession(sess)
+
+	// Client messages arrive on their own goroutine; a receive error means the
+	// client went away (or half-closed, which also ends the session).
+	recvErr := make(chan error, 1)
+	go func() {
+		for {
+			msg, err := stream.Recv()
+			if err != nil {
+				recvErr <- err
+				return
+			}
+			if st := msg.GetStatus(); st != nil {
+				s.updateClientState(sess.clientID, st.Metadata)
+			}
+		}
+	}()
+
+	var last *nanabushv1.HeartbeatResponse
+	sendDirectives := func() error {
+		directives := s.sessionDirectives(sess.clientID)
+		if sameDirectives(last, directives) {
+			return nil
+		}
+		last = directives
+		return stream.Send(&nanabushv1.SessionEvent{
+			SentAt: timestamppb.Now(),
+			Event:  &nanabushv1.SessionEvent_Directives{Directives: directives},
+		})
+	}
+	if err := sendDirectives(); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(sessionCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		case err := <-recvErr:
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		case <-sess.done:
+			return sess.err
+		case event := <-sess.events:
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		case <-sess.wake:
+			if err := sendDirectives(); err != nil {
+				return err
+			}
+		case <-ticker.C:
+			s.touchClient(sess.clientID)
+			if err := sendDirectives(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// openSession marks a registered client as connected. An earlier session of
+// the same client is ended.
+func (s *TranslationService) openSession(hello *nanabushv1.SessionHello) (*session, error) {
+	s.clientsMutex.Lock()
+	defer s.clientsMutex.Unlock()
+
+	client, exists := s.clients[hello.ClientId]
+	if !exists {
+		s.Logger.Printf("Session from unknown client: id=%q, name=%q", hello.ClientId, hello.ClientName)
+		return nil, status.Error(codes.NotFound, "client not registered or expired, re-register")
+	}
+	if client.ClientName != hello.ClientName {
+		s.Logger.Printf("Session client name mismatch: expected=%q, got=%q", client.ClientName, hello.ClientName)
+		return nil, status.Error(codes.NotFound, "client name mismatch, re-register")
+	}
+	if old := s.sessions[hello.ClientId]; old != nil {
+		old.stop(status.Error(codes.Aborted, "replaced by a newer session"))
+	}
+	sess := newSession(hello.ClientId)
+	s.sessions[hello.ClientId] = sess
+
+	client.SessionActive = true
+	client.DisconnectedAt = time.Time{}
+	client.LastHeartbeat = time.Now()
+	if hello.Metadata != nil {
+		client.HeartbeatMetadata = hello.Metadata
+	}
+	s.Logger.Printf("Session opened: client_id=%q, name=%q, active_sessions=%d", hello.ClientId, client.ClientName, len(s.sessions))
+	return sess, nil
+}
+
+// closeSession marks the client inactive as soon as its session ends.
+func (s *TranslationService) closeSession(sess *session) {
+	sess.stop(nil)
+
+	s.clientsMutex.Lock()
+	defer s.clientsMutex.Unlock()
+
+	if s.sessions[sess.clientID] != sess {
+		return // Replaced by a newer session
+	}
+	delete(s.sessions, sess.clientID)
+	if client, exists := s.clients[sess.clientID]; exists {
+		client.SessionActive = false
+		client.DisconnectedAt = time.Now()
+		client.LastHeartbeat = client.DisconnectedAt
+	}
+	s.Logger.Printf("Session closed, client inactive: client_id=%q, active_sessions=%d", sess.clientID, len(s.sessions))
+}
+
+// updateClientState stores client-reported state from a session status message.
+func (s *TranslationService) updateClientState(clientID string, md map[string]string) {
+	s.clientsMutex.Lock()
+	defer s.clientsMutex.Unlock()
+
+	if client, exists := s.clients[clientID]; exists {
+		client.HeartbeatMetadata = md
+		client.LastHeartbeat = time.Now()
+	}
+}
+
+// touchClient records that a session client is still connected.
+func (s *TranslationService) touchClient(clientID string) {
+	s.clientsMutex.Lock()
+	defer s.clientsMutex.Unlock()
+
+	if client, exists := s.clients[clientID]; exists {
+		client.LastHeartbeat = time.Now()
+	}
+}
+
+// sessionDirectives builds the directives pushed to a session client.
+func (s *TranslationService) sessionDirectives(clientID string) *nanabushv1.HeartbeatResponse {
+	s.clientsMutex.Lock()
+	defer s.clientsMutex.Unlock()
+
+	return s.withDirectivesLocked(&nanabushv1.HeartbeatResponse{
+		Success:                  true,
+		Message:                  "Session active",
+		ReceivedAt:               timestamppb.Now(),
+		HeartbeatIntervalSeconds: s.Settings().heartbeatSeconds(),
+	}, clientID)
+}
+
+// sameDirectives reports whether b would tell the client nothing new after a.
+// Queue depth alone changes constantly and is not worth a push.
+func sameDirectives(a, b *nanabushv1.HeartbeatResponse) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Drain == b.Drain &&
+		a.MinClientVersion == b.MinClientVersion &&
+		a.HeartbeatIntervalSeconds == b.HeartbeatIntervalSeconds &&
+		a.Backpressure.GetLevel() == b.Backpressure.GetLevel() &&
+		a.Backpressure.GetMaxConcurrentJobs() == b.Backpressure.GetMaxConcurrentJobs()
+}
+
+// pokeSessions makes every open session re-check its directives now.
+func (s *TranslationService) pokeSessions() {
+	s.clientsMutex.RLock()
+	defer s.clientsMutex.RUnlock()
+
+	for _, sess := range s.sessions {
+		sess.poke()
+	}
+}
+
+// stopSessionLocked ends a client's session, if it has one.
+// s.clientsMutex must be held.
+func (s *TranslationService) stopSessionLocked(clientID string, err error) {
+	if sess := s.sessions[clientID]; sess != nil {
+		sess.stop(err)
+	}
+}
+
+// notifyJobCompleted pushes the outcome of a Translate call to the calling
+// client's session, if it has one open.
+func (s *TranslationService) notifyJobCompleted(ctx context.Context, req *nanabushv1.TranslateRequest, resp *nanabushv1.TranslateResponse, err error) {
+	clientID, _ := s.clientFromContext(ctx)
+	if clientID == "" || req.GetJobId() == "" {
+		return
+	}
+
+	s.clientsMutex.RLock()
+	sess := s.sessions[clientID]
+	s.clientsMutex.RUnlock()
+	if sess == nil {
+		return
+	}
+
+	completed := &nanabushv1.JobCompleted{
+		JobId:       req.JobId,
+		CompletedAt: timestamppb.Now(),
+	}
+	if err != nil {
+		st := status.Convert(err)
+		completed.ErrorMessage = st.Message()
+		completed.StatusCode = int32(st.Code())
+	} else {
+		completed.Success = resp.Success
+		completed.ErrorMessage = resp.ErrorMessage
+		completed.ErrorCode = resp.ErrorCode
+		completed.QualityScore = resp.QualityScore
+		completed.NeedsReview = resp.NeedsReview
+	}
+
+	select {
+	case sess.events <- &nanabushv1.SessionEvent{
+		SentAt: timestamppb.Now(),
+		Event:  &nanabushv1.SessionEvent_JobCompleted{JobCompleted: completed},
+	}:
+	default:
+		s.Logger.Printf("Session event dropped, client not keeping up: client_id=%q, job_id=%q", clientID, req.JobId)
+	}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"time"
+
+	"github.com/dasmlab/nanabush/server/pkg/compat"
+	"github.com/dasmlab/nanabush/server/pkg/quality"
+)
+
+// Settings are the service options that can change while the server runs,
+// e.g. on a configuration reload.
+type Settings struct {
+	// HeartbeatInterval is recommended to clients in registration and heartbeat responses
+	HeartbeatInterval time.Duration
+
+	// RegistrationExpiry is how long a registration lasts before the client must re-register
+	RegistrationExpiry time.Duration
+
+	// MaxDocumentChars is the largest markdown accepted by Translate (0 for no limit)
+	MaxDocumentChars int
+
+	// ReviewThreshold is the quality score below which responses set needs_review
+	ReviewThreshold float64
+
+	// VersionPolicy decides which client versions may register (nil allows
+	// all); its minimum is announced to clients in heartbeats
+	VersionPolicy *compat.Policy
+}
+
+// DefaultSettings returns the settings of a new service.
+func DefaultSettings() Settings {
+	return Settings{
+		HeartbeatInterval:  60 * time.Second,
+		RegistrationExpiry: 24 * time.Hour,
+		MaxDocumentChars:   DefaultMaxDocumentChars,
+		ReviewThreshold:    quality.DefaultReviewThreshold,
+	}
+}
+
+// heartbeatSeconds is the heartbeat interval as sent to clients.
+func (settings Settings) heartbeatSeconds() int32 {
+	return int32(settings.HeartbeatInterval / time.Second)
+}
+
+// Settings returns the current settings.
+func (s *TranslationService) Settings() Settings {
+	s.settingsMutex.RLock()
+	defer s.settingsMutex.RUnlock()
+	return s.settings
+}
+
+// ApplySettings replaces the current settings. Registered clients are
+// re-checked against the new version policy; their status changes but they
+// stay registered until they next register.
+func (s *TranslationService) ApplySettings(settings Settings) {
+	s.settingsMutex.Lock()
+	s.settings = settings
+	s.settingsMutex.Unlock()
+
+	s.clientsMutex.Lock()
+	defer s.clientsMutex.Unlock()
+
+	changed := 0
+	for _, client := range s.clients {
+		status := settings.VersionPolicy.Check(client.ClientVersion).Status
+		if status != client.VersionStatus {
+			client.VersionStatus = status
+			changed++
+		}
+	}
+	if changed > 0 {
+		s.Logger.Printf("Version policy changed the status of %d registered clients", changed)
+	}
+
+	// Sessions pick up a new interval or minimum version right away
+	for _, sess := range s.sessions {
+		sess.poke()
+	}
+}
@@ -7,13 +7,35 @@ import (
 	"sync"
 	"time"
 
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/dasmlab/nanabush/server/pkg/audit"
+	"github.com/dasmlab/nanabush/server/pkg/compat"
+	"github.com/dasmlab/nanabush/server/pkg/estimator"
+	"github.com/dasmlab/nanabush/server/pkg/feedback"
+	"github.com/dasmlab/nanabush/server/pkg/guard"
+	"github.com/dasmlab/nanabush/server/pkg/jobs"
+	"github.com/dasmlab/nanabush/server/pkg/langid"
+	"github.com/dasmlab/nanabush/server/pkg/languages"
 	"github.com/dasmlab/nanabush/server/pkg/proto/v1"
+	"github.com/dasmlab/nanabush/server/pkg/quality"
+	"github.com/dasmlab/nanabush/server/pkg/sanitize"
+	"github.com/dasmlab/nanabush/server/pkg/scheduler"
+	"github.com/dasmlab/nanabush/server/pkg/webhook"
 )
 
+// PriorityMetadataKey is the gRPC metadata key clients use to set the
+// scheduling class of a request ("interactive", "normal" or "bulk").
+const PriorityMetadataKey = "nanabush-priority"
+
+// VersionWarningMetadataKey is the RegisterClient response header carrying a
+// warning when the client version is deprecated or cannot be checked.
+const VersionWarningMetadataKey = "nanabush-version-warning"
+
 // ClientInfo tracks registered client information.
 type ClientInfo struct {
 	ClientID    string
@@ -23,6 +45,18 @@ type ClientInfo struct {
 	Metadata    map[string]string
 	RegisteredAt time.Time
 	LastHeartbeat time.Time
+	
+	// HeartbeatMetadata is the client-reported state from the latest heartbeat
+	HeartbeatMetadata map[string]string
+	
+	// SessionActive is set while the client has a Session stream open;
+	// DisconnectedAt is set when it closes (the client is inactive until it
+	// reconnects or heartbeats)
+	SessionActive  bool
+	DisconnectedAt time.Time
+	
+	// VersionStatus is how the version policy judged ClientVersion at registration
+	VersionStatus compat.Status
 }
 
 // TranslationService implements the TranslationService gRPC service.
@@ -32,6 +66,42 @@ type TranslationService struct {
 	// Backend is the vLLM backend integration (to be implemented)
 	Backend TranslatorBackend
 	
+	// Scheduler orders backend work by priority class and namespace
+	Scheduler *scheduler.Scheduler
+	
+	// Estimator predicts job duration from observed backend throughput
+	Estimator *estimator.Estimator
+	
+	// LanguageID detects the source language when clients send "auto"
+	LanguageID *langid.Identifier
+	
+	// Languages holds the supported language pairs
+	Languages *languages.Registry
+	
+	// Sanitizer masks PII and secrets before content reaches the backend (nil disables)
+	Sanitizer *sanitize.Sanitizer
+	
+	// Guard rejects backend output that diverges from a translation (nil disables)
+	Guard *guard.Guard
+	
+	// Quality scores successful translations (nil disables)
+	Quality *quality.Scorer
+	
+	// Audit records every translation call in a hash-chained log (nil disables)
+	Audit *audit.Log
+	
+	// Jobs keeps recently completed jobs for follow-up calls such as SubmitFeedback
+	Jobs *jobs.Store
+	
+	// Running tracks in-flight Translate jobs so administrators can list and cancel them
+	Running *jobs.Running
+	
+	// Feedback is the retraining dataset written by SubmitFeedback (nil disables)
+	Feedback *feedback.Dataset
+	
+	// Webhooks delivers job callbacks to callback_url (nil disables callbacks)
+	Webhooks *webhook.Dispatcher
+	
 	// Logger for service operations
 	Logger *log.Logger
 	
@@ -39,7 +109,17 @@ type TranslationService struct {
 	clients      map[string]*ClientInfo
 	clientsMutex sync.RWMutex
 	clientIDCounter int64
-	heartbeatInterval int32 // seconds
+	sessions     map[string]*session // Open Session streams by client ID, guarded by clientsMutex
+	
+	// Runtime-changeable settings (see ApplySettings)
+	settingsMutex sync.RWMutex
+	settings      Settings
+	
+	// Drain state (see Drain and WaitForJobs)
+	drainMutex    sync.Mutex
+	drainingSince time.Time
+	drainDeadline time.Time
+	interruptedJobs int
 }
 
 // TranslatorBackend defines the interface for vLLM backend integration.
@@ -59,11 +139,32 @@ func NewTranslationService(backend TranslatorBackend, logger *log.Logger) *Trans
 	if logger == nil {
 		logger = log.Default()
 	}
+	
+	languageID, err := langid.New()
+	if err != nil {
+		logger.Printf("Language detection unavailable: %v", err)
+	}
+	
+	sanitizer, err := sanitize.New(sanitize.Config{})
+	if err != nil {
+		logger.Printf("PII scrubbing unavailable: %v", err)
+	}
+	
 	return &TranslationService{
 		Backend:          backend,
+		Scheduler:        scheduler.New(scheduler.DefaultConfig()),
+		Estimator:        estimator.New(estimator.DefaultWindow),
+		LanguageID:       languageID,
+		Languages:        languages.Default(),
+		Sanitizer:        sanitizer,
+		Guard:            guard.New(guard.DefaultConfig(), languageID),
+		Quality:          quality.New(languageID),
+		Jobs:             jobs.NewStore(jobs.DefaultCapacity, jobs.DefaultRetention),
+		Running:          jobs.NewRunning(),
 		Logger:           logger,
 		clients:          make(map[string]*ClientInfo),
-		heartbeatInterval: 60, // Default: 60 seconds
+		sessions:         make(map[string]*session),
+		settings:         DefaultSettings(),
 	}
 }
 
@@ -75,6 +176,23 @@ func (s *TranslationService) RegisterClient(ctx context.Context, req *nanabushv1
 	if req.ClientName == "" {
 		return nil, status.Error(codes.InvalidArgument, "client_name is required")
 	}
+	if err := s.checkDraining(); err != nil {
+		return nil, err
+	}
+	
+	// Enforce the client version policy
+	settings := s.Settings()
+	compatibility := settings.VersionPolicy.Check(req.ClientVersion)
+	if compatibility.Status == compat.Blocked {
+		s.Logger.Printf("Client version rejected: name=%q, version=%q: %s", req.ClientName, req.ClientVersion, compatibility.Reason)
+		return nil, status.Error(codes.FailedPrecondition, compatibility.Reason)
+	}
+	message := fmt.Sprintf("Client %q registered successfully", req.ClientName)
+	if compatibility.Reason != "" {
+		s.Logger.Printf("Client version warning: name=%q, version=%q, status=%s", req.ClientName, req.ClientVersion, compatibility.Status)
+		message += "; warning: " + compatibility.Reason
+		grpc.SetHeader(ctx, metadata.Pairs(VersionWarningMetadataKey, compatibility.Reason))
+	}
 	
 	s.clientsMutex.Lock()
 	defer s.clientsMutex.Unlock()
@@ -93,6 +211,7 @@ func (s *TranslationService) RegisterClient(ctx context.Context, req *nanabushv1
 		Metadata:      req.Metadata,
 		RegisteredAt:  now,
 		LastHeartbeat: now,
+		VersionStatus: compatibility.Status,
 	}
 	
 	// Store client
@@ -100,14 +219,14 @@ func (s *TranslationService) RegisterClient(ctx context.Context, req *nanabushv1
 	
 	s.Logger.Printf("Client registered: id=%q, name=%q, total_clients=%d", clientID, req.ClientName, len(s.clients))
 	
-	// Calculate expiration (24 hours from now)
-	expiresAt := now.Add(24 * time.Hour)
+	// Calculate expiration
+	expiresAt := now.Add(settings.RegistrationExpiry)
 	
 	return &nanabushv1.RegisterClientResponse{
 		ClientId:               clientID,
 		Success:                true,
-		Message:                fmt.Sprintf("Client %q registered successfully", req.ClientName),
-		HeartbeatIntervalSeconds: s.heartbeatInterval,
+		Message:                message,
+		HeartbeatIntervalSeconds: settings.heartbeatSeconds(),
 		ExpiresAt:              timestamppb.New(expiresAt),
 	}, nil
 }
@@ -131,53 +250,64 @@ func (s *TranslationService) Heartbeat(ctx context.Context, req *nanabushv1.Hear
 	clientInfo, exists := s.clients[req.ClientId]
 	if !exists {
 		s.Logger.Printf("Heartbeat from unknown client: id=%q, name=%q", req.ClientId, req.ClientName)
-		return &nanabushv1.HeartbeatResponse{
+		return s.withDirectivesLocked(&nanabushv1.HeartbeatResponse{
 			Success:             false,
 			Message:             "Client not registered or expired",
 			ReceivedAt:          timestamppb.Now(),
-			HeartbeatIntervalSeconds: s.heartbeatInterval,
+			HeartbeatIntervalSeconds: s.Settings().heartbeatSeconds(),
 			ReRegisterRequired: true,
-		}, nil
+		}, ""), nil
 	}
 	
 	// Validate client name matches
 	if clientInfo.ClientName != req.ClientName {
 		s.Logger.Printf("Heartbeat client name mismatch: expected=%q, got=%q", clientInfo.ClientName, req.ClientName)
-		return &nanabushv1.HeartbeatResponse{
+		return s.withDirectivesLocked(&nanabushv1.HeartbeatResponse{
 			Success:             false,
 			Message:             "Client name mismatch",
 			ReceivedAt:          timestamppb.Now(),
-			HeartbeatIntervalSeconds: s.heartbeatInterval,
+			HeartbeatIntervalSeconds: s.Settings().heartbeatSeconds(),
 			ReRegisterRequired: true,
-		}, nil
+		}, ""), nil
 	}
 	
-	// Update last heartbeat time
+	// Update last heartbeat time and client-reported state
 	clientInfo.LastHeartbeat = time.Now()
+	clientInfo.DisconnectedAt = time.Time{}
+	if req.Metadata != nil {
+		clientInfo.HeartbeatMetadata = req.Metadata
+	}
 	
-	// Check if registration expired (24 hours)
-	if time.Since(clientInfo.RegisteredAt) > 24*time.Hour {
+	// Check if registration expired
+	if time.Since(clientInfo.RegisteredAt) > s.Settings().RegistrationExpiry {
 		s.Logger.Printf("Client registration expired: id=%q, name=%q", req.ClientId, req.ClientName)
 		delete(s.clients, req.ClientId)
-		return &nanabushv1.HeartbeatResponse{
+		s.stopSessionLocked(req.ClientId, status.Error(codes.NotFound, "registration expired, re-register"))
+		return s.withDirectivesLocked(&nanabushv1.HeartbeatResponse{
 			Success:             false,
 			Message:             "Registration expired",
 			ReceivedAt:          timestamppb.Now(),
-			HeartbeatIntervalSeconds: s.heartbeatInterval,
+			HeartbeatIntervalSeconds: s.Settings().heartbeatSeconds(),
 			ReRegisterRequired: true,
-		}, nil
+		}, ""), nil
 	}
 	
 	s.Logger.Printf("Heartbeat acknowledged: client_id=%q, name=%q, last_seen=%v", 
 		req.ClientId, req.ClientName, clientInfo.LastHeartbeat)
 	
-	return &nanabushv1.HeartbeatResponse{
+	resp := s.withDirectivesLocked(&nanabushv1.HeartbeatResponse{
 		Success:             true,
 		Message:             "Heartbeat acknowledged",
 		ReceivedAt:          timestamppb.Now(),
-		HeartbeatIntervalSeconds: s.heartbeatInterval,
+		HeartbeatIntervalSeconds: s.Settings().heartbeatSeconds(),
 		ReRegisterRequired: false,
-	}, nil
+	}, req.ClientId)
+	if resp.Drain || resp.Backpressure.GetLevel() == nanabushv1.BackpressureLevel_BACKPRESSURE_LEVEL_HIGH {
+		s.Logger.Printf("Heartbeat directives: client_id=%q, drain=%v, backpressure=%v, max_concurrent_jobs=%d, interval=%ds",
+			req.ClientId, resp.Drain, resp.Backpressure.GetLevel(), resp.Backpressure.GetMaxConcurrentJobs(), resp.HeartbeatIntervalSeconds)
+	}
+	
+	return resp, nil
 }
 
 // GetRegisteredClients returns all currently registered clients (for monitoring/debugging).
@@ -208,6 +338,10 @@ type ClientMetrics struct {
 	ClientsByVersion   map[string]int
 	OldestHeartbeat    time.Time
 	NewestHeartbeat    time.Time
+	ActiveSessions     int
+	
+	// DeprecatedClients counts clients registered with a deprecated version
+	DeprecatedClients int
 }
 
 // GetClientMetrics returns aggregated metrics about registered clients.
@@ -219,6 +353,7 @@ func (s *TranslationService) GetClientMetrics() *ClientMetrics {
 		TotalClients:       len(s.clients),
 		ClientsByNamespace: make(map[string]int),
 		ClientsByVersion:   make(map[string]int),
+		ActiveSessions:     len(s.sessions),
 	}
 	
 	if len(s.clients) == 0 {
@@ -243,6 +378,9 @@ func (s *TranslationService) GetClientMetrics() *ClientMetrics {
 			version = "unknown"
 		}
 		metrics.ClientsByVersion[version]++
+		if client.VersionStatus == compat.Deprecated {
+			metrics.DeprecatedClients++
+		}
 		
 		// Track heartbeat times
 		if client.LastHeartbeat.Before(oldest) {
@@ -259,6 +397,24 @@ func (s *TranslationService) GetClientMetrics() *ClientMetrics {
 	return metrics
 }
 
+// EvictClient removes a client registration. The client's next heartbeat is
+// told to re-register.
+func (s *TranslationService) EvictClient(clientID string) (*ClientInfo, bool) {
+	s.clientsMutex.Lock()
+	defer s.clientsMutex.Unlock()
+	
+	client, exists := s.clients[clientID]
+	if !exists {
+		return nil, false
+	}
+	delete(s.clients, clientID)
+	s.stopSessionLocked(clientID, status.Error(codes.NotFound, "client evicted, re-register"))
+	s.Logger.Printf("Client evicted: id=%q, name=%q, %d remaining", clientID, client.ClientName, len(s.clients))
+	
+	clientCopy := *client
+	return &clientCopy, true
+}
+
 // CleanupExpiredClients removes clients that haven't sent a heartbeat in a while.
 // This should be called periodically (e.g., every 5 minutes).
 func (s *TranslationService) CleanupExpiredClients(maxIdleTime time.Duration) {
@@ -269,6 +425,10 @@ func (s *TranslationService) CleanupExpiredClients(maxIdleTime time.Duration) {
 	removed := 0
 	
 	for clientID, client := range s.clients {
+		// An open session is proof of liveness on its own
+		if client.SessionActive {
+			continue
+		}
 		if now.Sub(client.LastHeartbeat) > maxIdleTime {
 			s.Logger.Printf("Removing expired client: id=%q, name=%q, last_heartbeat=%v", 
 				clientID, client.ClientName, client.LastHeartbeat)
@@ -298,6 +458,14 @@ func (s *TranslationService) CheckTitle(ctx context.Context, req *nanabushv1.Tit
 		return nil, status.Error(codes.InvalidArgument, "source_language is required")
 	}
 	
+	// A draining server sends new work elsewhere
+	if s.Draining() {
+		return &nanabushv1.TitleCheckResponse{
+			Ready:   false,
+			Message: "Server is draining, retry on another replica",
+		}, nil
+	}
+	
 	// Check backend health
 	if s.Backend != nil {
 		if err := s.Backend.CheckHealth(ctx); err != nil {
@@ -310,32 +478,121 @@ func (s *TranslationService) CheckTitle(ctx context.Context, req *nanabushv1.Tit
 		}
 	}
 	
-	// Estimate time based on title length (simple heuristic)
-	// TODO: Improve with actual backend metrics
-	estimatedSeconds := int32(5 + len(req.Title)/10)
-	if estimatedSeconds < 5 {
-		estimatedSeconds = 5
+	targetLang, err := parseLanguageTag("language_tag", req.LanguageTag)
+	if err != nil {
+		return nil, err
 	}
-	if estimatedSeconds > 60 {
-		estimatedSeconds = 60
+	
+	// Detect the source language from the title when the client asked for "auto"
+	sourceLang := req.SourceLanguage
+	var detection *langid.Result
+	if isAutoLanguage(sourceLang) {
+		// Titles are short, so an inconclusive result is reported rather than
+		// rejected; Translate detects again from the full document.
+		result, err := s.detectLanguage(req.Title)
+		if err != nil {
+			s.Logger.Printf("CheckTitle source language detection inconclusive: %v", err)
+		}
+		detection = &result
+		sourceLang = result.Language
 	}
 	
-	s.Logger.Printf("CheckTitle response: ready=true, estimated=%ds", estimatedSeconds)
+	// Reject unsupported language pairs up front; an inconclusive detection is left to Translate
+	if detection == nil || (detection.Language != langid.Undetermined && detection.Confidence >= minDetectionConfidence) {
+		pair, err := s.resolveLanguagePair(sourceLang, targetLang)
+		if err != nil {
+			s.Logger.Printf("CheckTitle rejected: %v", err)
+			return nil, err
+		}
+		sourceLang = pair.Source.String()
+		targetLang = pair.Target.String()
+	}
 	
-	return &nanabushv1.TitleCheckResponse{
-		Ready:                true,
-		Message:              "Ready to handle translation request",
-		EstimatedTimeSeconds: estimatedSeconds,
-	}, nil
+	// Estimate from observed backend throughput for this language pair.
+	// The expected document size, when given, dominates the title length.
+	size := len(req.Title)
+	if req.ExpectedDocumentChars > 0 {
+		size += int(req.ExpectedDocumentChars)
+	}
+	estimate := s.Estimator.Estimate(s.backendName(), sourceLang, targetLang, estimator.EstimateTokens(size))
+	
+	// Add the time the request would spend queued behind other jobs
+	var queueWait time.Duration
+	var queueDepth int
+	if s.Scheduler != nil {
+		priority, err := priorityFromContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		queueWait = s.Scheduler.EstimateWait(priority)
+		queueDepth = s.Scheduler.QueueAhead(priority)
+		
+		stats := s.Scheduler.Stats()
+		s.Logger.Printf("CheckTitle queue: priority=%s, depth=%d, running=%d, estimated_wait=%v",
+			priority, stats.QueueDepth, stats.Running, queueWait)
+	}
+	
+	resp := &nanabushv1.TitleCheckResponse{
+		Ready:                    true,
+		Message:                  "Ready to handle translation request",
+		EstimatedTimeSeconds:     ceilSeconds(estimate.Point + queueWait),
+		EstimatedTimeLowSeconds:  ceilSeconds(estimate.Low + queueWait),
+		EstimatedTimeHighSeconds: ceilSeconds(estimate.High + queueWait),
+		QueueDepth:               int32(queueDepth),
+	}
+	
+	if detection != nil {
+		resp.DetectedSourceLanguage = detection.Language
+		resp.DetectionConfidence = detection.Confidence
+	}
+	
+	s.Logger.Printf("CheckTitle response: ready=true, estimated=%ds (band %d-%ds, samples=%d)",
+		resp.EstimatedTimeSeconds, resp.EstimatedTimeLowSeconds, resp.EstimatedTimeHighSeconds, estimate.Samples)
+	
+	return resp, nil
 }
 
 // Translate performs full document translation.
 // This is the main translation endpoint that processes complete documents.
 func (s *TranslationService) Translate(ctx context.Context, req *nanabushv1.TranslateRequest) (*nanabushv1.TranslateResponse, error) {
+	ctx, finish := s.trackJob(ctx, req)
+	return s.runJob(ctx, req, finish)
+}
+
+// runJob runs a Translate job tracked by trackJob and reports the outcome,
+// calling finish only once the job is recorded and its callback queued so a
+// drain waiting for running jobs does not stop before them.
+func (s *TranslationService) runJob(ctx context.Context, req *nanabushv1.TranslateRequest, finish func()) (*nanabushv1.TranslateResponse, error) {
+	defer finish()
+	masked := make(map[string]int)
+	resp, err := s.translate(ctx, req, masked)
+	if jobs.Cancelled(ctx) && (err != nil || !resp.Success) {
+		s.Logger.Printf("Translate cancelled: job_id=%q", req.JobId)
+		resp, err = nil, cancelledJobError(req.JobId)
+	}
+	if jobs.Interrupted(ctx) && (err != nil || !resp.Success) {
+		s.Logger.Printf("Translate interrupted by shutdown: job_id=%q", req.JobId)
+		resp, err = nil, interruptedJobError(req.JobId)
+	}
+	s.auditTranslate(ctx, req, resp, err, masked)
+	if err == nil {
+		s.recordJob(ctx, req, resp)
+	}
+	s.notifyJobCompleted(ctx, req, resp, err)
+	s.sendCallback(ctx, req, resp, err)
+	return resp, err
+}
+
+// translate does the work of Translate. Counts of masked values are added to masked.
+func (s *TranslationService) translate(ctx context.Context, req *nanabushv1.TranslateRequest, masked map[string]int) (*nanabushv1.TranslateResponse, error) {
 	s.Logger.Printf("Translate request: job_id=%q, primitive=%v, namespace=%q", req.JobId, req.Primitive, req.Namespace)
 	
 	startTime := time.Now()
 	
+	if err := s.checkDraining(); err != nil {
+		return nil, err
+	}
+	
 	// Validate request
 	if req.JobId == "" {
 		return nil, status.Error(codes.InvalidArgument, "job_id is required")
@@ -346,13 +603,61 @@ func (s *TranslationService) Translate(ctx context.Context, req *nanabushv1.Tran
 	if req.SourceLanguage == "" {
 		return nil, status.Error(codes.InvalidArgument, "source_language is required")
 	}
+	if err := s.checkCallback(req.CallbackUrl); err != nil {
+		return nil, err
+	}
+	if err := checkGlossary("glossary", req.Glossary); err != nil {
+		return nil, err
+	}
 	
+	// Canonicalize BCP 47 tags ("EN" -> "en") before anything else looks at them
+	targetLang, err := parseLanguageTag("target_language", req.TargetLanguage)
+	if err != nil {
+		return nil, err
+	}
 	sourceLang := req.SourceLanguage
-	targetLang := req.TargetLanguage
+	if !isAutoLanguage(sourceLang) {
+		if sourceLang, err = parseLanguageTag("source_language", sourceLang); err != nil {
+			return nil, err
+		}
+	}
+	
+	// Detect the source language when the client asked for "auto"
+	var detection *langid.Result
+	if isAutoLanguage(sourceLang) && requestText(req) != "" {
+		result, err := s.detectLanguage(requestText(req))
+		if err != nil {
+			s.Logger.Printf("Source language detection failed: job_id=%q, err=%v", req.JobId, err)
+			return nil, err
+		}
+		detection = &result
+		sourceLang = result.Language
+		s.Logger.Printf("Detected source language: job_id=%q, language=%q, confidence=%.2f", req.JobId, result.Language, result.Confidence)
+		
+		// Nothing to do if the content is already in the target language
+		if result.Confidence >= skipConfidence && langid.SameLanguage(sourceLang, targetLang) {
+			s.Logger.Printf("Translate skipped: job_id=%q, source %q already matches target %q", req.JobId, sourceLang, targetLang)
+			return skippedResponse(req, result), nil
+		}
+	}
+	
+	// Map the request onto a supported language pair (with fallbacks such as fr-CA -> fr)
+	if !isAutoLanguage(sourceLang) {
+		pair, err := s.resolveLanguagePair(sourceLang, targetLang)
+		if err != nil {
+			s.Logger.Printf("Translate rejected: job_id=%q, %v", req.JobId, err)
+			return nil, err
+		}
+		if pair.Target.String() != targetLang {
+			s.Logger.Printf("Target language fallback: job_id=%q, %s -> %s", req.JobId, targetLang, pair.Target)
+		}
+		sourceLang = pair.Source.String()
+		targetLang = pair.Target.String()
+	}
 	
 	var translatedTitle string
 	var translatedDoc *nanabushv1.DocumentContent
-	var err error
+	glossary := s.backendGlossary(req.Glossary)
 	
 	// Handle different primitive types
 	switch req.Primitive {
@@ -363,16 +668,37 @@ func (s *TranslationService) Translate(ctx context.Context, req *nanabushv1.Tran
 		}
 		
 		if s.Backend != nil {
-			translatedTitle, err = s.Backend.TranslateTitle(ctx, req.GetTitle(), sourceLang, targetLang)
+			var release func()
+			release, err = s.acquireSlot(ctx, req.Namespace, len(req.GetTitle()))
+			if err != nil {
+				return nil, err
+			}
+			mask := s.newMask()
+			backendStart := time.Now()
+			translatedTitle, err = s.backendTitle(ctx, mask.Scrub(req.GetTitle()), sourceLang, targetLang, glossary)
+			release()
+			translatedTitle = mask.Restore(translatedTitle)
+			s.auditMask(req.JobId, mask)
+			addMaskCounts(masked, mask)
+			if err == nil {
+				s.Estimator.Observe(s.backendName(), sourceLang, targetLang,
+					estimator.EstimateTokens(len(req.GetTitle())), time.Since(backendStart))
+			}
 			if err != nil {
 				s.Logger.Printf("Translate title failed: %v", err)
 				return &nanabushv1.TranslateResponse{
 					JobId:        req.JobId,
 					Success:      false,
 					ErrorMessage: fmt.Sprintf("Translation failed: %v", err),
+					ErrorCode:    nanabushv1.ErrorCode_ERROR_CODE_BACKEND_FAILURE,
 					CompletedAt:  timestamppb.Now(),
 				}, nil
 			}
+			
+			// Reject output that does not look like a translation of the source
+			if violation := s.checkOutput(req.GetTitle(), translatedTitle, sourceLang, targetLang); violation != nil {
+				return s.rejectedResponse(req.JobId, violation), nil
+			}
 		} else {
 			// Placeholder: return original title when backend not implemented
 			translatedTitle = req.GetTitle() + " [translated]"
@@ -383,18 +709,42 @@ func (s *TranslationService) Translate(ctx context.Context, req *nanabushv1.Tran
 		if req.GetDoc() == nil {
 			return nil, status.Error(codes.InvalidArgument, "doc is required for PRIMITIVE_DOC_TRANSLATE")
 		}
+		if limit := s.Settings().MaxDocumentChars; limit > 0 && len(req.GetDoc().Markdown) > limit {
+			return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("doc markdown is %d characters, maximum is %d", len(req.GetDoc().Markdown), limit))
+		}
 		
 		if s.Backend != nil {
-			translatedDoc, err = s.Backend.TranslateDocument(ctx, req.GetDoc(), sourceLang, targetLang)
+			var release func()
+			release, err = s.acquireSlot(ctx, req.Namespace, len(req.GetDoc().Markdown))
+			if err != nil {
+				return nil, err
+			}
+			mask := s.newMask()
+			backendStart := time.Now()
+			translatedDoc, err = s.backendDocument(ctx, scrubDocument(mask, req.GetDoc()), sourceLang, targetLang, glossary)
+			release()
+			restoreDocument(mask, translatedDoc)
+			s.auditMask(req.JobId, mask)
+			addMaskCounts(masked, mask)
+			if err == nil && translatedDoc != nil {
+				s.Estimator.Observe(s.backendName(), sourceLang, targetLang,
+					estimator.EstimateTokens(len(req.GetDoc().Title)+len(req.GetDoc().Markdown)), time.Since(backendStart))
+			}
 			if err != nil {
 				s.Logger.Printf("Translate document failed: %v", err)
 				return &nanabushv1.TranslateResponse{
 					JobId:        req.JobId,
 					Success:      false,
 					ErrorMessage: fmt.Sprintf("Translation failed: %v", err),
+					ErrorCode:    nanabushv1.ErrorCode_ERROR_CODE_BACKEND_FAILURE,
 					CompletedAt:  timestamppb.Now(),
 				}, nil
 			}
+			
+			// Reject output that does not look like a translation of the source
+			if violation := s.checkDocumentOutput(req.GetDoc(), translatedDoc, sourceLang, targetLang); violation != nil {
+				return s.rejectedResponse(req.JobId, violation), nil
+			}
 		} else {
 			// Placeholder: return original document when backend not implemented
 			translatedDoc = &nanabushv1.DocumentContent{
@@ -418,6 +768,13 @@ func (s *TranslationService) Translate(ctx context.Context, req *nanabushv1.Tran
 		CompletedAt:         timestamppb.Now(),
 		TokensUsed:          0, // TODO: Get from backend
 		InferenceTimeSeconds: inferenceTime,
+		ResolvedSourceLanguage: sourceLang,
+		ResolvedTargetLanguage: targetLang,
+	}
+	
+	if detection != nil {
+		resp.DetectedSourceLanguage = detection.Language
+		resp.DetectionConfidence = detection.Confidence
 	}
 	
 	if translatedTitle != "" {
@@ -430,14 +787,90 @@ func (s *TranslationService) Translate(ctx context.Context, req *nanabushv1.Tran
 		}
 	}
 	
-	s.Logger.Printf("Translate response: job_id=%q, success=true, time=%.2fs", req.JobId, inferenceTime)
+	s.scoreResponse(req, resp)
+	
+	s.Logger.Printf("Translate response: job_id=%q, success=true, time=%.2fs, quality=%.3f", req.JobId, inferenceTime, resp.QualityScore)
 	
 	return resp, nil
 }
 
+// acquireSlot waits for the scheduler to admit a backend call.
+// The returned release function must be called once the call completes.
+func (s *TranslationService) acquireSlot(ctx context.Context, namespace string, cost int) (func(), error) {
+	if s.Scheduler == nil {
+		return func() {}, nil
+	}
+	
+	priority, err := priorityFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if namespace == "" {
+		namespace = "default"
+	}
+	
+	release, err := s.Scheduler.Acquire(ctx, namespace, priority, cost)
+	if err != nil {
+		s.Logger.Printf("Scheduler wait aborted: namespace=%q, priority=%s, err=%v", namespace, priority, err)
+		return nil, status.FromContextError(err).Err()
+	}
+	return release, nil
+}
+
+// backendName identifies the backend for throughput tracking.
+func (s *TranslationService) backendName() string {
+	if s.Backend == nil {
+		return "placeholder"
+	}
+	if named, ok := s.Backend.(interface{ Name() string }); ok {
+		return named.Name()
+	}
+	return fmt.Sprintf("%T", s.Backend)
+}
+
+// ceilSeconds rounds d up to whole seconds, with a minimum of one second.
+func ceilSeconds(d time.Duration) int32 {
+	secs := int32((d + time.Second - 1) / time.Second)
+	if secs < 1 {
+		secs = 1
+	}
+	return secs
+}
+
+// priorityFromContext reads the scheduling class from incoming gRPC metadata.
+func priorityFromContext(ctx context.Context) (scheduler.Priority, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return scheduler.PriorityNormal, nil
+	}
+	values := md.Get(PriorityMetadataKey)
+	if len(values) == 0 {
+		return scheduler.PriorityNormal, nil
+	}
+	priority, err := scheduler.ParsePriority(values[0])
+	if err != nil {
+		return scheduler.PriorityNormal, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return priority, nil
+}
+
 // TranslateStream supports streaming for large documents.
 // Client sends chunks, server responds with translated chunks.
 func (s *TranslationService) TranslateStream(stream nanabushv1.TranslationService_TranslateStreamServer) error {
+	if err := s.checkDraining(); err != nil {
+		return err
+	}
+	if s.Audit == nil {
+		return s.translateStream(stream)
+	}
+	audited := newAuditedStream(stream)
+	err := s.translateStream(audited)
+	s.auditStream(audited, err)
+	return err
+}
+
+// translateStream does the work of TranslateStream.
+func (s *TranslationService) translateStream(stream nanabushv1.TranslationService_TranslateStreamServer) error {
 	s.Logger.Println("TranslateStream request started")
 	
 	var jobID string
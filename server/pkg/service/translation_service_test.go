@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/dasmlab/nanabush/server/pkg/compat"
+	"github.com/dasmlab/nanabush/server/pkg/proto/v1"
+)
+
+func TestRegisterClientVersionPolicy(t *testing.T) {
+	policy, err := compat.NewPolicy("1.0.0", []string{"<1.4.0"}, []string{">=1.5.0 <1.5.2"})
+	if err != nil {
+		t.Fatalf("NewPolicy: %v", err)
+	}
+	allowing := *policy
+	allowing.AllowUnversioned = true
+
+	tests := []struct {
+		name        string
+		policy      *compat.Policy
+		version     string
+		wantCode    codes.Code
+		wantMessage string
+		wantStatus  compat.Status
+	}{
+		{"supported", policy, "1.4.0", codes.OK, "", compat.Supported},
+		{"deprecated", policy, "1.2.0", codes.OK, "deprecated", compat.Deprecated},
+		{"older than the minimum", policy, "0.9.0", codes.FailedPrecondition, "older than the minimum", 0},
+		{"blocked", policy, "1.5.1", codes.FailedPrecondition, "is blocked", 0},
+		{"unversioned", policy, "dev", codes.FailedPrecondition, "not a semantic version", 0},
+		{"no version", policy, "", codes.FailedPrecondition, "not a semantic version", 0},
+		{"unversioned allowed", &allowing, "dev", codes.OK, "cannot be checked", compat.Unknown},
+		{"no policy", nil, "dev", codes.OK, "", compat.Supported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewTranslationService(nil, discard)
+			settings := s.Settings()
+			settings.VersionPolicy = tt.policy
+			s.ApplySettings(settings)
+
+			resp, err := s.RegisterClient(context.Background(), &nanabushv1.RegisterClientRequest{ClientName: "glooscap", ClientVersion: tt.version})
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("RegisterClient: %v, want code %v", err, tt.wantCode)
+			}
+			if err != nil {
+				if !strings.Contains(status.Convert(err).Message(), tt.wantMessage) {
+					t.Errorf("error %q, want it to contain %q", status.Convert(err).Message(), tt.wantMessage)
+				}
+				if len(s.clients) != 0 {
+					t.Errorf("refused client was registered")
+				}
+				return
+			}
+			if !strings.Contains(resp.Message, tt.wantMessage) {
+				t.Errorf("message %q, want it to contain %q", resp.Message, tt.wantMessage)
+			}
+			if got := s.clients[resp.ClientId].VersionStatus; got != tt.wantStatus {
+				t.Errorf("version status %v, want %v", got, tt.wantStatus)
+			}
+		})
+	}
+}
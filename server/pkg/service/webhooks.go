@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/dasmlab/nanabush/server/pkg/proto/v1"
+	"github.com/dasmlab/nanabush/server/pkg/webhook"
+)
+
+// SubmitTranslate queues a translation and returns without waiting for it.
+// The outcome is delivered to the request's callback_url, including a job
+// interrupted by a shutdown, which the client must resubmit: the request is
+// not kept anywhere it could be resumed from.
+func (s *TranslationService) SubmitTranslate(ctx context.Context, req *nanabushv1.TranslateRequest) (*nanabushv1.SubmitTranslateResponse, error) {
+	s.Logger.Printf("SubmitTranslate request: job_id=%q, primitive=%v, namespace=%q", req.JobId, req.Primitive, req.Namespace)
+
+	if err := s.checkDraining(); err != nil {
+		return nil, err
+	}
+	if req.JobId == "" {
+		return nil, status.Error(codes.InvalidArgument, "job_id is required")
+	}
+	if req.CallbackUrl == "" {
+		return nil, status.Error(codes.InvalidArgument, "callback_url is required")
+	}
+	if err := s.checkCallback(req.CallbackUrl); err != nil {
+		return nil, err
+	}
+	if req.TargetLanguage == "" {
+		return nil, status.Error(codes.InvalidArgument, "target_language is required")
+	}
+	if req.SourceLanguage == "" {
+		return nil, status.Error(codes.InvalidArgument, "source_language is required")
+	}
+	if _, err := priorityFromContext(ctx); err != nil {
+		return nil, err
+	}
+
+	// The job outlives the call: keep the caller's metadata (client ID,
+	// priority) but not its deadline or cancellation. Tracking it before
+	// returning means a drain that starts now waits for it.
+	md, _ := metadata.FromIncomingContext(ctx)
+	jobCtx := metadata.NewIncomingContext(context.Background(), md.Copy())
+	jobCtx, finish := s.trackJob(jobCtx, req)
+	go s.runJob(jobCtx, req, finish)
+
+	s.Logger.Printf("SubmitTranslate accepted: job_id=%q, callback=%q", req.JobId, req.CallbackUrl)
+	return &nanabushv1.SubmitTranslateResponse{
+		JobId:      req.JobId,
+		AcceptedAt: timestamppb.Now(),
+	}, nil
+}
+
+// checkCallback rejects a callback_url that cannot be used. An empty URL is fine.
+func (s *TranslationService) checkCallback(callbackURL string) error {
+	if callbackURL == "" {
+		return nil
+	}
+	if s.Webhooks == nil {
+		return status.Error(codes.FailedPrecondition, "callbacks are not enabled on this server")
+	}
+	if err := s.Webhooks.CheckURL(callbackURL); err != nil {
+		return status.Error(codes.InvalidArgument, fmt.Sprintf("invalid callback_url: %v", err))
+	}
+	return nil
+}
+
+// sendCallback delivers the outcome of a Translate call to its callback_url,
+// if it has a usable one.
+func (s *TranslationService) sendCallback(ctx context.Context, req *nanabushv1.TranslateRequest, resp *nanabushv1.TranslateResponse, err error) {
+	if req.GetCallbackUrl() == "" || s.checkCallback(req.CallbackUrl) != nil {
+		return
+	}
+
+	clientID, _ := s.clientFromContext(ctx)
+	callback := &nanabushv1.JobCallback{
+		Event:       webhook.EventJobCompleted,
+		JobId:       req.JobId,
+		ClientId:    clientID,
+		CompletedAt: timestamppb.Now(),
+	}
+	if err != nil {
+		st := status.Convert(err)
+		callback.StatusCode = int32(st.Code())
+		callback.ErrorMessage = st.Message()
+	} else {
+		callback.Success = resp.Success
+		callback.Response = resp
+		callback.ErrorMessage = resp.ErrorMessage
+	}
+	s.Webhooks.Send(req.CallbackUrl, callback)
+}
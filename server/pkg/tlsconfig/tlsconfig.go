@@ -0,0 +1,87 @@
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// Files names the PEM files used for TLS. The same flags (-tls-cert,
+// -tls-key, -tls-ca) configure the server and its clients.
+type Files struct {
+	// CertFile and KeyFile are this side's certificate and private key.
+	// For clients they are only needed for mTLS.
+	CertFile string
+	KeyFile  string
+
+	// CAFile verifies the peer. On the server it enables mTLS (client
+	// certificates required); on clients it replaces the system roots.
+	CAFile string
+
+	// ServerName overrides the name clients verify the server certificate against.
+	ServerName string
+}
+
+// Server builds a server TLS config. A CA file makes client certificates mandatory.
+func Server(files Files) (*tls.Config, error) {
+	if files.CertFile == "" || files.KeyFile == "" {
+		return nil, fmt.Errorf("TLS requires both a certificate and a private key")
+	}
+	cert, err := tls.LoadX509KeyPair(files.CertFile, files.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load server certificate: %w", err)
+	}
+
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if files.CAFile != "" {
+		pool, err := loadPool(files.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return cfg, nil
+}
+
+// Client builds a client TLS config. A certificate and key enable mTLS.
+func Client(files Files) (*tls.Config, error) {
+	cfg := &tls.Config{
+		ServerName: files.ServerName,
+		MinVersion: tls.VersionTLS12,
+	}
+	if files.CAFile != "" {
+		pool, err := loadPool(files.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = pool
+	}
+	if files.CertFile != "" || files.KeyFile != "" {
+		if files.CertFile == "" || files.KeyFile == "" {
+			return nil, fmt.Errorf("mTLS requires both a client certificate and a private key")
+		}
+		cert, err := tls.LoadX509KeyPair(files.CertFile, files.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+func loadPool(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", path)
+	}
+	return pool, nil
+}
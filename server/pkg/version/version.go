@@ -0,0 +1,5 @@
+package version
+
+// Version is the server version, set at build time with
+// -ldflags "-X github.com/dasmlab/nanabush/server/pkg/version.Version=v0.3.0".
+var Version = "dev"
@@ -0,0 +1,546 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	mathrand "math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/dasmlab/nanabush/server/pkg/proto/v1"
+)
+
+// EventJobCompleted is the event of a callback sent when a job finishes.
+const EventJobCompleted = "job.completed"
+
+// Headers sent with every callback.
+const (
+	HeaderEvent     = "Nanabush-Event"
+	HeaderDelivery  = "Nanabush-Delivery"
+	HeaderAttempt   = "Nanabush-Attempt"
+	HeaderTimestamp = "Nanabush-Timestamp"
+	HeaderSignature = "Nanabush-Signature"
+)
+
+// signaturePrefix names the algorithm in HeaderSignature.
+const signaturePrefix = "sha256="
+
+// DefaultTolerance is how far a callback's timestamp may be from the
+// receiver's clock before Verify rejects it as a replay.
+const DefaultTolerance = 5 * time.Minute
+
+// maxDeadLetters bounds the dead letters kept in memory for listing.
+const maxDeadLetters = 100
+
+// maxErrorBody is how much of a failed response is kept in the error.
+const maxErrorBody = 256
+
+var payloadJSON = protojson.MarshalOptions{UseProtoNames: true}
+
+// errBlockedAddress is returned for callbacks to addresses that are refused
+// without an allow list. Such callbacks are not retried.
+var errBlockedAddress = errors.New("callbacks to loopback, private and link-local addresses need webhook allowed hosts")
+
+// Config configures callback delivery.
+type Config struct {
+	// Secret keys the HMAC signature of every callback (required)
+	Secret string
+
+	// AllowedHosts restricts callback URLs to these hosts, exact or as
+	// "*.example.com" for subdomains. Empty allows any host whose addresses
+	// are public: loopback, private and link-local addresses are refused.
+	AllowedHosts []string
+
+	// MaxAttempts is how many times a callback is tried before it is dead-lettered
+	MaxAttempts int
+
+	// InitialBackoff is the wait before the first retry; it doubles per
+	// attempt up to MaxBackoff, with jitter
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	// Timeout of each attempt
+	Timeout time.Duration
+
+	// DeadLetterFile receives a JSON line, with the payload, for every
+	// callback given up on (empty keeps dead letters in memory only)
+	DeadLetterFile string
+}
+
+// DefaultConfig returns the default delivery settings, without a secret.
+func DefaultConfig() Config {
+	return Config{
+		MaxAttempts:    6,
+		InitialBackoff: 2 * time.Second,
+		MaxBackoff:     2 * time.Minute,
+		Timeout:        10 * time.Second,
+	}
+}
+
+// DeadLetter is a callback that could not be delivered.
+type DeadLetter struct {
+	DeliveryID     string    `json:"delivery_id"`
+	JobID          string    `json:"job_id"`
+	ClientID       string    `json:"client_id,omitempty"`
+	URL            string    `json:"callback_url"`
+	Attempts       int       `json:"attempts"`
+	LastStatusCode int       `json:"last_status_code,omitempty"`
+	LastError      string    `json:"last_error"`
+	FailedAt       time.Time `json:"failed_at"`
+
+	// Payload is the JobCallback body; it is only written to the dead-letter file
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// Stats counts callback deliveries.
+type Stats struct {
+	Pending      int // Being delivered or waiting to retry
+	Delivered    int64
+	DeadLettered int64
+}
+
+// Dispatcher delivers callbacks in the background. Each is POSTed with an
+// HMAC signature and retried with exponential backoff on network errors,
+// timeouts, 408, 429 and 5xx responses; other responses, running out of
+// attempts and shutting down turn it into a dead letter. Redirects are not
+// followed, since only the callback URL itself was checked.
+type Dispatcher struct {
+	cfg    Config
+	client *http.Client
+	logger *log.Logger
+
+	closing chan struct{}   // Closed by Close: no more retries
+	ctx     context.Context // Cancelled when Close gives up on in-flight attempts
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+
+	mu           sync.Mutex
+	closed       bool
+	pending      int
+	delivered    int64
+	deadLettered int64
+	deadLetters  []DeadLetter // Oldest first, without payloads
+	file         *os.File
+}
+
+// New creates a Dispatcher. Zero settings in cfg use the defaults.
+func New(cfg Config, logger *log.Logger) (*Dispatcher, error) {
+	if cfg.Secret == "" {
+		return nil, errors.New("a webhook secret is required to sign callbacks")
+	}
+	defaults := DefaultConfig()
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaults.MaxAttempts
+	}
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = defaults.InitialBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = defaults.MaxBackoff
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaults.Timeout
+	}
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	client := &http.Client{
+		Timeout:   cfg.Timeout,
+		Transport: newTransport(len(cfg.AllowedHosts) == 0),
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse // A 3xx is a failed delivery
+		},
+	}
+	d := &Dispatcher{
+		cfg:     cfg,
+		client:  client,
+		logger:  logger,
+		closing: make(chan struct{}),
+	}
+	d.ctx, d.cancel = context.WithCancel(context.Background())
+	if cfg.DeadLetterFile != "" {
+		f, err := os.OpenFile(cfg.DeadLetterFile, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open dead-letter file: %w", err)
+		}
+		d.file = f
+	}
+	return d, nil
+}
+
+// newTransport returns the transport callbacks are sent with. With
+// blockPrivate, connections to loopback, private and link-local addresses
+// are refused as they are dialed, after DNS resolution, so a callback URL
+// cannot reach services in the cluster or a cloud metadata endpoint.
+func newTransport(blockPrivate bool) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if blockPrivate {
+		transport.Proxy = nil // The dialer must see the callback's own address
+		dialer := &net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+			Control:   refusePrivate,
+		}
+		transport.DialContext = dialer.DialContext
+	}
+	return transport
+}
+
+// refusePrivate is a net.Dialer Control function refusing connections to
+// blocked addresses.
+func refusePrivate(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || blockedIP(ip) {
+		return fmt.Errorf("%w: %s", errBlockedAddress, host)
+	}
+	return nil
+}
+
+// blockedIP reports whether ip is refused without an allow list.
+func blockedIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified()
+}
+
+// CheckURL reports whether raw can be used as a callback URL.
+func (d *Dispatcher) CheckURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("scheme must be http or https, got %q", u.Scheme)
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "" {
+		return errors.New("host is required")
+	}
+	if len(d.cfg.AllowedHosts) == 0 {
+		// Host names are checked again when dialed, once resolved
+		if ip := net.ParseIP(host); host == "localhost" || (ip != nil && blockedIP(ip)) {
+			return fmt.Errorf("host %q: %w", host, errBlockedAddress)
+		}
+		return nil
+	}
+	for _, allowed := range d.cfg.AllowedHosts {
+		allowed = strings.ToLower(allowed)
+		if host == allowed || (strings.HasPrefix(allowed, "*.") && strings.HasSuffix(host, allowed[1:])) {
+			return nil
+		}
+	}
+	return fmt.Errorf("host %q is not allowed", host)
+}
+
+// Send delivers callback to rawURL in the background. It fills in the
+// event and a delivery ID when they are not set.
+func (d *Dispatcher) Send(rawURL string, callback *nanabushv1.JobCallback) {
+	if callback.Event == "" {
+		callback.Event = EventJobCompleted
+	}
+	if callback.DeliveryId == "" {
+		callback.DeliveryId = newDeliveryID()
+	}
+	letter := DeadLetter{
+		DeliveryID: callback.DeliveryId,
+		JobID:      callback.JobId,
+		ClientID:   callback.ClientId,
+		URL:        rawURL,
+	}
+	body, err := payloadJSON.Marshal(callback)
+	if err != nil {
+		letter.LastError = fmt.Sprintf("failed to encode callback: %v", err)
+		d.deadLetter(letter)
+		return
+	}
+	letter.Payload = body
+
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		letter.LastError = "server shut down before delivery"
+		d.deadLetter(letter)
+		return
+	}
+	d.pending++
+	d.wg.Add(1)
+	d.mu.Unlock()
+
+	go func() {
+		defer d.wg.Done()
+		d.deliver(letter, callback.Event, body)
+	}()
+}
+
+// deliver tries a callback until it is delivered or dead-lettered.
+func (d *Dispatcher) deliver(letter DeadLetter, event string, body []byte) {
+	defer func() {
+		d.mu.Lock()
+		d.pending--
+		d.mu.Unlock()
+	}()
+
+	for attempt := 1; ; attempt++ {
+		code, retryAfter, err := d.post(letter, event, body, attempt)
+		if err == nil {
+			d.mu.Lock()
+			d.delivered++
+			d.mu.Unlock()
+			d.logger.Printf("Callback delivered: job_id=%q, delivery_id=%q, attempts=%d", letter.JobID, letter.DeliveryID, attempt)
+			return
+		}
+		letter.Attempts, letter.LastStatusCode, letter.LastError = attempt, code, err.Error()
+		if !retryable(code) || errors.Is(err, errBlockedAddress) || attempt >= d.cfg.MaxAttempts {
+			d.deadLetter(letter)
+			return
+		}
+
+		wait := d.backoff(attempt, retryAfter)
+		d.logger.Printf("Callback attempt %d of %d failed, retrying in %v: job_id=%q, delivery_id=%q, err=%v",
+			attempt, d.cfg.MaxAttempts, wait.Round(time.Millisecond), letter.JobID, letter.DeliveryID, err)
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-d.closing:
+			timer.Stop()
+			letter.LastError = fmt.Sprintf("server shut down before retrying: %v", err)
+			d.deadLetter(letter)
+			return
+		}
+	}
+}
+
+// post makes one delivery attempt. It returns the HTTP status (0 without a
+// response) and any Retry-After delay the receiver asked for.
+func (d *Dispatcher) post(letter DeadLetter, event string, body []byte, attempt int) (int, time.Duration, error) {
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, letter.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, 0, err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "nanabush-webhook/1")
+	req.Header.Set(HeaderEvent, event)
+	req.Header.Set(HeaderDelivery, letter.DeliveryID)
+	req.Header.Set(HeaderAttempt, strconv.Itoa(attempt))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(d.cfg.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	io.Copy(io.Discard, resp.Body) // Let the connection be reused
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, 0, nil
+	}
+	var retryAfter time.Duration
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		retryAfter = time.Duration(seconds) * time.Second
+	}
+	if text := strings.TrimSpace(string(detail)); text != "" {
+		return resp.StatusCode, retryAfter, fmt.Errorf("HTTP %d: %s", resp.StatusCode, text)
+	}
+	return resp.StatusCode, retryAfter, fmt.Errorf("HTTP %d", resp.StatusCode)
+}
+
+// retryable reports whether an attempt that got status code (0 for no
+// response) may succeed if tried again.
+func retryable(code int) bool {
+	switch {
+	case code == 0, code == http.StatusRequestTimeout, code == http.StatusTooEarly, code == http.StatusTooManyRequests:
+		return true
+	case code >= 500:
+		return true
+	}
+	return false
+}
+
+// backoff returns the wait after a failed attempt: InitialBackoff doubled
+// per attempt, capped at MaxBackoff, between half and all of it at random.
+// A longer Retry-After from the receiver wins, up to MaxBackoff.
+func (d *Dispatcher) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	wait := d.cfg.InitialBackoff
+	for i := 1; i < attempt && wait < d.cfg.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > d.cfg.MaxBackoff {
+		wait = d.cfg.MaxBackoff
+	}
+	wait = wait/2 + time.Duration(mathrand.Int63n(int64(wait/2)+1))
+	if retryAfter > wait {
+		wait = retryAfter
+		if wait > d.cfg.MaxBackoff {
+			wait = d.cfg.MaxBackoff
+		}
+	}
+	return wait
+}
+
+// deadLetter records a callback given up on.
+func (d *Dispatcher) deadLetter(letter DeadLetter) {
+	if letter.FailedAt.IsZero() {
+		letter.FailedAt = time.Now()
+	}
+	d.logger.Printf("Callback dead-lettered: job_id=%q, delivery_id=%q, url=%q, attempts=%d, err=%s",
+		letter.JobID, letter.DeliveryID, letter.URL, letter.Attempts, letter.LastError)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.deadLettered++
+	if d.file != nil {
+		line, err := json.Marshal(letter)
+		if err == nil {
+			_, err = d.file.Write(append(line, '\n'))
+		}
+		if err != nil {
+			d.logger.Printf("ERROR: dead-letter file write failed: delivery_id=%q, err=%v", letter.DeliveryID, err)
+		}
+	}
+	letter.Payload = nil
+	d.deadLetters = append(d.deadLetters, letter)
+	if len(d.deadLetters) > maxDeadLetters {
+		d.deadLetters = append(d.deadLetters[:0], d.deadLetters[len(d.deadLetters)-maxDeadLetters:]...)
+	}
+}
+
+// DeadLetters returns the most recent dead letters, newest first, without payloads.
+func (d *Dispatcher) DeadLetters() []DeadLetter {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	letters := make([]DeadLetter, 0, len(d.deadLetters))
+	for i := len(d.deadLetters) - 1; i >= 0; i-- {
+		letters = append(letters, d.deadLetters[i])
+	}
+	return letters
+}
+
+// Stats returns delivery counts.
+func (d *Dispatcher) Stats() Stats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return Stats{Pending: d.pending, Delivered: d.delivered, DeadLettered: d.deadLettered}
+}
+
+// Close stops retrying and waits until ctx is done for attempts in flight,
+// then cancels them. Callbacks not delivered by then, and any sent later,
+// are dead-lettered. It returns how many callbacks were still pending.
+func (d *Dispatcher) Close(ctx context.Context) int {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return 0
+	}
+	d.closed = true
+	pending := d.pending
+	d.mu.Unlock()
+	close(d.closing)
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		d.cancel()
+		<-done
+	}
+	d.cancel()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.file != nil {
+		d.file.Close()
+		d.file = nil
+	}
+	return pending
+}
+
+// Sign returns the HeaderSignature value for a body sent at timestamp.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a received callback's signature, and that its timestamp is
+// within tolerance of now (0 uses DefaultTolerance). Receivers should call
+// it on the raw body before decoding it.
+func Verify(secret string, header http.Header, body []byte, tolerance time.Duration) error {
+	if tolerance <= 0 {
+		tolerance = DefaultTolerance
+	}
+	timestamp, err := strconv.ParseInt(header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return fmt.Errorf("missing or invalid %s header", HeaderTimestamp)
+	}
+	if age := time.Since(time.Unix(timestamp, 0)); age > tolerance || age < -tolerance {
+		return fmt.Errorf("timestamp is %v away from now, more than %v", age.Round(time.Second), tolerance)
+	}
+	signature := header.Get(HeaderSignature)
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return fmt.Errorf("missing or invalid %s header", HeaderSignature)
+	}
+	if !hmac.Equal([]byte(signature), []byte(Sign(secret, timestamp, body))) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
+
+// maxCallbackBytes bounds the callback bodies Receive reads.
+const maxCallbackBytes = 8 << 20
+
+// Receive reads, verifies and decodes a callback request, for receivers
+// written in Go. Unknown fields are ignored so newer servers can add them.
+func Receive(secret string, r *http.Request) (*nanabushv1.JobCallback, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxCallbackBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read callback: %w", err)
+	}
+	if err := Verify(secret, r.Header, body, 0); err != nil {
+		return nil, err
+	}
+	callback := &nanabushv1.JobCallback{}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(body, callback); err != nil {
+		return nil, fmt.Errorf("failed to decode callback: %w", err)
+	}
+	return callback, nil
+}
+
+// newDeliveryID returns a random delivery ID.
+func newDeliveryID() string {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return "dlv-" + hex.EncodeToString(b)
+}
@@ -0,0 +1,296 @@
+package webhook
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dasmlab/nanabush/server/pkg/proto/v1"
+)
+
+const testSecret = "test-secret"
+
+var discard = log.New(io.Discard, "", 0)
+
+func TestSignVerify(t *testing.T) {
+	body := []byte(`{"job_id":"job-1"}`)
+	now := time.Now().Unix()
+	signed := func(secret string, timestamp int64, body []byte) http.Header {
+		header := http.Header{}
+		header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+		header.Set(HeaderSignature, Sign(secret, timestamp, body))
+		return header
+	}
+
+	tests := []struct {
+		name    string
+		header  http.Header
+		wantErr string
+	}{
+		{"valid", signed(testSecret, now, body), ""},
+		{"within tolerance", signed(testSecret, now-60, body), ""},
+		{"wrong secret", signed("other", now, body), "signature mismatch"},
+		{"other body", signed(testSecret, now, []byte(`{"job_id":"job-2"}`)), "signature mismatch"},
+		{"too old", signed(testSecret, now-int64(DefaultTolerance/time.Second)-60, body), "away from now"},
+		{"from the future", signed(testSecret, now+int64(DefaultTolerance/time.Second)+60, body), "away from now"},
+		{"no timestamp", http.Header{HeaderSignature: {Sign(testSecret, now, body)}}, HeaderTimestamp},
+		{"no signature", http.Header{HeaderTimestamp: {strconv.FormatInt(now, 10)}}, HeaderSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(testSecret, tt.header, body, 0)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Verify: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Verify = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	// A different timestamp signs differently, so it cannot be swapped in
+	if Sign(testSecret, now, body) == Sign(testSecret, now+1, body) {
+		t.Error("Sign ignores the timestamp")
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		url     string
+		wantErr bool
+	}{
+		{"public host", nil, "https://hooks.example.com/nanabush", false},
+		{"public address", nil, "http://203.0.113.10:8080/", false},
+		{"scheme", nil, "ftp://hooks.example.com/", true},
+		{"no host", nil, "https:///path", true},
+		{"loopback", nil, "http://127.0.0.1:8080/", true},
+		{"localhost", nil, "http://LOCALHOST/", true},
+		{"IPv6 loopback", nil, "http://[::1]/", true},
+		{"private", nil, "http://10.0.0.5/", true},
+		{"metadata endpoint", nil, "http://169.254.169.254/latest/meta-data/", true},
+		{"unspecified", nil, "http://0.0.0.0/", true},
+		{"allowed exactly", []string{"127.0.0.1"}, "http://127.0.0.1:8080/", false},
+		{"allowed subdomain", []string{"*.glooscap.svc"}, "http://api.glooscap.svc:8080/", false},
+		{"not allowed", []string{"*.glooscap.svc"}, "https://hooks.example.com/", true},
+		{"wildcard needs a subdomain", []string{"*.glooscap.svc"}, "http://evilglooscap.svc/", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := New(Config{Secret: testSecret, AllowedHosts: tt.allowed}, discard)
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			if err := d.CheckURL(tt.url); (err != nil) != tt.wantErr {
+				t.Fatalf("CheckURL(%q) = %v, want error: %v", tt.url, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// response is one reply from a test receiver.
+type response struct {
+	status     int
+	retryAfter string
+}
+
+// receiver is a callback endpoint that replies with responses in turn,
+// then 200, and records the attempts it saw.
+type receiver struct {
+	t         *testing.T
+	responses []response
+
+	mu       sync.Mutex
+	attempts []time.Time
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if _, err := Receive(testSecret, r); err != nil {
+		rc.t.Errorf("Receive: %v", err)
+	}
+	rc.mu.Lock()
+	n := len(rc.attempts)
+	rc.attempts = append(rc.attempts, time.Now())
+	rc.mu.Unlock()
+	if got := r.Header.Get(HeaderAttempt); got != strconv.Itoa(n+1) {
+		rc.t.Errorf("%s = %q, want %d", HeaderAttempt, got, n+1)
+	}
+	if n >= len(rc.responses) {
+		return
+	}
+	if rc.responses[n].retryAfter != "" {
+		w.Header().Set("Retry-After", rc.responses[n].retryAfter)
+	}
+	if rc.responses[n].status == http.StatusFound {
+		http.Redirect(w, r, "/elsewhere", http.StatusFound)
+		return
+	}
+	w.WriteHeader(rc.responses[n].status)
+}
+
+// waitDelivered waits until d has no callbacks pending.
+func waitDelivered(t *testing.T, d *Dispatcher) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for d.Stats().Pending != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("callbacks still pending: %+v", d.Stats())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestDelivery(t *testing.T) {
+	tests := []struct {
+		name         string
+		responses    []response
+		wantAttempts int
+		wantDead     bool
+		wantStatus   int           // Last status code of the dead letter
+		wantWait     time.Duration // Minimum wait before the second attempt
+	}{
+		{
+			name:         "delivered first time",
+			wantAttempts: 1,
+		},
+		{
+			name:         "5xx is retried",
+			responses:    []response{{status: http.StatusBadGateway}, {status: http.StatusServiceUnavailable}},
+			wantAttempts: 3,
+		},
+		{
+			name:         "429 waits for Retry-After",
+			responses:    []response{{status: http.StatusTooManyRequests, retryAfter: "1"}},
+			wantAttempts: 2,
+			wantWait:     time.Second,
+		},
+		{
+			name:         "dead-lettered after MaxAttempts",
+			responses:    []response{{status: 500}, {status: 500}, {status: 500}, {status: 500}},
+			wantAttempts: 3,
+			wantDead:     true,
+			wantStatus:   500,
+		},
+		{
+			name:         "4xx is not retried",
+			responses:    []response{{status: http.StatusBadRequest}},
+			wantAttempts: 1,
+			wantDead:     true,
+			wantStatus:   http.StatusBadRequest,
+		},
+		{
+			name:         "redirects are not followed",
+			responses:    []response{{status: http.StatusFound}},
+			wantAttempts: 1,
+			wantDead:     true,
+			wantStatus:   http.StatusFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rc := &receiver{t: t, responses: tt.responses}
+			server := httptest.NewServer(rc)
+			defer server.Close()
+
+			d, err := New(Config{
+				Secret:         testSecret,
+				AllowedHosts:   []string{"127.0.0.1"},
+				MaxAttempts:    3,
+				InitialBackoff: time.Millisecond,
+				MaxBackoff:     2 * time.Second,
+			}, discard)
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			d.Send(server.URL+"/callback", &nanabushv1.JobCallback{JobId: "job-1", ClientId: "client-1"})
+			waitDelivered(t, d)
+
+			rc.mu.Lock()
+			attempts := rc.attempts
+			rc.mu.Unlock()
+			if len(attempts) != tt.wantAttempts {
+				t.Fatalf("receiver saw %d attempts, want %d", len(attempts), tt.wantAttempts)
+			}
+			if tt.wantWait > 0 {
+				if wait := attempts[1].Sub(attempts[0]); wait < tt.wantWait {
+					t.Errorf("retried after %v, want at least %v", wait, tt.wantWait)
+				}
+			}
+
+			stats := d.Stats()
+			letters := d.DeadLetters()
+			if !tt.wantDead {
+				if stats.Delivered != 1 || stats.DeadLettered != 0 || len(letters) != 0 {
+					t.Fatalf("stats %+v, dead letters %+v; want one delivery", stats, letters)
+				}
+				return
+			}
+			if stats.Delivered != 0 || stats.DeadLettered != 1 || len(letters) != 1 {
+				t.Fatalf("stats %+v, dead letters %+v; want one dead letter", stats, letters)
+			}
+			if got := letters[0]; got.JobID != "job-1" || got.Attempts != tt.wantAttempts || got.LastStatusCode != tt.wantStatus || got.Payload != nil {
+				t.Errorf("dead letter %+v, want job-1 after %d attempts with status %d and no payload", got, tt.wantAttempts, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestPrivateAddressRefusedWhenDialed(t *testing.T) {
+	rc := &receiver{t: t}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	// CheckURL rejects this loopback address; send anyway, as for a host
+	// name that only resolves to it, to reach the check made when dialing
+	d, err := New(Config{Secret: testSecret, MaxAttempts: 3, InitialBackoff: time.Millisecond}, discard)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	d.Send(server.URL, &nanabushv1.JobCallback{JobId: "job-1"})
+	waitDelivered(t, d)
+
+	if len(rc.attempts) != 0 {
+		t.Fatalf("receiver saw %d attempts, want none", len(rc.attempts))
+	}
+	letters := d.DeadLetters()
+	if len(letters) != 1 || letters[0].Attempts != 1 || !strings.Contains(letters[0].LastError, errBlockedAddress.Error()) {
+		t.Fatalf("dead letters %+v, want one after a single refused attempt", letters)
+	}
+}
+
+func TestRefusePrivate(t *testing.T) {
+	tests := []struct {
+		address string
+		blocked bool
+	}{
+		{"203.0.113.10:443", false},
+		{"[2001:db8::1]:443", false},
+		{"127.0.0.1:80", true},
+		{"[::1]:80", true},
+		{"10.1.2.3:80", true},
+		{"172.16.0.1:80", true},
+		{"192.168.1.1:80", true},
+		{"169.254.169.254:80", true},
+		{"[fe80::1]:80", true},
+		{"[fd00::1]:80", true},
+		{"[::ffff:127.0.0.1]:80", true},
+		{"0.0.0.0:80", true},
+	}
+	for _, tt := range tests {
+		err := refusePrivate("tcp", tt.address, nil)
+		if blocked := errors.Is(err, errBlockedAddress); blocked != tt.blocked {
+			t.Errorf("refusePrivate(%q) = %v, want blocked: %v", tt.address, err, tt.blocked)
+		}
+	}
+}